@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+func runCatalog(args []string) error {
+	fs := newFlagSet("catalog")
+	url := fs.String("url", "", "URL del catalogo (default: $"+services.DSOCatalogURLEnv+" o repo GitHub)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Uso: astrolair catalog [opzioni] info|update|rollback")
+		fs.PrintDefaults()
+	}
+
+	action := "info"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		action, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch action {
+	case "info":
+		cat, err := services.ReadLocalDsoCatalog()
+		if err != nil {
+			return fmt.Errorf("catalogo locale non disponibile: %w", err)
+		}
+		fmt.Fprintf(stdout, "File:      %s\n", services.DSOCatalogLocalPath)
+		fmt.Fprintf(stdout, "Versione:  %d\n", cat.Version)
+		fmt.Fprintf(stdout, "Generato:  %s\n", cat.GeneratedAt)
+		fmt.Fprintf(stdout, "Sorgente:  %s\n", cat.Source)
+		fmt.Fprintf(stdout, "Oggetti:   %d\n", len(cat.Objects))
+		if _, err := os.Stat(services.DSOCatalogBackupPath); err == nil {
+			fmt.Fprintf(stdout, "Rollback:  %s\n", services.DSOCatalogBackupPath)
+		}
+		return nil
+
+	case "update":
+		res, err := services.UpdateDSOCatalog(services.ResolveDSOCatalogURL(*url), nil)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, res.Message)
+		return nil
+
+	case "rollback":
+		if err := services.RollbackDSOCatalog(); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "Ripristinato il catalogo precedente.")
+		return nil
+	}
+
+	fs.Usage()
+	return fmt.Errorf("azione non valida %q", action)
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// =======================
+//  Modalità headless (CLI)
+// =======================
+
+// command descrive un sottocomando della CLI.
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"moon":      {"fase lunare e prossime notti favorevoli", runMoon},
+	"targets":   {"elenco dei target dal catalogo DSO", runTargets},
+	"weather":   {"previsioni meteo orarie per lat/lon", runWeather},
+	"sats":      {"posizione dei satelliti di Giove o Saturno", runSats},
+	"catalog":   {"stato, aggiornamento e rollback del catalogo DSO", runCatalog},
+	"sun":       {"posizione del Sole, alba, tramonto e crepuscoli", runSun},
+	"planets":   {"posizione e visibilità notturna dei pianeti", runPlanets},
+	"kp":        {"indice Kp attuale, storico e previsione con visibilità dell'aurora", runKp},
+	"locate":    {"posizione del dispositivo da gpsd, IP o coordinate manuali", runLocate},
+	"places":    {"ricerca offline di una località (coordinate, quota, fuso)", runPlaces},
+	"flares":    {"flusso X GOES attuale e brillamenti solari degli ultimi 7 giorni", runFlares},
+	"solarwind": {"vento solare in tempo reale (Bz, Bt, velocità, densità) con i periodi di Bz sud", runSolarWind},
+}
+
+// Sito di default dei comandi che richiedono lat/lon (lo stesso della GUI).
+const (
+	defaultLat = 37.65
+	defaultLon = 15.17
+)
+
+// stdout è lo stream su cui scrivono i sottocomandi (sostituibile per i test).
+var stdout io.Writer = os.Stdout
+
+// HasCommand indica se arg è un sottocomando CLI (o una richiesta di aiuto).
+func HasCommand(arg string) bool {
+	switch arg {
+	case "help", "-h", "-help", "--help":
+		return true
+	}
+	_, ok := commands[arg]
+	return ok
+}
+
+// Run esegue il sottocomando indicato in args[0] senza aprire alcuna finestra
+// e restituisce l'exit code del processo.
+func Run(args []string) int {
+	if len(args) == 0 || !HasCommand(args[0]) {
+		printUsage(os.Stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		printUsage(stdout)
+		return 0
+	}
+
+	if err := cmd.run(args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "astrolair %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Uso: astrolair <comando> [opzioni]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Senza comando viene avviata l'interfaccia grafica.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Comandi:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%s\n", name, commands[name].summary)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Usa \"astrolair <comando> --help\" per le opzioni di ogni comando.")
+}
+
+// =======================
+//  Helpers
+// =======================
+
+// newFlagSet crea un FlagSet che riporta gli errori invece di terminare il processo.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("astrolair "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// dateLayouts sono i formati accettati da --date (ora locale se non indicata la zona).
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseDate interpreta il valore di --date; stringa vuota = adesso.
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Now(), nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("data non valida %q (atteso es. 2026-11-02T22:00)", s)
+}
+
+// writeJSON scrive v come JSON indentato su stdout.
+func writeJSON(v any) error {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// newTable restituisce un tabwriter per le tabelle "umane".
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runCapture esegue la CLI catturando ciò che i sottocomandi scrivono su stdout.
+func runCapture(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	var buf bytes.Buffer
+	old := stdout
+	stdout = &buf
+	defer func() { stdout = old }()
+	code := Run(args)
+	return buf.String(), code
+}
+
+func TestRunHelpListsCommands(t *testing.T) {
+	out, code := runCapture(t, "help")
+	if code != 0 {
+		t.Fatalf("exit code = %d, atteso 0", code)
+	}
+	for name := range commands {
+		if !strings.Contains(out, "  "+name+" ") {
+			t.Errorf("l'aiuto non elenca il comando %q:\n%s", name, out)
+		}
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	out, code := runCapture(t, "nonesiste")
+	if code != 2 {
+		t.Errorf("exit code = %d, atteso 2", code)
+	}
+	if out != "" {
+		t.Errorf("stdout non vuoto per un comando sconosciuto: %q", out)
+	}
+}
+
+func TestRunSunJSON(t *testing.T) {
+	out, code := runCapture(t, "sun", "-json", "-date", "2026-06-21T12:00:00+02:00", "-lat", "37.65", "-lon", "15.17")
+	if code != 0 {
+		t.Fatalf("exit code = %d, atteso 0", code)
+	}
+	var rep sunReport
+	if err := json.Unmarshal([]byte(out), &rep); err != nil {
+		t.Fatalf("JSON non valido: %v\n%s", err, out)
+	}
+	if rep.Lat != 37.65 || rep.Lon != 15.17 {
+		t.Errorf("sito = %v, %v; atteso 37.65, 15.17", rep.Lat, rep.Lon)
+	}
+	// vicino al solstizio, a mezzogiorno il Sole è alto sull'Etna
+	if rep.Position.AltDeg < 60 || rep.Position.AltDeg > 80 {
+		t.Errorf("altezza del Sole = %.1f°, attesa fra 60° e 80°", rep.Position.AltDeg)
+	}
+}
+
+func TestRunBadFlag(t *testing.T) {
+	if _, code := runCapture(t, "moon", "-date", "ieri"); code != 1 {
+		t.Errorf("exit code = %d, atteso 1 per una data non valida", code)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"2026-11-02T22:00", time.Date(2026, 11, 2, 22, 0, 0, 0, time.Local), true},
+		{"2026-11-02 22:00", time.Date(2026, 11, 2, 22, 0, 0, 0, time.Local), true},
+		{"2026-11-02", time.Date(2026, 11, 2, 0, 0, 0, 0, time.Local), true},
+		{"2026-11-02T22:00:00Z", time.Date(2026, 11, 2, 22, 0, 0, 0, time.UTC), true},
+		{"02/11/2026", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseDate(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseDate(%q) errore = %v, ok atteso %v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %v, atteso %v", tt.in, got, tt.want)
+		}
+	}
+}
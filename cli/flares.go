@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+// flaresReport è l'output JSON di "astrolair flares".
+type flaresReport struct {
+	Current *xrayPoint   `json:"current,omitempty"`
+	Class   string       `json:"class,omitempty"`
+	Flares  []flareEntry `json:"flares"`
+}
+
+// xrayPoint è il flusso X attuale con la banda corta opzionale (null se manca).
+type xrayPoint struct {
+	models.XRayFlux
+	Short *float64 `json:"short"`
+}
+
+// flareEntry è un brillamento con il flusso di picco opzionale (null se la
+// classe non è riconosciuta).
+type flareEntry struct {
+	models.SolarFlare
+	PeakFlux *float64 `json:"peak_flux"`
+}
+
+func runFlares(args []string) error {
+	fs := newFlagSet("flares")
+	minClass := fs.String("min", "", "mostra solo i brillamenti da questa classe in su (es. C, M1)")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	minFlux := 0.0
+	if *minClass != "" {
+		if minFlux = services.ParseXRayClass(*minClass); math.IsNaN(minFlux) {
+			return fmt.Errorf("classe %q non valida (A, B, C, M o X, es. M1)", *minClass)
+		}
+	}
+
+	flux, err := services.FetchXRayFlux(1)
+	if err != nil {
+		return fmt.Errorf("errore durante il download del flusso X: %w", err)
+	}
+	flares, err := services.FetchSolarFlares()
+	if err != nil {
+		return fmt.Errorf("errore durante il download dei brillamenti: %w", err)
+	}
+
+	rep := flaresReport{Flares: []flareEntry{}}
+	for i := len(flux) - 1; i >= 0; i-- {
+		if !math.IsNaN(flux[i].Long) {
+			p := flux[i]
+			rep.Current = &xrayPoint{p, optional(p.Short)}
+			rep.Class = services.XRayClass(p.Long)
+			break
+		}
+	}
+	for _, f := range flares {
+		if math.IsNaN(f.PeakFlux) {
+			if minFlux > 0 {
+				continue // classe non riconosciuta: non sappiamo se supera il filtro
+			}
+		} else if f.PeakFlux < minFlux {
+			continue
+		}
+		rep.Flares = append(rep.Flares, flareEntry{f, optional(f.PeakFlux)})
+	}
+
+	if *asJSON {
+		return writeJSON(rep)
+	}
+
+	if c := rep.Current; c != nil {
+		fmt.Fprintf(stdout, "Flusso X (0.1-0.8 nm): %.2e W/m², classe %s, %s\n", c.Long, rep.Class, c.Time.Local().Format("2006-01-02 15:04"))
+	} else {
+		fmt.Fprintln(stdout, "Flusso X: nessuna misura recente")
+	}
+	fmt.Fprintln(stdout)
+	if len(rep.Flares) == 0 {
+		fmt.Fprintln(stdout, "Nessun brillamento negli ultimi 7 giorni.")
+		return nil
+	}
+
+	tw := newTable()
+	fmt.Fprintln(tw, "CLASSE\tINIZIO\tPICCO\tFINE\tGOES")
+	for _, f := range rep.Flares {
+		end := "in corso"
+		if f.End != nil {
+			end = f.End.Local().Format("15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", f.Class, f.Begin.Local().Format("2006-01-02 15:04"),
+			f.Peak.Local().Format("2006-01-02 15:04"), end, f.Satellite)
+	}
+	return tw.Flush()
+}
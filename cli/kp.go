@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+// kpReport è l'output JSON di "astrolair kp".
+type kpReport struct {
+	Lat     float64          `json:"lat"`
+	Lon     float64          `json:"lon"`
+	Outlook models.KpOutlook `json:"outlook"`
+	Aurora  kpAurora         `json:"aurora"`
+}
+
+// kpAurora è la stima dell'aurora con il Kp opzionale (null se manca).
+type kpAurora struct {
+	models.AuroraChance
+	Kp *float64 `json:"kp"`
+}
+
+func runKp(args []string) error {
+	fs := newFlagSet("kp")
+	lat := fs.Float64("lat", defaultLat, "latitudine del sito in gradi decimali")
+	lon := fs.Float64("lon", defaultLon, "longitudine del sito in gradi decimali (est positiva)")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	o, err := services.FetchKpOutlook(now)
+	if err != nil {
+		return fmt.Errorf("errore durante il download del Kp: %w", err)
+	}
+	a := services.AuroraTonight(o, now, *lat, *lon)
+
+	if *asJSON {
+		return writeJSON(kpReport{Lat: *lat, Lon: *lon, Outlook: o, Aurora: kpAurora{a, optional(a.Kp)}})
+	}
+
+	if c := o.Current; c != nil {
+		fmt.Fprintf(stdout, "Kp attuale: %.2f (%s), %s %s\n", c.Kp, services.KpLabel(c.Kp), c.Status, c.Time.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintln(stdout)
+
+	tw := newTable()
+	fmt.Fprintln(tw, "INTERVALLO\tKP\tSTATO\tLIVELLO")
+	for _, r := range append(append([]models.KpReading(nil), o.History...), o.Forecast...) {
+		fmt.Fprintf(tw, "%s\t%.2f\t%s\t%s\n", r.Time.Local().Format("2006-01-02 15:04"), r.Kp, r.Status, services.KpLabel(r.Kp))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(stdout)
+	fmt.Fprintf(stdout, "Sito %.4f, %.4f: latitudine geomagnetica %.1f°\n", *lat, *lon, a.GeomagLat)
+	fmt.Fprintf(stdout, "Aurora stanotte: %s", services.AuroraLabel(a))
+	if a.KpForHorizon <= 9 && !a.OnHorizon {
+		fmt.Fprintf(stdout, " (serve Kp ≥ %.1f)", a.KpForHorizon)
+	}
+	fmt.Fprintln(stdout)
+	return nil
+}
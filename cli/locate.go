@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+// locateReport è l'output JSON di "astrolair locate".
+type locateReport struct {
+	Provider  string   `json:"provider"`
+	Lat       float64  `json:"lat"`
+	Lon       float64  `json:"lon"`
+	Elevation *float64 `json:"elevation_m"`
+	Accuracy  *float64 `json:"accuracy_m"`
+}
+
+func runLocate(args []string) error {
+	fs := newFlagSet("locate")
+	order := fs.String("order", "", "fonti in ordine, es. gpsd,ip-api,ipwho.is,manuale (default: gpsd,ip-api,ipwho.is)")
+	gpsd := fs.String("gpsd", services.DefaultGpsdAddr, "indirizzo host:porta di gpsd")
+	timeout := fs.Duration("timeout", services.DefaultGpsdTimeout, "attesa massima del fix GPS")
+	lat := fs.Float64("lat", math.NaN(), "latitudine per la fonte \"manuale\"")
+	lon := fs.Float64("lon", math.NaN(), "longitudine per la fonte \"manuale\"")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	names, err := services.ParseLocationOrder(*order)
+	if err != nil {
+		return err
+	}
+	var manual *services.DeviceLocation
+	if !math.IsNaN(*lat) && !math.IsNaN(*lon) {
+		manual = &services.DeviceLocation{Lat: *lat, Lon: *lon, ElevationM: math.NaN()}
+	}
+	providers, err := services.LocationProviders(names, *gpsd, manual)
+	if err != nil {
+		return err
+	}
+	for i, p := range providers {
+		if g, ok := p.(services.GpsdProvider); ok {
+			g.Timeout = *timeout
+			providers[i] = g
+		}
+	}
+
+	start := time.Now()
+	loc, err := services.LocateDevice(providers)
+	if err != nil {
+		return fmt.Errorf("impossibile rilevare la posizione:\n%w", err)
+	}
+
+	if *asJSON {
+		return writeJSON(locateReport{
+			Provider:  loc.Provider,
+			Lat:       loc.Lat,
+			Lon:       loc.Lon,
+			Elevation: optional(loc.ElevationM),
+			Accuracy:  optional(loc.AccuracyM),
+		})
+	}
+	fmt.Fprintf(stdout, "Fonte:      %s (%.1f s)\n", loc.Provider, time.Since(start).Seconds())
+	fmt.Fprintf(stdout, "Posizione:  %.5f, %.5f\n", loc.Lat, loc.Lon)
+	if !math.IsNaN(loc.ElevationM) {
+		fmt.Fprintf(stdout, "Quota:      %.0f m\n", loc.ElevationM)
+	}
+	if math.IsNaN(loc.AccuracyM) {
+		fmt.Fprintln(stdout, "Precisione: ignota")
+	} else {
+		fmt.Fprintf(stdout, "Precisione: %s\n", services.FormatLocationAccuracy(loc.AccuracyM))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+// moonReport è l'output JSON di "astrolair moon".
+type moonReport struct {
+	Date         time.Time               `json:"date"`
+	PhaseName    string                  `json:"phase_name"`
+	Phase        float64                 `json:"phase"`
+	Illumination float64                 `json:"illumination"`
+	AgeDays      float64                 `json:"age_days"`
+	Position     models.MoonPosition     `json:"position"`
+	NextPhases   []models.MoonPhaseEvent `json:"next_phases"`
+	BestNights   []models.BestNightEntry `json:"best_nights"`
+}
+
+func runMoon(args []string) error {
+	fs := newFlagSet("moon")
+	date := fs.String("date", "", "data/ora locale, es. 2026-11-02T22:00 (default: adesso)")
+	nights := fs.Int("nights", 30, "giorni da considerare per le notti favorevoli")
+	lat := fs.Float64("lat", defaultLat, "latitudine del sito in gradi decimali")
+	lon := fs.Float64("lon", defaultLon, "longitudine del sito in gradi decimali (est positiva)")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t, err := parseDate(*date)
+	if err != nil {
+		return err
+	}
+
+	mp := services.ComputeMoonPosition(t, *lat, *lon)
+	rep := moonReport{
+		Date:         t,
+		PhaseName:    mp.PhaseName,
+		Phase:        mp.Phase,
+		Illumination: mp.Illumination,
+		AgeDays:      mp.AgeDays,
+		Position:     mp,
+		NextPhases:   services.NextMoonPhases(t),
+		BestNights:   services.ComputeBestNights(t, *nights, *lat, *lon),
+	}
+
+	if *asJSON {
+		return writeJSON(rep)
+	}
+
+	fmt.Fprintf(stdout, "Data/ora:       %s\n", rep.Date.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(stdout, "Fase:           %s\n", rep.PhaseName)
+	fmt.Fprintf(stdout, "Frazione:       %.3f (0 = nuova, 0.5 = piena)\n", rep.Phase)
+	fmt.Fprintf(stdout, "Illuminazione:  %.1f%%\n", rep.Illumination*100.0)
+	fmt.Fprintf(stdout, "Età:            %.1f giorni\n", rep.AgeDays)
+	fmt.Fprintf(stdout, "RA/Dec topo:    %s  %s\n", services.FormatRAFromDeg(mp.TopoRADeg), services.FormatDecFromDeg(mp.TopoDecDeg))
+	fmt.Fprintf(stdout, "Alt/Az:         %.1f°  %.1f°\n", mp.AltDeg, mp.AzDeg)
+	fmt.Fprintf(stdout, "Distanza:       %.0f km\n", mp.DistanceKm)
+	fmt.Fprintf(stdout, "Lembo (PA):     %.0f°\n", mp.BrightLimbDeg)
+	fmt.Fprintln(stdout)
+
+	fmt.Fprintln(stdout, "Prossime fasi:")
+	for _, p := range rep.NextPhases {
+		fmt.Fprintf(stdout, "  %-14s %s\n", p.Name, p.Time.In(t.Location()).Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintln(stdout)
+
+	fmt.Fprintf(stdout, "Notti migliori (%d giorni, buio astronomico senza Luna):\n", *nights)
+	tw := newTable()
+	fmt.Fprintln(tw, "NOTTE\tINIZIO\tFINE\tORE BUIE\tILLUMINAZIONE\tFASE\tQUALITÀ")
+	for _, n := range rep.BestNights {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.1f\t%.1f%%\t%s\t%s\n",
+			n.Date.Format("02/01/2006"),
+			n.Start.In(t.Location()).Format("15:04"),
+			n.End.In(t.Location()).Format("15:04"),
+			n.DarkHours,
+			n.Illumination*100.0,
+			n.PhaseName,
+			n.Quality,
+		)
+	}
+	return tw.Flush()
+}
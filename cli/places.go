@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+func runPlaces(args []string) error {
+	fs := newFlagSet("places")
+	limit := fs.Int("limit", 10, "numero massimo di risultati")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	query := strings.Join(fs.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		return errors.New("indica il nome della località, es. \"astrolair places Nicolosi\"")
+	}
+
+	places := services.SearchPlaces(query, *limit)
+	if *asJSON {
+		return writeJSON(places)
+	}
+	if len(places) == 0 {
+		fmt.Fprintf(stdout, "Nessuna località trovata per %q (%d nel gazetteer).\n", query, services.GazetteerSize())
+		return nil
+	}
+
+	tw := newTable()
+	fmt.Fprintln(tw, "NOME\tPAESE\tLAT\tLON\tQUOTA\tFUSO\tABITANTI")
+	for _, p := range places {
+		fmt.Fprintf(tw, "%s\t%s\t%.4f\t%.4f\t%.0f m\t%s\t%d\n",
+			p.Name, p.Country, p.Lat, p.Lon, p.ElevationM, p.Timezone, p.Population)
+	}
+	return tw.Flush()
+}
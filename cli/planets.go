@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+// planetsReport è l'output JSON di "astrolair planets".
+type planetsReport struct {
+	Date    time.Time              `json:"date"`
+	Lat     float64                `json:"lat"`
+	Lon     float64                `json:"lon"`
+	Night   *models.NightWindow    `json:"night,omitempty"` // nil se non fa buio
+	Planets []models.PlanetTonight `json:"planets"`
+}
+
+func runPlanets(args []string) error {
+	fs := newFlagSet("planets")
+	date := fs.String("date", "", "data/ora locale, es. 2026-11-02T22:00 (default: adesso)")
+	lat := fs.Float64("lat", defaultLat, "latitudine del sito in gradi decimali")
+	lon := fs.Float64("lon", defaultLon, "longitudine del sito in gradi decimali (est positiva)")
+	minAlt := fs.Float64("min-alt", 15, "altezza minima (gradi) per contare le ore di visibilità")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t, err := parseDate(*date)
+	if err != nil {
+		return err
+	}
+
+	list, window, ok := services.PlanetsTonight(t, *lat, *lon, *minAlt)
+	rep := planetsReport{Date: t, Lat: *lat, Lon: *lon, Planets: list}
+	if ok {
+		rep.Night = &window
+	}
+
+	if *asJSON {
+		return writeJSON(rep)
+	}
+
+	loc := t.Location()
+	fmt.Fprintf(stdout, "Pianeti — notte del %s, sito %.4f, %.4f\n", services.NightDay(t).Format("2006-01-02"), *lat, *lon)
+	if ok {
+		fmt.Fprintf(stdout, "Buio astronomico: %s – %s (ore sopra %.0f°)\n", window.Start.In(loc).Format("15:04"), window.End.In(loc).Format("15:04"), *minAlt)
+	} else {
+		fmt.Fprintln(stdout, "Nessun buio astronomico al sito.")
+	}
+	fmt.Fprintln(stdout)
+
+	tw := newTable()
+	fmt.Fprintln(tw, "PIANETA\tRA\tDEC\tMAG\tDIAM\"\tFASE\tELONG\tSORGE\tTRANSITO\tTRAMONTA\tORE BUIO")
+	for _, p := range rep.Planets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%+.1f\t%.1f\t%.0f%%\t%+.0f°\t%s\t%s\t%s\t%.1f\n",
+			p.Name,
+			services.FormatRAFromDeg(p.RADeg), services.FormatDecFromDeg(p.DecDeg),
+			p.Magnitude, p.DiameterArcsec, p.Illumination*100, p.ElongationDeg,
+			clockOrDash(p.Rise, loc), clockOrDash(p.Transit, loc), clockOrDash(p.Set, loc),
+			p.HoursAbove)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, "Elongazione + = a est del Sole (sera), − = a ovest (mattino).")
+	return nil
+}
+
+// clockOrDash formatta un orario opzionale come HH:MM.
+func clockOrDash(t *time.Time, loc *time.Location) string {
+	if t == nil {
+		return "—"
+	}
+	return t.In(loc).Format("15:04")
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+// satsReport è l'output JSON di "astrolair sats".
+type satsReport struct {
+	Planet     string                  `json:"planet"`
+	Date       time.Time               `json:"date"`
+	Satellites []models.SatellitePos   `json:"satellites"`
+	Rings      *satsRings              `json:"rings,omitempty"`
+	Events     []models.SatelliteEvent `json:"events,omitempty"`
+}
+
+// satsRings è la geometria degli anelli di Saturno vista dalla Terra.
+type satsRings struct {
+	TiltDeg   float64 `json:"tilt_deg"`    // B, + = faccia nord
+	PolePADeg float64 `json:"pole_pa_deg"` // P
+}
+
+func runSats(args []string) error {
+	fs := newFlagSet("sats")
+	date := fs.String("date", "", "data/ora locale, es. 2026-11-02T22:00 (default: adesso)")
+	days := fs.Int("events", 0, "elenca i fenomeni dei Galileiani nei prossimi N giorni (solo Giove)")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Uso: astrolair sats [opzioni] jupiter|saturn")
+		fs.PrintDefaults()
+	}
+
+	// accetta il pianeta sia prima sia dopo le opzioni
+	planetArg := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		planetArg, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if planetArg == "" && fs.NArg() > 0 {
+		planetArg = fs.Arg(0)
+	}
+
+	t, err := parseDate(*date)
+	if err != nil {
+		return err
+	}
+
+	rep := satsReport{Date: t}
+	switch strings.ToLower(planetArg) {
+	case "jupiter", "giove":
+		rep.Planet = "Giove"
+		rep.Satellites = services.ComputeJupiterSatellites(t)
+		if *days > 0 {
+			rep.Events = services.JupiterSatelliteEvents(t, *days)
+		}
+	case "saturn", "saturno":
+		rep.Planet = "Saturno"
+		sys := services.ComputeSaturnSystem(t)
+		rep.Satellites = sys.Moons
+		rep.Rings = &satsRings{TiltDeg: sys.RingTiltDeg, PolePADeg: sys.PolePADeg}
+	default:
+		fs.Usage()
+		return fmt.Errorf("pianeta non valido %q", planetArg)
+	}
+
+	if *asJSON {
+		return writeJSON(rep)
+	}
+
+	fmt.Fprintf(stdout, "Satelliti di %s — %s\n", rep.Planet, rep.Date.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintln(stdout, "(X/Y in raggi planetari: X positivo verso ovest lungo l'equatore, Y verso nord)")
+	if rep.Rings != nil {
+		fmt.Fprintf(stdout, "Anelli: apertura B %+.2f°, angolo di posizione del polo P %.1f°\n", rep.Rings.TiltDeg, rep.Rings.PolePADeg)
+	}
+	fmt.Fprintln(stdout)
+	tw := newTable()
+	fmt.Fprintln(tw, "SATELLITE\tX\tY\tPOSIZIONE")
+	for _, s := range rep.Satellites {
+		fmt.Fprintf(tw, "%s\t%+.2f\t%+.2f\t%s\n", s.Name, s.X, s.Y, satState(s))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(rep.Events) > 0 {
+		fmt.Fprintln(stdout)
+		fmt.Fprintf(stdout, "Fenomeni dei prossimi %d giorni:\n", *days)
+		for _, e := range rep.Events {
+			fmt.Fprintf(stdout, "  %s  %s\n", e.Time.In(t.Location()).Format("2006-01-02 15:04"), services.FormatSatelliteEvent(e))
+		}
+	}
+	return nil
+}
+
+// satState descrive la posizione del satellite rispetto al pianeta.
+func satState(s models.SatellitePos) string {
+	var parts []string
+	switch {
+	case s.Transit:
+		parts = append(parts, "in transito")
+	case s.Occulted:
+		parts = append(parts, "occultato")
+	case s.Behind:
+		parts = append(parts, "dietro")
+	default:
+		parts = append(parts, "davanti")
+	}
+	if s.Eclipsed {
+		parts = append(parts, "eclissato")
+	}
+	if s.ShadowOnDisk {
+		parts = append(parts, "ombra sul disco")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+// solarWindPoint è un campione nell'output JSON (null se il valore manca:
+// NaN non è serializzabile e Bz può essere negativo).
+type solarWindPoint struct {
+	Time        time.Time `json:"time"`
+	Bt          *float64  `json:"bt"`
+	Bz          *float64  `json:"bz"`
+	Speed       *float64  `json:"speed"`
+	Density     *float64  `json:"density"`
+	Temperature *float64  `json:"temperature"`
+}
+
+// solarWindReport è l'output JSON di "astrolair solarwind".
+type solarWindReport struct {
+	Hours   int                    `json:"hours"`
+	Latest  *solarWindPoint        `json:"latest,omitempty"`
+	South   []models.BzSouthPeriod `json:"southward_bz"`
+	Samples []solarWindPoint       `json:"samples,omitempty"`
+}
+
+func runSolarWind(args []string) error {
+	fs := newFlagSet("solarwind")
+	hours := fs.Int("hours", 2, "finestra in ore (2, 24 o 168)")
+	withSamples := fs.Bool("samples", false, "includi tutti i campioni al minuto nell'output JSON")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	valid := false
+	for _, h := range services.SolarWindHours() {
+		valid = valid || h == *hours
+	}
+	if !valid {
+		return fmt.Errorf("finestra di %d ore non disponibile (usa %v)", *hours, services.SolarWindHours())
+	}
+
+	samples, err := services.FetchSolarWind(*hours)
+	if err != nil {
+		return fmt.Errorf("errore durante il download del vento solare: %w", err)
+	}
+	rep := solarWindReport{Hours: *hours, South: services.SouthwardBz(samples)}
+	if rep.South == nil {
+		rep.South = []models.BzSouthPeriod{}
+	}
+	if last, ok := services.LatestSolarWind(samples); ok {
+		p := newSolarWindPoint(last)
+		rep.Latest = &p
+	}
+
+	if *asJSON {
+		if *withSamples {
+			rep.Samples = make([]solarWindPoint, len(samples))
+			for i, s := range samples {
+				rep.Samples[i] = newSolarWindPoint(s)
+			}
+		}
+		return writeJSON(rep)
+	}
+
+	if l := rep.Latest; l == nil {
+		fmt.Fprintln(stdout, "Nessuna misura recente del vento solare.")
+	} else {
+		fmt.Fprintf(stdout, "Ultimo dato: %s\n", l.Time.Local().Format("2006-01-02 15:04"))
+		fmt.Fprintf(stdout, "Bz %s nT • Bt %s nT • velocità %s km/s • densità %s p/cm³\n",
+			num(l.Bz, "%+.1f"), num(l.Bt, "%.1f"), num(l.Speed, "%.0f"), num(l.Density, "%.1f"))
+	}
+	fmt.Fprintln(stdout)
+
+	if len(rep.South) == 0 {
+		fmt.Fprintf(stdout, "Nessun intervallo con Bz sotto %.0f nT per almeno %d minuti.\n",
+			services.BzSouthThreshold, services.BzSouthMinutes)
+		return nil
+	}
+	tw := newTable()
+	fmt.Fprintln(tw, "BZ SUD DAL\tAL\tDURATA\tBZ MIN")
+	for _, p := range rep.South {
+		fmt.Fprintf(tw, "%s\t%s\t%.0f min\t%.1f\n", p.Start.Local().Format("2006-01-02 15:04"),
+			p.End.Local().Format("15:04"), p.End.Sub(p.Start).Minutes(), p.MinBz)
+	}
+	return tw.Flush()
+}
+
+func newSolarWindPoint(s models.SolarWindSample) solarWindPoint {
+	return solarWindPoint{Time: s.Time, Bt: optional(s.Bt), Bz: optional(s.Bz), Speed: optional(s.Speed),
+		Density: optional(s.Density), Temperature: optional(s.Temperature)}
+}
+
+// num formatta un valore opzionale ("-" se mancante).
+func num(v *float64, format string) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf(format, *v)
+}
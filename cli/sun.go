@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+// sunReport è l'output JSON di "astrolair sun".
+type sunReport struct {
+	Date     time.Time          `json:"date"`
+	Lat      float64            `json:"lat"`
+	Lon      float64            `json:"lon"`
+	Position models.SunPosition `json:"position"`
+	Events   models.SunEvents   `json:"events"`
+}
+
+func runSun(args []string) error {
+	fs := newFlagSet("sun")
+	date := fs.String("date", "", "data/ora locale, es. 2026-11-02T22:00 (default: adesso)")
+	lat := fs.Float64("lat", defaultLat, "latitudine del sito in gradi decimali")
+	lon := fs.Float64("lon", defaultLon, "longitudine del sito in gradi decimali (est positiva)")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t, err := parseDate(*date)
+	if err != nil {
+		return err
+	}
+
+	rep := sunReport{
+		Date:     t,
+		Lat:      *lat,
+		Lon:      *lon,
+		Position: services.SunPosition(t, *lat, *lon),
+		Events:   services.ComputeSunEvents(t, *lat, *lon),
+	}
+
+	if *asJSON {
+		return writeJSON(rep)
+	}
+
+	p := rep.Position
+	fmt.Fprintf(stdout, "Data/ora:   %s\n", t.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(stdout, "Sito:       %.4f, %.4f\n", *lat, *lon)
+	fmt.Fprintf(stdout, "RA/Dec:     %s  %s\n", services.FormatRAFromDeg(p.RADeg), services.FormatDecFromDeg(p.DecDeg))
+	fmt.Fprintf(stdout, "Alt/Az:     %.2f°  %.2f°\n", p.AltDeg, p.AzDeg)
+	fmt.Fprintf(stdout, "Distanza:   %.5f UA\n", p.DistanceAU)
+	fmt.Fprintln(stdout)
+
+	ev := rep.Events
+	switch {
+	case ev.PolarDay:
+		fmt.Fprintln(stdout, "Giorno polare: il Sole non tramonta.")
+	case ev.PolarNight:
+		fmt.Fprintln(stdout, "Notte polare: il Sole non sorge.")
+	}
+
+	tw := newTable()
+	fmt.Fprintln(tw, "EVENTO\tORA")
+	row := func(name string, at *time.Time) {
+		val := "—"
+		if at != nil {
+			val = at.In(t.Location()).Format("15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", name, val)
+	}
+	row("Inizio crepuscolo astronomico", ev.AstronomicalDawn)
+	row("Inizio crepuscolo nautico", ev.NauticalDawn)
+	row("Inizio crepuscolo civile", ev.CivilDawn)
+	row("Alba", ev.Sunrise)
+	fmt.Fprintf(tw, "Transito (alt %.1f°)\t%s\n", ev.TransitAltDeg, ev.Transit.In(t.Location()).Format("15:04"))
+	row("Tramonto", ev.Sunset)
+	row("Fine crepuscolo civile", ev.CivilDusk)
+	row("Fine crepuscolo nautico", ev.NauticalDusk)
+	row("Fine crepuscolo astronomico", ev.AstronomicalDusk)
+	return tw.Flush()
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+func runTargets(args []string) error {
+	fs := newFlagSet("targets")
+	catalog := fs.String("catalog", "", "catalogo da elencare, es. Messier (default: tutti)")
+	search := fs.String("search", "", "filtra per nome, codice o costellazione")
+	limit := fs.Int("limit", 0, "numero massimo di target (0 = nessun limite)")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	byCatalog := services.LoadTargetsCatalog()
+
+	var names []string
+	for name := range byCatalog {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if *catalog != "" {
+		found := ""
+		for _, name := range names {
+			if strings.EqualFold(name, *catalog) {
+				found = name
+				break
+			}
+		}
+		if found == "" {
+			return fmt.Errorf("catalogo %q non trovato (disponibili: %s)", *catalog, strings.Join(names, ", "))
+		}
+		names = []string{found}
+	}
+
+	q := strings.TrimSpace(strings.ToLower(*search))
+	var out []models.TargetObject
+	for _, name := range names {
+		for _, t := range byCatalog[name] {
+			if q != "" &&
+				!strings.Contains(strings.ToLower(t.Name), q) &&
+				!strings.Contains(strings.ToLower(t.Constellation), q) &&
+				!strings.Contains(strings.ToLower(t.Code), q) {
+				continue
+			}
+			out = append(out, t)
+		}
+	}
+	if *limit > 0 && len(out) > *limit {
+		out = out[:*limit]
+	}
+
+	if *asJSON {
+		return writeJSON(out)
+	}
+
+	tw := newTable()
+	fmt.Fprintln(tw, "CATALOGO\tCODICE\tNOME\tTIPO\tCOSTELLAZIONE\tMAG\tRA\tDEC")
+	for _, t := range out {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Catalog, t.Code, t.Name, t.Type, t.Constellation, num(t.Magnitude, "%.1f"), t.RA, t.Dec)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+// weatherHour è una riga dell'output JSON di "astrolair weather".
+// I valori non forniti da Open-Meteo sono null.
+type weatherHour struct {
+	Time         time.Time `json:"time"`
+	Temperature  *float64  `json:"temperature_c"`
+	DewPoint     *float64  `json:"dew_point_c"`
+	Humidity     *float64  `json:"humidity"`
+	CloudCover   *float64  `json:"cloud_cover"`
+	CloudLow     *float64  `json:"cloud_low"`
+	CloudMid     *float64  `json:"cloud_mid"`
+	CloudHigh    *float64  `json:"cloud_high"`
+	Visibility   *float64  `json:"visibility_m"`
+	WindSpeed    *float64  `json:"wind_speed_ms"`
+	WindGusts    *float64  `json:"wind_gusts_ms"`
+	PrecipProb   *float64  `json:"precip_prob"`
+	Jet          *float64  `json:"jet_stream_ms"`
+	Seeing       *float64  `json:"seeing_arcsec"`
+	Transparency *float64  `json:"transparency"`
+	DewMargin    *float64  `json:"dew_margin_c"` // ottica − punto di rugiada
+	DewRisk      string    `json:"dew_risk"`
+	HeaterPct    float64   `json:"heater_pct"`
+	Quality      string    `json:"quality"`
+}
+
+// weatherNight è il riassunto JSON di una notte.
+type weatherNight struct {
+	Date         string              `json:"date"`
+	Start        time.Time           `json:"start"`
+	End          time.Time           `json:"end"`
+	Astronomical bool                `json:"astronomical"`
+	Hours        int                 `json:"hours"`
+	ClearHours   int                 `json:"clear_hours"`
+	AvgCloud     *float64            `json:"avg_cloud"`
+	MinTemp      *float64            `json:"min_temperature_c"`
+	MinDewSpread *float64            `json:"min_dew_spread_c"`
+	MinVisKm     *float64            `json:"min_visibility_km"`
+	MaxGust      *float64            `json:"max_gust_ms"`
+	MaxPrecip    *float64            `json:"max_precip_prob"`
+	AvgSeeing    *float64            `json:"avg_seeing_arcsec"`
+	AvgTransp    *float64            `json:"avg_transparency"`
+	DewRisk      string              `json:"dew_risk"`
+	DewWarnings  []models.DewWarning `json:"dew_warnings,omitempty"`
+	Quality      string              `json:"quality"`
+}
+
+// weatherReport è l'output JSON di "astrolair weather".
+type weatherReport struct {
+	Lat      float64        `json:"lat"`
+	Lon      float64        `json:"lon"`
+	Timezone string         `json:"timezone"`
+	Current  *weatherHour   `json:"current,omitempty"`
+	Nights   []weatherNight `json:"nights"`
+	Hours    []weatherHour  `json:"hours"`
+}
+
+func runWeather(args []string) error {
+	fs := newFlagSet("weather")
+	lat := fs.Float64("lat", defaultLat, "latitudine in gradi decimali")
+	lon := fs.Float64("lon", defaultLon, "longitudine in gradi decimali (est positiva)")
+	days := fs.Int("days", 7, "giorni di previsione (1-7)")
+	hourly := fs.Bool("hourly", false, "elenca anche le previsioni ora per ora")
+	asJSON := fs.Bool("json", false, "output in formato JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fc, err := services.FetchForecast(*lat, *lon, *days)
+	if err != nil {
+		return fmt.Errorf("errore durante il download del meteo: %w", err)
+	}
+
+	now := time.Now()
+	rep := weatherReport{Lat: *lat, Lon: *lon, Timezone: fc.Timezone}
+	for _, h := range fc.Hours {
+		rep.Hours = append(rep.Hours, toWeatherHour(h))
+	}
+	if idx := services.CurrentHourIndex(*fc, now); idx >= 0 {
+		rep.Current = &rep.Hours[idx]
+	}
+	for _, n := range services.NightForecasts(*fc, *lat, *lon, now) {
+		rep.Nights = append(rep.Nights, weatherNight{
+			Date:         n.Date.Format("2006-01-02"),
+			Start:        n.Start,
+			End:          n.End,
+			Astronomical: n.Astronomical,
+			Hours:        n.Hours,
+			ClearHours:   n.ClearHours,
+			AvgCloud:     optional(n.AvgCloudPct),
+			MinTemp:      optional(n.MinTemperatureC),
+			MinDewSpread: optional(n.MinDewSpreadC),
+			MinVisKm:     optional(n.MinVisibilityKm),
+			MaxGust:      optional(n.MaxGustMS),
+			MaxPrecip:    optional(n.MaxPrecipProbPct),
+			AvgSeeing:    optional(n.AvgSeeingArcsec),
+			AvgTransp:    optional(n.AvgTransparencyPct),
+			DewRisk:      services.DewRiskLabel(n.MaxDewLevel, n.Frost),
+			DewWarnings:  n.DewWarnings,
+			Quality:      n.Quality,
+		})
+	}
+
+	if *asJSON {
+		return writeJSON(rep)
+	}
+
+	fmt.Fprintf(stdout, "Previsioni per %.4f, %.4f (fuso %s)\n", *lat, *lon, fc.Timezone)
+	if c := rep.Current; c != nil {
+		fmt.Fprintf(stdout, "Adesso (%s): nuvole %s, T %s, rugiada %s, vento %s, raffiche %s — %s\n",
+			c.Time.Format("15:04"), pct(c.CloudCover), temp(c.Temperature), temp(c.DewPoint),
+			wind(c.WindSpeed), wind(c.WindGusts), c.Quality)
+	}
+	fmt.Fprintln(stdout)
+
+	tw := newTable()
+	fmt.Fprintln(tw, "NOTTE\tBUIO\tNUVOLE\tSERENE\tSEEING\tTRASP.\tT MIN\tT-RUGIADA\tRISCHIO RUG.\tRAFFICHE\tPIOGGIA\tCIELO")
+	for _, n := range rep.Nights {
+		loc := fc.Hours[0].Time.Location()
+		span := n.Start.In(loc).Format("15:04") + "-" + n.End.In(loc).Format("15:04")
+		if !n.Astronomical {
+			span += "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d/%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			n.Date, span, pct(n.AvgCloud), n.ClearHours, n.Hours, arcsec(n.AvgSeeing), pct(n.AvgTransp), temp(n.MinTemp),
+			temp(n.MinDewSpread), n.DewRisk, wind(n.MaxGust), pct(n.MaxPrecip), n.Quality)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// intervalli a rischio rugiada con la potenza consigliata delle fasce
+	for _, n := range rep.Nights {
+		for _, w := range n.DewWarnings {
+			loc := fc.Hours[0].Time.Location()
+			fmt.Fprintf(stdout, "Rugiada %s: %s-%s rischio %s, fasce al %.0f%%\n",
+				n.Date, w.Start.In(loc).Format("15:04"), w.End.In(loc).Format("15:04"),
+				services.DewRiskLabel(w.MaxLevel, w.Frost), w.MaxHeaterPct)
+		}
+	}
+
+	if !*hourly {
+		return nil
+	}
+	fmt.Fprintln(stdout)
+	tw = newTable()
+	fmt.Fprintln(tw, "ORA\tNUVOLE\tBASSE\tMEDIE\tALTE\tT\tRUGIADA\tVISIB.\tVENTO\tRAFFICHE\tPIOGGIA\tGETTO\tSEEING\tTRASP.\tRISCHIO RUG.")
+	for _, h := range rep.Hours {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			h.Time.Format("2006-01-02 15:04"), pct(h.CloudCover), pct(h.CloudLow), pct(h.CloudMid),
+			pct(h.CloudHigh), temp(h.Temperature), temp(h.DewPoint), visibility(h.Visibility),
+			wind(h.WindSpeed), wind(h.WindGusts), pct(h.PrecipProb), wind(h.Jet), arcsec(h.Seeing), pct(h.Transparency), h.DewRisk)
+	}
+	return tw.Flush()
+}
+
+func toWeatherHour(h models.WeatherHour) weatherHour {
+	dew := services.AssessDew(h)
+	return weatherHour{
+		Time:         h.Time,
+		Temperature:  optional(h.TemperatureC),
+		DewPoint:     optional(h.DewPointC),
+		Humidity:     optional(h.HumidityPct),
+		CloudCover:   optional(h.CloudCoverPct),
+		CloudLow:     optional(h.CloudLowPct),
+		CloudMid:     optional(h.CloudMidPct),
+		CloudHigh:    optional(h.CloudHighPct),
+		Visibility:   optional(h.VisibilityM),
+		WindSpeed:    optional(h.WindSpeedMS),
+		WindGusts:    optional(h.WindGustsMS),
+		PrecipProb:   optional(h.PrecipProbPct),
+		Jet:          optional(services.JetStreamMS(h)),
+		Seeing:       optional(h.SeeingArcsec),
+		Transparency: optional(h.TransparencyPct),
+		DewMargin:    optional(dew.MarginC),
+		DewRisk:      services.DewRiskLabel(dew.Level, dew.Frost),
+		HeaterPct:    dew.HeaterPct,
+		Quality:      services.SkyQuality(h.CloudCoverPct, h.WindSpeedMS),
+	}
+}
+
+// optional converte NaN (valore mancante) in nil per il JSON.
+func optional(v float64) *float64 {
+	if math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
+
+// Formattazione dei valori opzionali per le tabelle ("-" se mancanti)
+
+func pct(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", *v)
+}
+
+func temp(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f°C", *v)
+}
+
+func wind(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f m/s", *v)
+}
+
+func arcsec(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f\"", *v)
+}
+
+func visibility(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f km", *v/1000)
+}
@@ -0,0 +1,78 @@
+// Command dsogen genera catalog/dso_catalog.json dai CSV di OpenNGC
+// (NGC.csv + addendum.csv, separati da ';') letti da file locali.
+//
+// Uso:
+//
+//	go run ./cmd/dsogen -ngc NGC.csv -addendum addendum.csv -out catalog/dso_catalog.json
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+func main() {
+	ngcPath := flag.String("ngc", "NGC.csv", "percorso di NGC.csv (OpenNGC)")
+	addendumPath := flag.String("addendum", "addendum.csv", "percorso di addendum.csv (vuoto per saltarlo)")
+	outPath := flag.String("out", services.DSOCatalogLocalPath, "file JSON da generare")
+	version := flag.Int("version", 1, "versione del catalogo scritta nel JSON")
+	stats := flag.Bool("stats", true, "stampa il report delle statistiche")
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.Println("=== Astro-Lair DSO Catalog Generator ===")
+
+	ngcRows := readRows(*ngcPath)
+	var addRows []services.OpenNGCRow
+	if *addendumPath != "" {
+		addRows = readRows(*addendumPath)
+	}
+
+	cat, st := services.GenerateDsoCatalog(ngcRows, addRows, *version, time.Now())
+	if err := services.ValidateDsoCatalog(cat); err != nil {
+		log.Fatalf("[ERROR] Catalogo generato non valido: %v", err)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cat); err != nil {
+		log.Fatalf("[ERROR] Serializzazione JSON: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*outPath), 0o755); err != nil {
+		log.Fatalf("[ERROR] %v", err)
+	}
+	if err := os.WriteFile(*outPath, buf.Bytes(), 0o644); err != nil {
+		log.Fatalf("[ERROR] Salvataggio %s: %v", *outPath, err)
+	}
+	log.Printf("[INFO] Catalogo scritto in %s (%d oggetti, %d byte)", *outPath, cat.ObjectCount, buf.Len())
+
+	if *stats {
+		log.Println()
+		st.WriteReport(os.Stdout)
+	}
+}
+
+func readRows(path string) []services.OpenNGCRow {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("[ERROR] %v", err)
+	}
+	defer f.Close()
+
+	rows, err := services.ReadOpenNGCCSV(f)
+	if err != nil {
+		log.Fatalf("[ERROR] Lettura %s: %v", path, err)
+	}
+	log.Printf("[INFO] %s → %d righe lette", path, len(rows))
+	return rows
+}
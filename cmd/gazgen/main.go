@@ -0,0 +1,52 @@
+// Command gazgen genera services/assets/gazetteer.tsv.gz, il gazetteer
+// offline usato per cercare le località per nome, da un dump di GeoNames
+// (cities1000.txt, cities5000.txt, ...) letto da file locale.
+//
+// Uso:
+//
+//	go run ./cmd/gazgen -in cities1000.txt -out services/assets/gazetteer.tsv.gz
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+func main() {
+	inPath := flag.String("in", "cities1000.txt", "dump GeoNames da convertire")
+	outPath := flag.String("out", "services/assets/gazetteer.tsv.gz", "file da generare")
+	minPop := flag.Int("min-pop", 1000, "popolazione minima delle località")
+	altMinPop := flag.Int("alt-min-pop", 100000, "popolazione minima per tenere i nomi alternativi (0 = mai)")
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.Println("=== Astro-Lair Gazetteer Generator ===")
+
+	f, err := os.Open(*inPath)
+	if err != nil {
+		log.Fatalf("[ERROR] %v", err)
+	}
+	defer f.Close()
+
+	places, err := services.ReadGeoNamesCities(f, services.GeoNamesOptions{
+		MinPopulation:    *minPop,
+		AltMinPopulation: *altMinPop,
+	})
+	if err != nil {
+		log.Fatalf("[ERROR] Lettura %s: %v", *inPath, err)
+	}
+	log.Printf("[INFO] %s → %d località", *inPath, len(places))
+
+	var buf bytes.Buffer
+	if err := services.WriteGazetteer(&buf, places); err != nil {
+		log.Fatalf("[ERROR] Compressione: %v", err)
+	}
+	if err := os.WriteFile(*outPath, buf.Bytes(), 0o644); err != nil {
+		log.Fatalf("[ERROR] Salvataggio %s: %v", *outPath, err)
+	}
+	log.Printf("[INFO] Gazetteer scritto in %s (%d byte)", *outPath, buf.Len())
+}
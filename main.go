@@ -2,8 +2,9 @@ package main
 
 import (
 	"image/color"
+	"os"
 
-	"github.com/cr4sh87/astro-lair-go/services"
+	"github.com/cr4sh87/astro-lair-go/cli"
 	"github.com/cr4sh87/astro-lair-go/ui"
 
 	"fyne.io/fyne/v2"
@@ -15,15 +16,16 @@ import (
 )
 
 func main() {
+	// Modalità headless: "astrolair moon|targets|weather|sats ..." non apre finestre
+	if len(os.Args) > 1 && cli.HasCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
 	a := app.NewWithID("com.cr4sh.astrolair.go")
 	w := a.NewWindow("Astro-Lair (Go Edition)")
 
-	// 🔭 Prima cosa: aggiorna il catalogo DSO dal repo GitHub
-	// Se fallisce, buildTargetsCatalog() userà comunque il file esistente (se c'è).
-	services.UpdateDSOCatalogFromGitHub()
-
-	// Inizializza la configurazione dell'equipaggio nel package ui
-	ui.SetEquipmentConfig(ui.NewDefaultEquipmentConfig())
+	// Configurazione dell'equipaggiamento salvata (o predefinita)
+	ui.SetEquipmentConfig(ui.LoadEquipmentConfig())
 
 	// Inizializza il provider dei sprite della luna
 	ui.InitMoonProviderForUI()
@@ -44,35 +46,61 @@ func main() {
 		ui.ShowEquipmentDialog(w)
 	})
 
+	// Sito di osservazione: tutte le tab seguono la selezione
+	siteSelector := ui.BuildSiteSelector(w)
+
 	topBar := container.NewBorder(
 		nil,
 		nil,
 		titleBox,
 		settingsBtn,
+		siteSelector,
 	)
 
-	// Qui buildTargetsCatalog() leggerà il file aggiornato in catalog/dso_catalog.json
+	// buildTargetsCatalog() legge il file locale in catalog/dso_catalog.json;
+	// l'aggiornamento dal repo GitHub (o dal mirror configurato) gira in background
+	// e ricarica la vista solo se arriva un catalogo valido e più recente.
 	targetsView := ui.BuildTargetsViewPublic(ui.BuildCatalog())
+	targetsView.StartCatalogUpdate()
 
 	// Satellites ora ritorna anche una funzione di refresh
 	satView, satRefresh := ui.BuildSatellitesViewPublic()
+	planetsView, planetsRefresh := ui.BuildPlanetsViewPublic()
+
+	// Home: le card aprono la tab di dettaglio
+	var tabs *container.AppTabs
+	homeView, homeRefresh := ui.BuildHomeView(targetsView.AllTargets, func(name string) {
+		for _, ti := range tabs.Items {
+			if ti.Text == name {
+				tabs.Select(ti)
+				return
+			}
+		}
+	})
 
-	tabs := container.NewAppTabs(
-		container.NewTabItem("Home", ui.BuildHomeView()),
+	tabs = container.NewAppTabs(
+		container.NewTabItem("Home", homeView),
 		container.NewTabItem("Moon", ui.BuildMoonViewPublic()),
 		container.NewTabItem("Weather", ui.BuildWeatherViewPublic()),
 		container.NewTabItem("SpaceWeather", ui.BuildSpaceWeatherView()),
 		container.NewTabItem("SOHO", ui.BuildSohoView()),
+		container.NewTabItem("Planets", planetsView),
 		container.NewTabItem("Satellites", satView),
 		container.NewTabItem("Targets", targetsView.Widget()),
 		container.NewTabItem("Tools", ui.BuildToolsView()),
 	)
 	tabs.SetTabLocation(container.TabLocationBottom)
 
-	// Quando entri nella tab Satellites → simula "Adesso"
+	// Quando entri nella tab Satellites → simula "Adesso";
+	// Planets e Home ricalcolano la notte (il sito può essere cambiato)
 	tabs.OnChanged = func(ti *container.TabItem) {
-		if ti.Text == "Satellites" {
+		switch ti.Text {
+		case "Home":
+			homeRefresh()
+		case "Satellites":
 			satRefresh()
+		case "Planets":
+			planetsRefresh()
 		}
 	}
 
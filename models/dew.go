@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// DewRisk — Rischio di condensa sull'ottica per un'ora di previsione.
+// Level: 0 nessuno, 1 basso, 2 moderato, 3 alto, 4 condensa in atto
+// (brina se Frost). HeaterPct è la potenza consigliata delle fasce (0-100%).
+type DewRisk struct {
+	Time        time.Time `json:"time"`
+	OpticsTempC float64   `json:"optics_temp_c"` // ottica raffreddata per irraggiamento
+	MarginC     float64   `json:"margin_c"`      // ottica − punto di rugiada
+	Level       int       `json:"level"`
+	Frost       bool      `json:"frost,omitempty"`
+	HeaterPct   float64   `json:"heater_pct"`
+}
+
+// DewWarning — Intervallo continuo con rischio almeno moderato.
+type DewWarning struct {
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	MaxLevel     int       `json:"max_level"`
+	Frost        bool      `json:"frost,omitempty"`
+	MaxHeaterPct float64   `json:"max_heater_pct"`
+}
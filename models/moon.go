@@ -1,8 +1,45 @@
 package models
 
-// BestNightEntry — Notte migliore per l'osservazione
+import "time"
+
+// BestNightEntry — Notte migliore per l'osservazione: buio astronomico
+// senza Luna sopra l'orizzonte.
 type BestNightEntry struct {
-	Date         string
-	Illumination float64
-	Quality      string
+	Date         time.Time     `json:"date"`       // giorno la cui sera apre la notte
+	DarkStart    time.Time     `json:"dark_start"` // buio astronomico
+	DarkEnd      time.Time     `json:"dark_end"`
+	Start        time.Time     `json:"start"` // finestra buia senza Luna più lunga
+	End          time.Time     `json:"end"`
+	Windows      []NightWindow `json:"windows"`    // tutte le finestre senza Luna
+	DarkHours    float64       `json:"dark_hours"` // ore totali di buio senza Luna
+	Illumination float64       `json:"illumination"`
+	PhaseName    string        `json:"phase_name"`
+	Quality      string        `json:"quality"`
+}
+
+// MoonPosition — Efemeride della Luna per un sito e un istante.
+// Le coordinate topocentriche includono la parallasse (fino a ~1°).
+type MoonPosition struct {
+	RADeg             float64 `json:"ra_deg"`  // geocentrica apparente
+	DecDeg            float64 `json:"dec_deg"` // geocentrica apparente
+	TopoRADeg         float64 `json:"topo_ra_deg"`
+	TopoDecDeg        float64 `json:"topo_dec_deg"`
+	AltDeg            float64 `json:"alt_deg"` // topocentrica, senza rifrazione
+	AzDeg             float64 `json:"az_deg"`
+	DistanceKm        float64 `json:"distance_km"`
+	ElongationDeg     float64 `json:"elongation_deg"`  // distanza angolare dal Sole
+	PhaseAngleDeg     float64 `json:"phase_angle_deg"` // angolo Sole-Luna-Terra
+	Illumination      float64 `json:"illumination"`    // frazione illuminata 0..1
+	BrightLimbDeg     float64 `json:"bright_limb_deg"` // angolo di posizione del lembo illuminato
+	Phase             float64 `json:"phase"`           // 0 = nuova, 0.25 = primo quarto, 0.5 = piena…
+	AgeDays           float64 `json:"age_days"`        // giorni dall'ultima Luna nuova
+	PhaseName         string  `json:"phase_name"`
+	AngularDiamArcmin float64 `json:"angular_diameter_arcmin"`
+}
+
+// MoonPhaseEvent — Istante di una fase principale (nuova, quarti, piena).
+type MoonPhaseEvent struct {
+	Name  string    `json:"name"`
+	Phase float64   `json:"phase"` // 0, 0.25, 0.5, 0.75
+	Time  time.Time `json:"time"`
 }
@@ -0,0 +1,16 @@
+package models
+
+// Place — Località del gazetteer offline (da GeoNames).
+// ElevationM è la quota dichiarata o, in mancanza, quella del modello del
+// terreno; Alternates sono i nomi in altre lingue usati dalla ricerca.
+type Place struct {
+	Name       string   `json:"name"`
+	ASCIIName  string   `json:"ascii_name,omitempty"`
+	Alternates []string `json:"alternates,omitempty"`
+	Country    string   `json:"country"` // codice ISO-3166 a due lettere
+	Lat        float64  `json:"lat"`
+	Lon        float64  `json:"lon"`
+	ElevationM float64  `json:"elevation_m"`
+	Timezone   string   `json:"timezone"`
+	Population int      `json:"population"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// PlanetPosition — Efemeride geocentrica di un pianeta.
+type PlanetPosition struct {
+	Name           string  `json:"name"`
+	RADeg          float64 `json:"ra_deg"`  // apparente, equinozio della data
+	DecDeg         float64 `json:"dec_deg"` // apparente, equinozio della data
+	DistanceAU     float64 `json:"distance_au"`
+	SunDistanceAU  float64 `json:"sun_distance_au"`
+	Magnitude      float64 `json:"magnitude"`
+	PhaseAngleDeg  float64 `json:"phase_angle_deg"` // angolo Sole-pianeta-Terra
+	Illumination   float64 `json:"illumination"`    // frazione illuminata 0..1
+	DiameterArcsec float64 `json:"diameter_arcsec"` // diametro equatoriale apparente
+	ElongationDeg  float64 `json:"elongation_deg"`  // + a est del Sole (sera), − a ovest (mattino)
+}
+
+// PlanetTonight — Un pianeta nella notte a un sito: posizione a metà notte,
+// sorgere/transito/tramonto e visibilità durante il buio astronomico
+// (NightVisibility è vuota se al sito non fa buio).
+type PlanetTonight struct {
+	PlanetPosition
+	RiseTransitSet
+	NightVisibility
+}
@@ -1,8 +1,39 @@
 package models
 
+import "time"
+
 // SatellitePos — Posizione di un satellite
+// X, Y sono in raggi equatoriali del pianeta, come appare dalla Terra:
+// X positivo verso ovest (a destra nello schema), Y positivo verso nord.
+// Behind = true significa "più lontano del pianeta" (dietro).
 type SatellitePos struct {
-	Name   string
-	X      float64
-	Behind bool
+	Name   string  `json:"name"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Behind bool    `json:"behind"`
+
+	// stato rispetto al disco del pianeta (calcolato solo per i Galileiani)
+	Transit  bool `json:"transit,omitempty"`  // davanti al disco
+	Occulted bool `json:"occulted,omitempty"` // nascosto dal disco
+	Eclipsed bool `json:"eclipsed,omitempty"` // nell'ombra del pianeta
+
+	// ombra proiettata sul disco (ShadowOnDisk = true), stesse unità di X/Y
+	ShadowOnDisk bool    `json:"shadow_on_disk,omitempty"`
+	ShadowX      float64 `json:"shadow_x,omitempty"`
+	ShadowY      float64 `json:"shadow_y,omitempty"`
+}
+
+// SatelliteEvent — Fenomeno di un satellite (transito, ombra, occultazione, eclisse).
+type SatelliteEvent struct {
+	Time      time.Time `json:"time"`
+	Satellite string    `json:"satellite"`
+	Kind      string    `json:"kind"`  // es. "Transito", "Transito ombra"
+	Start     bool      `json:"start"` // true = inizio, false = fine
+}
+
+// SaturnSystem — Satelliti di Saturno e geometria degli anelli vista dalla Terra.
+type SaturnSystem struct {
+	RingTiltDeg float64        `json:"ring_tilt_deg"` // B: apertura degli anelli (+ = faccia nord)
+	PolePADeg   float64        `json:"pole_pa_deg"`   // P: angolo di posizione del polo nord
+	Moons       []SatellitePos `json:"moons"`
 }
@@ -0,0 +1,21 @@
+package models
+
+// Site — Sito di osservazione salvato dall'utente.
+// Bortle e SQM a 0 significano "non indicato"; Timezone vuoto = fuso del dispositivo.
+type Site struct {
+	Name       string         `json:"name"`
+	Lat        float64        `json:"lat"`
+	Lon        float64        `json:"lon"`
+	ElevationM float64        `json:"elevation_m"`
+	Timezone   string         `json:"timezone"` // IANA, es. "Europe/Rome"
+	Bortle     int            `json:"bortle,omitempty"`
+	SQM        float64        `json:"sqm,omitempty"` // mag/arcsec²
+	Horizon    []HorizonPoint `json:"horizon,omitempty"`
+}
+
+// HorizonPoint — Altezza dell'orizzonte locale (alberi, edifici, rilievi)
+// a un dato azimut.
+type HorizonPoint struct {
+	AzDeg  float64 `json:"az_deg"`
+	AltDeg float64 `json:"alt_deg"`
+}
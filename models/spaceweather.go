@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// KpReading — Indice Kp planetario (0-9) misurato da NOAA SWPC.
+type KpReading struct {
+	Time   time.Time `json:"time"` // inizio dell'intervallo di 3 ore
+	Kp     float64   `json:"kp"`
+	Status string    `json:"status,omitempty"` // "observed", "estimated" o "predicted"
+}
+
+// Stati di un KpReading
+const (
+	KpObserved  = "observed"
+	KpEstimated = "estimated"
+	KpPredicted = "predicted"
+)
+
+// KpOutlook — Kp attuale, storico recente e previsione a 3 giorni.
+type KpOutlook struct {
+	Current  *KpReading  `json:"current,omitempty"` // stima al minuto più recente
+	History  []KpReading `json:"history"`           // intervalli di 3 ore osservati
+	Forecast []KpReading `json:"forecast"`          // intervalli stimati o previsti
+}
+
+// AuroraChance — Possibilità di vedere l'aurora da un sito.
+type AuroraChance struct {
+	GeomagLat    float64   `json:"geomag_lat"`     // latitudine geomagnetica del sito (°)
+	Kp           float64   `json:"kp"`             // Kp usato per la stima (NaN se manca)
+	OvalLat      float64   `json:"oval_lat"`       // bordo equatoriale dell'ovale (lat. geomagnetica)
+	ViewLat      float64   `json:"view_lat"`       // limite di visibilità all'orizzonte
+	KpForHorizon float64   `json:"kp_for_horizon"` // Kp minimo per vederla all'orizzonte (>9 = mai)
+	Overhead     bool      `json:"overhead"`       // il sito è sotto l'ovale
+	OnHorizon    bool      `json:"on_horizon"`     // visibile bassa verso il polo
+	NoDark       bool      `json:"no_dark"`        // nessun buio nautico (rimasto) stanotte
+	DarkHours    float64   `json:"dark_hours"`     // ore di buio nautico rimaste stanotte
+	PeakTime     time.Time `json:"peak_time"`      // intervallo col Kp massimo nella notte
+}
+
+// AuroraProbability — Probabilità di aurora del modello OVATION (NOAA SWPC)
+// al sito e nel punto più favorevole ancora visibile dal sito.
+type AuroraProbability struct {
+	ObservationTime time.Time `json:"observation_time"`
+	ForecastTime    time.Time `json:"forecast_time"`
+	SiteProb        float64   `json:"site_prob"`    // % allo zenit del sito
+	VisibleProb     float64   `json:"visible_prob"` // % del punto migliore, pesata con la sua altezza
+	VisibleLat      float64   `json:"visible_lat"`  // punto della griglia con VisibleProb
+	VisibleLon      float64   `json:"visible_lon"`
+	VisibleDistKm   float64   `json:"visible_dist_km"`  // distanza dal sito
+	VisibleElevDeg  float64   `json:"visible_elev_deg"` // altezza apparente sull'orizzonte
+	VisibleAzDeg    float64   `json:"visible_az_deg"`   // azimut (0 = nord)
+}
+
+// XRayFlux — Flusso X solare misurato dal GOES primario (W/m², NaN se manca).
+type XRayFlux struct {
+	Time  time.Time `json:"time"`
+	Long  float64   `json:"long"`  // banda 0.1-0.8 nm (definisce la classe del brillamento)
+	Short float64   `json:"short"` // banda 0.05-0.4 nm
+}
+
+// SolarFlare — Brillamento rilevato dal GOES (classe al picco).
+type SolarFlare struct {
+	Begin     time.Time  `json:"begin"`
+	Peak      time.Time  `json:"peak"`
+	End       *time.Time `json:"end,omitempty"` // nil se ancora in corso
+	Class     string     `json:"class"`         // es. "M2.4"
+	PeakFlux  float64    `json:"peak_flux"`     // W/m² nella banda 0.1-0.8 nm
+	Satellite int        `json:"satellite"`
+}
+
+// SolarWindSample — Vento solare in tempo reale a L1 (NaN se il valore manca).
+type SolarWindSample struct {
+	Time        time.Time `json:"time"`
+	Bt          float64   `json:"bt"`          // intensità del campo magnetico interplanetario (nT)
+	Bz          float64   `json:"bz"`          // componente nord-sud GSM (nT, negativa = verso sud)
+	Speed       float64   `json:"speed"`       // km/s
+	Density     float64   `json:"density"`     // protoni/cm³
+	Temperature float64   `json:"temperature"` // K
+}
+
+// BzSouthPeriod — Intervallo con Bz stabilmente verso sud.
+type BzSouthPeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	MinBz float64   `json:"min_bz"` // valore più negativo nell'intervallo
+}
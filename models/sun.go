@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// SunPosition — Posizione apparente del Sole per un sito e un istante.
+type SunPosition struct {
+	RADeg      float64 `json:"ra_deg"`
+	DecDeg     float64 `json:"dec_deg"`
+	AltDeg     float64 `json:"alt_deg"`
+	AzDeg      float64 `json:"az_deg"`
+	DistanceAU float64 `json:"distance_au"`
+}
+
+// SunEvents — Alba, tramonto e crepuscoli di un giorno (ora locale del sito).
+// Un evento nil non avviene quel giorno (es. niente buio astronomico d'estate
+// alle alte latitudini). PolarDay/PolarNight indicano che il Sole resta sempre
+// sopra/sotto l'orizzonte.
+type SunEvents struct {
+	Date             time.Time  `json:"date"`
+	AstronomicalDawn *time.Time `json:"astronomical_dawn,omitempty"`
+	NauticalDawn     *time.Time `json:"nautical_dawn,omitempty"`
+	CivilDawn        *time.Time `json:"civil_dawn,omitempty"`
+	Sunrise          *time.Time `json:"sunrise,omitempty"`
+	Transit          time.Time  `json:"transit"`
+	TransitAltDeg    float64    `json:"transit_alt_deg"`
+	Sunset           *time.Time `json:"sunset,omitempty"`
+	CivilDusk        *time.Time `json:"civil_dusk,omitempty"`
+	NauticalDusk     *time.Time `json:"nautical_dusk,omitempty"`
+	AstronomicalDusk *time.Time `json:"astronomical_dusk,omitempty"`
+	PolarDay         bool       `json:"polar_day,omitempty"`
+	PolarNight       bool       `json:"polar_night,omitempty"`
+}
@@ -5,15 +5,19 @@ package models
 // =======================
 
 type TargetObject struct {
-	Catalog       string
-	Code          string
-	Name          string
-	Type          string
-	Magnitude     float64
-	SurfaceBright *float64
-	RA            string
-	Dec           string
-	Constellation string
+	Catalog       string   `json:"catalog"`
+	Code          string   `json:"code"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Magnitude     *float64 `json:"magnitude,omitempty"` // nil se sconosciuta
+	SurfaceBright *float64 `json:"surface_brightness,omitempty"`
+	RA            string   `json:"ra"`
+	Dec           string   `json:"dec"`
+	RADeg         *float64 `json:"ra_deg,omitempty"`
+	DecDeg        *float64 `json:"dec_deg,omitempty"`
+	SizeMajor     *float64 `json:"size_major,omitempty"` // arcmin
+	SizeMinor     *float64 `json:"size_minor,omitempty"` // arcmin
+	Constellation string   `json:"constellation"`
 }
 
 func FloatPtr(f float64) *float64 { return &f }
@@ -25,10 +29,14 @@ var TargetsByaCatalog = map[string][]TargetObject{
 			Code:          "M31",
 			Name:          "Galassia di Andromeda",
 			Type:          "Galassia a spirale",
-			Magnitude:     3.4,
+			Magnitude:     FloatPtr(3.4),
 			SurfaceBright: FloatPtr(22.0),
 			RA:            "00h 42m 44s",
 			Dec:           "+41° 16′ 09″",
+			RADeg:         FloatPtr(10.6833),
+			DecDeg:        FloatPtr(41.2692),
+			SizeMajor:     FloatPtr(178),
+			SizeMinor:     FloatPtr(63),
 			Constellation: "Andromeda",
 		},
 		{
@@ -36,10 +44,14 @@ var TargetsByaCatalog = map[string][]TargetObject{
 			Code:          "M42",
 			Name:          "Nebulosa di Orione",
 			Type:          "Nebulosa a emissione",
-			Magnitude:     4.0,
+			Magnitude:     FloatPtr(4.0),
 			SurfaceBright: FloatPtr(21.0),
 			RA:            "05h 35m 17s",
 			Dec:           "−05° 23′ 28″",
+			RADeg:         FloatPtr(83.8208),
+			DecDeg:        FloatPtr(-5.3911),
+			SizeMajor:     FloatPtr(85),
+			SizeMinor:     FloatPtr(60),
 			Constellation: "Orione",
 		},
 		{
@@ -47,10 +59,14 @@ var TargetsByaCatalog = map[string][]TargetObject{
 			Code:          "M45",
 			Name:          "Pleiadi",
 			Type:          "Ammasso aperto",
-			Magnitude:     1.6,
+			Magnitude:     FloatPtr(1.6),
 			SurfaceBright: nil,
 			RA:            "03h 47m 24s",
 			Dec:           "+24° 07′ 00″",
+			RADeg:         FloatPtr(56.85),
+			DecDeg:        FloatPtr(24.1167),
+			SizeMajor:     FloatPtr(110),
+			SizeMinor:     FloatPtr(110),
 			Constellation: "Toro",
 		},
 	},
@@ -60,10 +76,14 @@ var TargetsByaCatalog = map[string][]TargetObject{
 			Code:          "NGC 7000",
 			Name:          "Nebulosa Nord America",
 			Type:          "Nebulosa a emissione",
-			Magnitude:     4.0,
+			Magnitude:     FloatPtr(4.0),
 			SurfaceBright: nil,
 			RA:            "20h 58m",
 			Dec:           "+44° 20′",
+			RADeg:         FloatPtr(314.5),
+			DecDeg:        FloatPtr(44.3333),
+			SizeMajor:     FloatPtr(120),
+			SizeMinor:     FloatPtr(100),
 			Constellation: "Cigno",
 		},
 		{
@@ -71,10 +91,14 @@ var TargetsByaCatalog = map[string][]TargetObject{
 			Code:          "NGC 253",
 			Name:          "Galassia dello Scultore",
 			Type:          "Galassia a spirale",
-			Magnitude:     8.0,
+			Magnitude:     FloatPtr(8.0),
 			SurfaceBright: FloatPtr(22.5),
 			RA:            "00h 47m 33s",
 			Dec:           "−25° 17′ 18″",
+			RADeg:         FloatPtr(11.8875),
+			DecDeg:        FloatPtr(-25.2883),
+			SizeMajor:     FloatPtr(27.5),
+			SizeMinor:     FloatPtr(6.8),
 			Constellation: "Scultore",
 		},
 	},
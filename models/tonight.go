@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// TonightSummary — Riepilogo della notte per il sito salvato (tab Home).
+// Weather e Kp sono nil se i dati non sono disponibili.
+type TonightSummary struct {
+	Date            time.Time       `json:"date"` // giorno la cui sera apre la notte
+	Lat             float64         `json:"lat"`
+	Lon             float64         `json:"lon"`
+	Sun             SunEvents       `json:"sun"`
+	Dark            *NightWindow    `json:"dark,omitempty"` // buio astronomico, nil se non c'è
+	Night           NightWindow     `json:"night"`          // buio astronomico o tramonto-alba
+	Moon            MoonPosition    `json:"moon"`           // a inizio notte (o adesso, se già iniziata)
+	MoonRise        *time.Time      `json:"moon_rise,omitempty"`
+	MoonSet         *time.Time      `json:"moon_set,omitempty"`
+	MoonlessWindows []NightWindow   `json:"moonless_windows,omitempty"` // buio senza Luna
+	MoonlessHours   float64         `json:"moonless_hours"`
+	Weather         *NightForecast  `json:"weather,omitempty"`
+	Hours           []WeatherHour   `json:"hours,omitempty"` // previsione oraria della notte
+	Kp              *KpReading      `json:"kp,omitempty"`
+	Score           int             `json:"score"`   // 0-100
+	Verdict         string          `json:"verdict"` // GO / FORSE / NO-GO
+	Reasons         []string        `json:"reasons,omitempty"`
+	Targets         []TonightTarget `json:"targets,omitempty"`
+}
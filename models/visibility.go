@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// RiseTransitSet — Sorgere, transito e tramonto di un oggetto.
+// Rise/Set sono nil se l'oggetto è circumpolare o non sorge mai.
+type RiseTransitSet struct {
+	Rise        *time.Time `json:"rise,omitempty"`
+	Transit     *time.Time `json:"transit,omitempty"`
+	Set         *time.Time `json:"set,omitempty"`
+	Circumpolar bool       `json:"circumpolar,omitempty"`
+	NeverRises  bool       `json:"never_rises,omitempty"`
+}
+
+// TargetVisibility — Posizione di un target per un sito e un istante.
+type TargetVisibility struct {
+	AltDeg       float64 `json:"alt_deg"`
+	AzDeg        float64 `json:"az_deg"`
+	HourAngleDeg float64 `json:"hour_angle_deg"`
+	Airmass      float64 `json:"airmass"` // 0 = sotto l'orizzonte
+	RiseTransitSet
+	Moon MoonInterference `json:"moon"`
+}
+
+// NightVisibility — Visibilità di un target durante il buio astronomico.
+type NightVisibility struct {
+	HoursAbove float64   `json:"hours_above"` // ore sopra l'altezza minima
+	MaxAltDeg  float64   `json:"max_alt_deg"`
+	MaxAltTime time.Time `json:"max_alt_time"`
+}
+
+// NightWindow — Finestra di buio astronomico per un sito.
+type NightWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// TonightFilter — Criteri della modalità "visibili stanotte".
+// MaxMagnitude/MinSizeArcmin a 0 disattivano il relativo filtro.
+type TonightFilter struct {
+	MinAltDeg     float64 `json:"min_alt_deg"`
+	MaxMagnitude  float64 `json:"max_magnitude"`
+	MinSizeArcmin float64 `json:"min_size_arcmin"`
+}
+
+// TonightTarget — Target che passa i filtri della modalità "visibili stanotte".
+type TonightTarget struct {
+	Target TargetObject `json:"target"`
+	NightVisibility
+}
+
+// MoonInterference — Disturbo della Luna su un target.
+// SkyBrighteningMag è lo schiarimento del fondo cielo in mag/arcsec²
+// (modello di Krisciunas & Schaefer); 0 se la Luna è sotto l'orizzonte.
+type MoonInterference struct {
+	SeparationDeg     float64 `json:"separation_deg"`
+	SkyBrighteningMag float64 `json:"sky_brightening_mag"`
+	MoonUp            bool    `json:"moon_up"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// WeatherHour — Previsione di un'ora (Open-Meteo). I valori mancanti sono NaN.
+type WeatherHour struct {
+	Time          time.Time `json:"time"` // inizio dell'ora, nel fuso del sito
+	TemperatureC  float64   `json:"temperature_c"`
+	DewPointC     float64   `json:"dew_point_c"`
+	HumidityPct   float64   `json:"humidity_pct"`
+	CloudCoverPct float64   `json:"cloud_cover_pct"`
+	CloudLowPct   float64   `json:"cloud_low_pct"`
+	CloudMidPct   float64   `json:"cloud_mid_pct"`
+	CloudHighPct  float64   `json:"cloud_high_pct"`
+	VisibilityM   float64   `json:"visibility_m"`
+	WindSpeedMS   float64   `json:"wind_speed_ms"`
+	WindGustsMS   float64   `json:"wind_gusts_ms"`
+	PrecipProbPct float64   `json:"precip_prob_pct"`
+
+	// livelli di pressione: corrente a getto e profilo della troposfera
+	Wind200MS float64 `json:"wind_200hpa_ms"`
+	Wind250MS float64 `json:"wind_250hpa_ms"`
+	Temp850C  float64 `json:"temp_850hpa_c"`
+	Temp700C  float64 `json:"temp_700hpa_c"`
+	Temp500C  float64 `json:"temp_500hpa_c"`
+	RH850Pct  float64 `json:"rh_850hpa_pct"`
+	RH700Pct  float64 `json:"rh_700hpa_pct"`
+	RH500Pct  float64 `json:"rh_500hpa_pct"`
+
+	// stime derivate (vedi services.EstimateSeeing / EstimateTransparency)
+	SeeingArcsec    float64 `json:"seeing_arcsec"`
+	TransparencyPct float64 `json:"transparency_pct"`
+}
+
+// WeatherForecast — Previsione oraria per un sito.
+type WeatherForecast struct {
+	Lat      float64       `json:"lat"`
+	Lon      float64       `json:"lon"`
+	Timezone string        `json:"timezone"`
+	Hours    []WeatherHour `json:"hours"`
+}
+
+// NightForecast — Riassunto della previsione su una notte.
+// Astronomical indica se Start/End sono il buio astronomico (altrimenti
+// tramonto-alba). I valori non disponibili sono NaN.
+type NightForecast struct {
+	Date               time.Time    `json:"date"` // giorno la cui sera apre la notte
+	Start              time.Time    `json:"start"`
+	End                time.Time    `json:"end"`
+	Astronomical       bool         `json:"astronomical"`
+	Hours              int          `json:"hours"`       // ore di previsione nella notte
+	ClearHours         int          `json:"clear_hours"` // nuvole < 20% e pioggia < 20%
+	AvgCloudPct        float64      `json:"avg_cloud_pct"`
+	MinTemperatureC    float64      `json:"min_temperature_c"`
+	MinDewSpreadC      float64      `json:"min_dew_spread_c"` // temperatura − punto di rugiada
+	MinVisibilityKm    float64      `json:"min_visibility_km"`
+	MaxWindMS          float64      `json:"max_wind_ms"`
+	MaxGustMS          float64      `json:"max_gust_ms"`
+	MaxPrecipProbPct   float64      `json:"max_precip_prob_pct"`
+	AvgSeeingArcsec    float64      `json:"avg_seeing_arcsec"`
+	AvgTransparencyPct float64      `json:"avg_transparency_pct"`
+	MaxDewLevel        int          `json:"max_dew_level"` // livello massimo di rischio rugiada (vedi DewRisk)
+	Frost              bool         `json:"frost,omitempty"`
+	DewWarnings        []DewWarning `json:"dew_warnings,omitempty"`
+	Quality            string       `json:"quality"`
+}
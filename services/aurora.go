@@ -0,0 +1,132 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Aurora: latitudine geomagnetica e visibilità
+// =======================
+
+// Polo nord geomagnetico del dipolo IGRF-14 (epoca 2025)
+const (
+	geomagPoleLat = 80.8
+	geomagPoleLon = -72.7
+)
+
+// Modello semplice dell'ovale aurorale in funzione del Kp: il bordo
+// equatoriale sta a ~66.5° di latitudine geomagnetica con Kp 0 e scende di
+// ~2° per unità di Kp. L'aurora (alta 100-300 km) si vede bassa
+// sull'orizzonte ~2.5° più verso l'equatore, come la "view line" delle
+// mappe NOAA (Kp 5 ≈ 54°, Kp 7 ≈ 50°, Kp 9 ≈ 46°).
+const (
+	auroraOvalKp0     = 66.5
+	auroraOvalPerKp   = 2.0
+	auroraViewMargin  = 2.5
+	auroraMaxKp       = 9.0
+	auroraTonightStep = 3 * time.Hour
+)
+
+// GeomagneticLatitude restituisce la latitudine geomagnetica (dipolo
+// centrato) di un punto, in gradi; positiva nell'emisfero nord.
+func GeomagneticLatitude(latDeg, lonDeg float64) float64 {
+	lat, lon := latDeg*deg2rad, lonDeg*deg2rad
+	plat, plon := geomagPoleLat*deg2rad, geomagPoleLon*deg2rad
+	s := math.Sin(lat)*math.Sin(plat) + math.Cos(lat)*math.Cos(plat)*math.Cos(lon-plon)
+	return math.Asin(math.Max(-1, math.Min(1, s))) * rad2deg
+}
+
+// AuroraOvalLat è la latitudine geomagnetica del bordo equatoriale
+// dell'ovale aurorale (aurora allo zenit) per un dato Kp.
+func AuroraOvalLat(kp float64) float64 {
+	return auroraOvalKp0 - auroraOvalPerKp*kp
+}
+
+// AuroraViewLat è la latitudine geomagnetica minima da cui l'aurora si vede
+// bassa sull'orizzonte verso il polo.
+func AuroraViewLat(kp float64) float64 {
+	return AuroraOvalLat(kp) - auroraViewMargin
+}
+
+// AuroraChanceAt stima la visibilità dell'aurora da lat/lon con l'indice kp.
+func AuroraChanceAt(latDeg, lonDeg, kp float64) models.AuroraChance {
+	m := GeomagneticLatitude(latDeg, lonDeg)
+	abs := math.Abs(m)
+	c := models.AuroraChance{
+		GeomagLat:    m,
+		Kp:           kp,
+		OvalLat:      AuroraOvalLat(kp),
+		ViewLat:      AuroraViewLat(kp),
+		KpForHorizon: math.Max(0, (auroraOvalKp0-auroraViewMargin-abs)/auroraOvalPerKp),
+	}
+	c.Overhead = abs >= c.OvalLat
+	c.OnHorizon = abs >= c.ViewLat
+	return c
+}
+
+// AuroraTonight stima la visibilità nella notte che contiene now (o che
+// comincia la sera di now) usando il Kp più alto previsto durante il buio
+// nautico ancora da venire. Senza previsioni che coprano la notte si usa il
+// Kp attuale.
+func AuroraTonight(o models.KpOutlook, now time.Time, latDeg, lonDeg float64) models.AuroraChance {
+	start, end, ok := DarkWindow(NightDay(now), latDeg, lonDeg, NauticalTwilightAlt)
+	if ok && now.After(start) {
+		start = now
+	}
+
+	var peak *models.KpReading
+	if ok && end.After(start) {
+		all := append(append([]models.KpReading(nil), o.History...), o.Forecast...)
+		for i := range all {
+			r := all[i]
+			if r.Time.Before(end) && r.Time.Add(auroraTonightStep).After(start) && (peak == nil || r.Kp > peak.Kp) {
+				peak = &r
+			}
+		}
+	}
+	if peak == nil {
+		peak = o.Current
+	}
+
+	var c models.AuroraChance
+	if peak != nil {
+		c = AuroraChanceAt(latDeg, lonDeg, peak.Kp)
+		c.PeakTime = peak.Time
+	} else {
+		c = AuroraChanceAt(latDeg, lonDeg, 0)
+		c.Kp = math.NaN()
+		c.Overhead, c.OnHorizon = false, false
+	}
+	if ok && end.After(start) {
+		c.DarkHours = end.Sub(start).Hours()
+	} else {
+		c.NoDark = true
+		c.Overhead, c.OnHorizon = false, false
+	}
+	return c
+}
+
+// AuroraLabel descrive in breve la visibilità stimata.
+func AuroraLabel(c models.AuroraChance) string {
+	toward := "nord"
+	if c.GeomagLat < 0 {
+		toward = "sud"
+	}
+	switch {
+	case c.NoDark:
+		return "niente buio stanotte"
+	case math.IsNaN(c.Kp):
+		return "Kp non disponibile"
+	case c.Overhead:
+		return "aurora possibile alta nel cielo"
+	case c.OnHorizon:
+		return "aurora possibile bassa all'orizzonte verso " + toward
+	case c.KpForHorizon > auroraMaxKp:
+		return "aurora non visibile nemmeno con Kp 9"
+	default:
+		return "aurora non visibile"
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Coordinate e tempo siderale
+// =======================
+
+const (
+	deg2rad = math.Pi / 180.0
+	rad2deg = 180.0 / math.Pi
+
+	// jdJ2000 è il giorno giuliano dell'epoca J2000.0
+	jdJ2000 = 2451545.0
+
+	// siderealToSolar converte un intervallo siderale in tempo solare medio
+	siderealToSolar = 0.9972695663
+
+	// StandardAltitudeStars è l'altezza geometrica del sorgere/tramonto di un
+	// oggetto puntiforme, corretta per la rifrazione atmosferica media.
+	StandardAltitudeStars = -0.5667
+)
+
+// JulianDay restituisce il giorno giuliano (UT) dell'istante t.
+func JulianDay(t time.Time) float64 {
+	return float64(t.UnixNano())/86400e9 + 2440587.5
+}
+
+// TimeFromJulianDay è l'inverso di JulianDay.
+func TimeFromJulianDay(jd float64) time.Time {
+	ns := (jd - 2440587.5) * 86400e9
+	return time.Unix(0, int64(math.Round(ns))).UTC()
+}
+
+// julianCenturies restituisce i secoli giuliani da J2000.0.
+func julianCenturies(jd float64) float64 {
+	return (jd - jdJ2000) / 36525.0
+}
+
+// normDeg riporta un angolo in [0, 360).
+func normDeg(a float64) float64 {
+	a = math.Mod(a, 360.0)
+	if a < 0 {
+		a += 360.0
+	}
+	return a
+}
+
+// normDeg180 riporta un angolo in (-180, 180].
+func normDeg180(a float64) float64 {
+	a = normDeg(a)
+	if a > 180 {
+		a -= 360
+	}
+	return a
+}
+
+// GreenwichSiderealDeg è il tempo siderale medio di Greenwich in gradi (Meeus 12.4).
+func GreenwichSiderealDeg(t time.Time) float64 {
+	jd := JulianDay(t)
+	T := julianCenturies(jd)
+	return normDeg(280.46061837 + 360.98564736629*(jd-jdJ2000) +
+		0.000387933*T*T - T*T*T/38710000.0)
+}
+
+// LocalSiderealDeg è il tempo siderale locale (longitudine est positiva).
+func LocalSiderealDeg(t time.Time, lonDeg float64) float64 {
+	return normDeg(GreenwichSiderealDeg(t) + lonDeg)
+}
+
+// EquatorialToHorizontal converte RA/Dec (gradi) in altezza/azimut per il sito
+// (lat, lon) all'istante t. Azimut da Nord verso Est, angolo orario in (-180, 180].
+func EquatorialToHorizontal(raDeg, decDeg, latDeg, lonDeg float64, t time.Time) (altDeg, azDeg, haDeg float64) {
+	haDeg = normDeg180(LocalSiderealDeg(t, lonDeg) - raDeg)
+
+	ha := haDeg * deg2rad
+	dec := decDeg * deg2rad
+	lat := latDeg * deg2rad
+
+	sinAlt := math.Sin(lat)*math.Sin(dec) + math.Cos(lat)*math.Cos(dec)*math.Cos(ha)
+	alt := math.Asin(math.Max(-1, math.Min(1, sinAlt)))
+
+	y := -math.Cos(dec) * math.Sin(ha)
+	x := math.Sin(dec)*math.Cos(lat) - math.Cos(dec)*math.Sin(lat)*math.Cos(ha)
+	az := math.Atan2(y, x)
+
+	return alt * rad2deg, normDeg(az * rad2deg), haDeg
+}
+
+// Airmass secondo Kasten & Young (1989); 0 se l'oggetto è sotto l'orizzonte.
+func Airmass(altDeg float64) float64 {
+	if altDeg <= 0 {
+		return 0
+	}
+	return 1.0 / (math.Sin(altDeg*deg2rad) + 0.50572*math.Pow(altDeg+6.07995, -1.6364))
+}
+
+// AngularSeparation restituisce la distanza angolare (gradi) tra due punti equatoriali.
+func AngularSeparation(ra1, dec1, ra2, dec2 float64) float64 {
+	d1, d2 := dec1*deg2rad, dec2*deg2rad
+	dra := (ra1 - ra2) * deg2rad
+	// formula di Vincenty: stabile anche per angoli piccoli
+	num := math.Hypot(math.Cos(d2)*math.Sin(dra), math.Cos(d1)*math.Sin(d2)-math.Sin(d1)*math.Cos(d2)*math.Cos(dra))
+	den := math.Sin(d1)*math.Sin(d2) + math.Cos(d1)*math.Cos(d2)*math.Cos(dra)
+	return math.Atan2(num, den) * rad2deg
+}
+
+// RiseTransitSet calcola per un oggetto fisso il transito più vicino a t e il
+// sorgere/tramonto che lo racchiudono, all'altezza h0Deg (es. StandardAltitudeStars).
+func RiseTransitSet(raDeg, decDeg, latDeg, lonDeg float64, t time.Time, h0Deg float64) models.RiseTransitSet {
+	var out models.RiseTransitSet
+
+	ha := normDeg180(LocalSiderealDeg(t, lonDeg) - raDeg)
+	transit := t.Add(-time.Duration(ha / 15.0 * siderealToSolar * float64(time.Hour)))
+	out.Transit = &transit
+
+	lat := latDeg * deg2rad
+	dec := decDeg * deg2rad
+	cosH0 := (math.Sin(h0Deg*deg2rad) - math.Sin(lat)*math.Sin(dec)) / (math.Cos(lat) * math.Cos(dec))
+
+	switch {
+	case cosH0 < -1:
+		out.Circumpolar = true
+	case cosH0 > 1:
+		out.NeverRises = true
+	default:
+		h0 := math.Acos(cosH0) * rad2deg
+		half := time.Duration(h0 / 15.0 * siderealToSolar * float64(time.Hour))
+		rise := transit.Add(-half)
+		set := transit.Add(half)
+		out.Rise = &rise
+		out.Set = &set
+	}
+	return out
+}
+
+// ComputeTargetVisibility calcola posizione orizzontale ed eventi di un target.
+// Restituisce false se il target non ha coordinate numeriche.
+func ComputeTargetVisibility(t models.TargetObject, latDeg, lonDeg float64, at time.Time) (models.TargetVisibility, bool) {
+	if t.RADeg == nil || t.DecDeg == nil {
+		return models.TargetVisibility{}, false
+	}
+
+	alt, az, ha := EquatorialToHorizontal(*t.RADeg, *t.DecDeg, latDeg, lonDeg, at)
+	return models.TargetVisibility{
+		AltDeg:         alt,
+		AzDeg:          az,
+		HourAngleDeg:   ha,
+		Airmass:        Airmass(alt),
+		RiseTransitSet: RiseTransitSet(*t.RADeg, *t.DecDeg, latDeg, lonDeg, at, StandardAltitudeStars),
+	}, true
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Rischio rugiada / brina sull'ottica
+// =======================
+//
+// Con il cielo sereno l'ottica irraggia verso lo spazio e si porta sotto la
+// temperatura dell'aria (fino a 2-3 °C), il vento la riscalda rimescolando
+// l'aria. La condensa si forma quando l'ottica scende sotto il punto di
+// rugiada; sotto zero diventa brina.
+
+const (
+	// raffreddamento radiativo massimo dell'ottica a cielo sereno e aria calma (°C)
+	maxRadiativeCoolingC = 2.5
+	// vento (m/s) che dimezza il raffreddamento radiativo
+	radiativeWindHalfMS = 4.0
+)
+
+// dewLevels sono i margini ottica − rugiada (°C) sotto cui scatta ciascun
+// livello di rischio, con la potenza delle fasce suggerita.
+var dewLevels = []struct {
+	belowC    float64
+	level     int
+	heaterPct float64
+}{
+	{0, 4, 100},
+	{1.5, 3, 75},
+	{3, 2, 50},
+	{5, 1, 25},
+}
+
+// AssessDew valuta il rischio di condensa per un'ora di previsione.
+// Restituisce Level 0 se mancano temperatura o punto di rugiada.
+func AssessDew(h models.WeatherHour) models.DewRisk {
+	r := models.DewRisk{Time: h.Time, OpticsTempC: math.NaN(), MarginC: math.NaN()}
+	if math.IsNaN(h.TemperatureC) || math.IsNaN(h.DewPointC) {
+		return r
+	}
+
+	clear := 1.0
+	if !math.IsNaN(h.CloudCoverPct) {
+		clear = 1 - h.CloudCoverPct/100
+	}
+	wind := 0.0
+	if !math.IsNaN(h.WindSpeedMS) {
+		wind = h.WindSpeedMS
+	}
+	cooling := maxRadiativeCoolingC * clear * radiativeWindHalfMS / (radiativeWindHalfMS + wind)
+
+	r.OpticsTempC = h.TemperatureC - cooling
+	r.MarginC = r.OpticsTempC - h.DewPointC
+
+	for _, l := range dewLevels {
+		if r.MarginC < l.belowC {
+			r.Level, r.HeaterPct = l.level, l.heaterPct
+			break
+		}
+	}
+	// aria quasi satura: almeno rischio moderato anche con margine apparente
+	if !math.IsNaN(h.HumidityPct) && h.HumidityPct >= 95 && r.Level < 2 {
+		r.Level, r.HeaterPct = 2, 50
+	}
+	if r.Level >= 3 && r.OpticsTempC <= 0 {
+		r.Frost = true
+		r.HeaterPct = 100
+	}
+	return r
+}
+
+// DewWarnings raggruppa le ore in [start, end) con rischio almeno moderato
+// in intervalli continui.
+func DewWarnings(hours []models.WeatherHour, start, end time.Time) []models.DewWarning {
+	var out []models.DewWarning
+	var cur *models.DewWarning
+	for _, h := range hours {
+		if !h.Time.Add(time.Hour).After(start) || !h.Time.Before(end) {
+			continue
+		}
+		r := AssessDew(h)
+		if r.Level < 2 {
+			cur = nil
+			continue
+		}
+		if cur == nil {
+			out = append(out, models.DewWarning{Start: h.Time})
+			cur = &out[len(out)-1]
+		}
+		cur.End = h.Time.Add(time.Hour)
+		cur.MaxLevel = max(cur.MaxLevel, r.Level)
+		cur.Frost = cur.Frost || r.Frost
+		cur.MaxHeaterPct = math.Max(cur.MaxHeaterPct, r.HeaterPct)
+	}
+	return out
+}
+
+// DewRiskLabel descrive un livello di rischio.
+func DewRiskLabel(level int, frost bool) string {
+	switch {
+	case level >= 4 && frost:
+		return "brina"
+	case level >= 4:
+		return "condensa"
+	case level == 3 && frost:
+		return "alto (brina)"
+	case level == 3:
+		return "alto"
+	case level == 2:
+		return "moderato"
+	case level == 1:
+		return "basso"
+	default:
+		return "nessuno"
+	}
+}
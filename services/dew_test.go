@@ -0,0 +1,89 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// dewHour costruisce un'ora di previsione con i soli campi usati da AssessDew.
+func dewHour(at time.Time, tempC, dewC, cloudPct, windMS, rhPct float64) models.WeatherHour {
+	return models.WeatherHour{Time: at, TemperatureC: tempC, DewPointC: dewC,
+		CloudCoverPct: cloudPct, WindSpeedMS: windMS, HumidityPct: rhPct}
+}
+
+func TestAssessDew(t *testing.T) {
+	at := time.Date(2024, 10, 5, 22, 0, 0, 0, time.UTC)
+	nan := math.NaN()
+	tests := []struct {
+		name      string
+		h         models.WeatherHour
+		optics    float64 // NaN se non calcolabile
+		level     int
+		frost     bool
+		heaterPct float64
+	}{
+		// raffreddamento radiativo: pieno a cielo sereno e aria calma,
+		// dimezzato da 4 m/s di vento, nullo con cielo coperto
+		{"sereno e calmo", dewHour(at, 10, 0, 0, 0, 50), 7.5, 0, false, 0},
+		{"sereno e ventoso", dewHour(at, 10, 0, 0, 4, 50), 8.75, 0, false, 0},
+		{"coperto", dewHour(at, 10, 0, 100, 0, 50), 10, 0, false, 0},
+		{"nuvole mancanti = sereno", dewHour(at, 10, 0, nan, nan, 50), 7.5, 0, false, 0},
+
+		// livelli dal margine ottica − rugiada (cielo coperto: ottica = aria)
+		{"margine 4 °C", dewHour(at, 10, 6, 100, 0, 70), 10, 1, false, 25},
+		{"margine 2 °C", dewHour(at, 10, 8, 100, 0, 85), 10, 2, false, 50},
+		{"margine 1 °C", dewHour(at, 10, 9, 100, 0, 90), 10, 3, false, 75},
+		{"ottica sotto la rugiada", dewHour(at, 10, 9, 0, 0, 90), 7.5, 4, false, 100},
+		{"aria satura", dewHour(at, 10, 4, 100, 0, 96), 10, 2, false, 50},
+
+		// brina: rischio alto con l'ottica sotto zero
+		{"brina", dewHour(at, 1, -1, 0, 0, 85), -1.5, 4, true, 100},
+		{"rischio alto sotto zero", dewHour(at, 2, -0.5, 0, 0, 80), -0.5, 3, true, 100},
+		{"dati mancanti", dewHour(at, nan, 5, 0, 0, 80), nan, 0, false, 0},
+	}
+	for _, tt := range tests {
+		r := AssessDew(tt.h)
+		if math.IsNaN(tt.optics) != math.IsNaN(r.OpticsTempC) ||
+			!math.IsNaN(tt.optics) && math.Abs(r.OpticsTempC-tt.optics) > 1e-9 {
+			t.Errorf("%s: ottica %.2f °C, attesa %.2f", tt.name, r.OpticsTempC, tt.optics)
+		}
+		if r.Level != tt.level || r.Frost != tt.frost || r.HeaterPct != tt.heaterPct {
+			t.Errorf("%s: livello %d, brina %v, fasce %.0f%%; attesi %d, %v, %.0f%%",
+				tt.name, r.Level, r.Frost, r.HeaterPct, tt.level, tt.frost, tt.heaterPct)
+		}
+	}
+}
+
+func TestDewWarnings(t *testing.T) {
+	t0 := time.Date(2024, 10, 5, 20, 0, 0, 0, time.UTC)
+	hour := func(i int) time.Time { return t0.Add(time.Duration(i) * time.Hour) }
+	safe := func(i int) models.WeatherHour { return dewHour(hour(i), 10, 0, 100, 0, 50) }
+	moderate := func(i int) models.WeatherHour { return dewHour(hour(i), 10, 8, 100, 0, 85) }
+	frost := func(i int) models.WeatherHour { return dewHour(hour(i), 1, -1, 0, 0, 85) }
+
+	hours := []models.WeatherHour{
+		moderate(0), // inizia prima della notte ma la sovrappone
+		frost(1),
+		safe(2),
+		moderate(3),
+		moderate(4),
+		frost(5), // dopo la fine della notte
+	}
+	got := DewWarnings(hours, hour(0).Add(30*time.Minute), hour(5))
+
+	want := []models.DewWarning{
+		{Start: hour(0), End: hour(2), MaxLevel: 4, Frost: true, MaxHeaterPct: 100},
+		{Start: hour(3), End: hour(5), MaxLevel: 2, MaxHeaterPct: 50},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d intervalli, attesi %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("intervallo %d = %+v, atteso %+v", i, got[i], want[i])
+		}
+	}
+}
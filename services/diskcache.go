@@ -0,0 +1,400 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =======================
+//  Cache su disco (HTTP)
+// =======================
+
+// CacheState descrive da dove arriva un contenuto restituito da FetchCached.
+type CacheState int
+
+const (
+	// CacheFresh: copia su disco ancora entro il TTL, nessuna richiesta di rete.
+	CacheFresh CacheState = iota
+	// CacheRevalidated: il server ha risposto 304, la copia su disco è ancora valida.
+	CacheRevalidated
+	// CacheDownloaded: contenuto nuovo scaricato (200).
+	CacheDownloaded
+	// CacheStale: la rete non ha risposto, restituita la copia scaduta.
+	CacheStale
+)
+
+// DefaultCacheTTL è la validità di una risorsa senza regola specifica.
+const DefaultCacheTTL = 30 * time.Minute
+
+// ImmutableCacheTTL si usa per risorse che non cambiano mai (es. frame con timestamp nel nome).
+const ImmutableCacheTTL = 7 * 24 * time.Hour
+
+// DefaultDiskCacheBudget è lo spazio massimo occupato dalla cache su disco.
+const DefaultDiskCacheBudget int64 = 200 << 20
+
+type ttlRule struct {
+	prefix string
+	ttl    time.Duration
+}
+
+// cacheTTLRules: TTL per sorgente, vince il prefisso più lungo.
+var cacheTTLRules = []ttlRule{
+	{"https://soho.nascom.nasa.gov/data/realtime/", 15 * time.Minute},
+	{"https://sohowww.nascom.nasa.gov/data/LATEST/", time.Hour},
+	{"https://services.swpc.noaa.gov/images/animations/suvi/", 5 * time.Minute},
+	{"https://services.swpc.noaa.gov/images/aurora-forecast-", 30 * time.Minute},
+	{"https://services.swpc.noaa.gov/images/swx-overview", 15 * time.Minute},
+}
+
+var ttlMu sync.RWMutex
+
+// SetCacheTTL imposta (o sostituisce) il TTL per gli URL che iniziano con prefix.
+func SetCacheTTL(prefix string, ttl time.Duration) {
+	ttlMu.Lock()
+	defer ttlMu.Unlock()
+
+	for i := range cacheTTLRules {
+		if cacheTTLRules[i].prefix == prefix {
+			cacheTTLRules[i].ttl = ttl
+			return
+		}
+	}
+	cacheTTLRules = append(cacheTTLRules, ttlRule{prefix: prefix, ttl: ttl})
+}
+
+// CacheTTL restituisce il TTL configurato per url.
+func CacheTTL(url string) time.Duration {
+	ttlMu.RLock()
+	defer ttlMu.RUnlock()
+
+	best, bestLen := DefaultCacheTTL, -1
+	for _, r := range cacheTTLRules {
+		if strings.HasPrefix(url, r.prefix) && len(r.prefix) > bestLen {
+			best, bestLen = r.ttl, len(r.prefix)
+		}
+	}
+	return best
+}
+
+// diskEntry sono i metadati (sidecar .json) di un file in cache.
+type diskEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	LastAccess   time.Time `json:"last_access"`
+	Size         int64     `json:"size"`
+}
+
+// diskCache mantiene l'indice in memoria dei file salvati in dir.
+type diskCache struct {
+	mu      sync.Mutex
+	dir     string
+	budget  int64
+	entries map[string]*diskEntry // chiave = hash dell'URL
+	loaded  bool
+}
+
+var httpDiskCache = &diskCache{budget: DefaultDiskCacheBudget}
+
+// SetDiskCacheBudget imposta lo spazio massimo (byte) della cache su disco.
+func SetDiskCacheBudget(bytes int64) {
+	httpDiskCache.mu.Lock()
+	httpDiskCache.budget = bytes
+	httpDiskCache.mu.Unlock()
+	httpDiskCache.evict()
+}
+
+// DiskCacheDir restituisce la cartella della cache su disco ("" se non disponibile).
+func DiskCacheDir() string {
+	httpDiskCache.mu.Lock()
+	defer httpDiskCache.mu.Unlock()
+	httpDiskCache.loadLocked()
+	return httpDiskCache.dir
+}
+
+func cacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadLocked inizializza la cartella e l'indice leggendo i sidecar esistenti.
+func (c *diskCache) loadLocked() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = make(map[string]*diskEntry)
+
+	base, err := os.UserCacheDir()
+	if err != nil {
+		log.Printf("[Cache] Cartella cache utente non disponibile, uso solo la memoria: %v\n", err)
+		return
+	}
+	dir := filepath.Join(base, "AstroLair", "http")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("[Cache] Impossibile creare %s: %v\n", dir, err)
+		return
+	}
+	c.openLocked(dir)
+}
+
+// openLocked legge l'indice da dir e rimuove i resti di scritture interrotte:
+// file temporanei di writeFileAtomic, dati senza sidecar e sidecar illeggibili.
+func (c *diskCache) openLocked(dir string) {
+	c.loaded = true
+	c.dir = dir
+	c.entries = make(map[string]*diskEntry)
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		log.Printf("[Cache] Impossibile leggere %s: %v\n", dir, err)
+		return
+	}
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		key := strings.TrimSuffix(name, ".json")
+		var e diskEntry
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			err = json.Unmarshal(data, &e)
+		}
+		if err != nil || e.URL == "" {
+			os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		c.entries[key] = &e
+	}
+
+	var orphans int
+	for _, f := range files {
+		name := f.Name()
+		orphan := strings.Contains(name, ".tmp")
+		if key, ok := strings.CutSuffix(name, ".bin"); ok && c.entries[key] == nil {
+			orphan = true
+		}
+		if orphan && !f.IsDir() {
+			os.Remove(filepath.Join(dir, name))
+			orphans++
+		}
+	}
+	if orphans > 0 {
+		log.Printf("[Cache] Rimossi %d file orfani da %s\n", orphans, dir)
+	}
+}
+
+func (c *diskCache) dataPath(key string) string { return filepath.Join(c.dir, key+".bin") }
+func (c *diskCache) metaPath(key string) string { return filepath.Join(c.dir, key+".json") }
+
+// lookup restituisce i dati in cache per url (se presenti) e una copia dei metadati.
+func (c *diskCache) lookup(url string) ([]byte, diskEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+
+	key := cacheKey(url)
+	e, ok := c.entries[key]
+	if !ok || c.dir == "" {
+		return nil, diskEntry{}, false
+	}
+	data, err := os.ReadFile(c.dataPath(key))
+	if err != nil {
+		delete(c.entries, key)
+		os.Remove(c.metaPath(key))
+		return nil, diskEntry{}, false
+	}
+	e.LastAccess = time.Now()
+	return data, *e, true
+}
+
+// store salva (o aggiorna) dati e metadati; data == nil aggiorna solo i metadati.
+func (c *diskCache) store(e diskEntry, data []byte) {
+	c.mu.Lock()
+	c.loadLocked()
+	if c.dir == "" {
+		c.mu.Unlock()
+		return
+	}
+
+	key := cacheKey(e.URL)
+	e.LastAccess = time.Now()
+	if data != nil {
+		e.Size = int64(len(data))
+		if err := writeFileAtomic(c.dataPath(key), data); err != nil {
+			c.mu.Unlock()
+			log.Printf("[Cache] Errore salvataggio %s: %v\n", e.URL, err)
+			return
+		}
+	}
+	if meta, err := json.Marshal(e); err == nil {
+		_ = writeFileAtomic(c.metaPath(key), meta)
+	}
+	c.entries[key] = &e
+	c.mu.Unlock()
+
+	c.evict()
+}
+
+// evict rimuove le voci usate meno di recente finché la cache non rientra nel budget.
+func (c *diskCache) evict() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dir == "" {
+		return
+	}
+
+	var total int64
+	keys := make([]string, 0, len(c.entries))
+	for k, e := range c.entries {
+		total += e.Size
+		keys = append(keys, k)
+	}
+	if total <= c.budget {
+		return
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].LastAccess.Before(c.entries[keys[j]].LastAccess)
+	})
+	for _, k := range keys {
+		if total <= c.budget {
+			break
+		}
+		total -= c.entries[k].Size
+		os.Remove(c.dataPath(k))
+		os.Remove(c.metaPath(k))
+		delete(c.entries, k)
+	}
+}
+
+// clear elimina tutti i file della cache su disco.
+func (c *diskCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+	for k := range c.entries {
+		os.Remove(c.dataPath(k))
+		os.Remove(c.metaPath(k))
+	}
+	c.entries = make(map[string]*diskEntry)
+}
+
+// writeFileAtomic scrive su un file temporaneo e lo rinomina sul percorso finale.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// CachedCopy restituisce subito l'eventuale copia su disco di url, senza rete,
+// con l'istante dell'ultimo download/validazione (vedi IsCacheFresh).
+func CachedCopy(url string) (data []byte, fetchedAt time.Time, ok bool) {
+	data, e, ok := httpDiskCache.lookup(url)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return data, e.FetchedAt, true
+}
+
+// CachedData è il risultato di FetchCached.
+type CachedData struct {
+	Data      []byte
+	State     CacheState
+	FetchedAt time.Time // ultimo download o validazione riuscita
+}
+
+// FetchCached scarica url passando dalla cache su disco con il TTL della sorgente.
+// Va chiamata fuori dal goroutine UI: può fare una richiesta HTTP bloccante.
+func FetchCached(url string) (CachedData, error) {
+	return fetchCachedTTL(url, CacheTTL(url))
+}
+
+// fetchCachedTTL: entro il TTL usa il disco, altrimenti fa una GET condizionale
+// (If-None-Match / If-Modified-Since). Se la rete fallisce e c'è una copia
+// scaduta, la restituisce come CacheStale insieme all'errore.
+func fetchCachedTTL(url string, ttl time.Duration) (CachedData, error) {
+	cached, e, have := httpDiskCache.lookup(url)
+	if have && time.Since(e.FetchedAt) < ttl {
+		return CachedData{Data: cached, State: CacheFresh, FetchedAt: e.FetchedAt}, nil
+	}
+
+	stale := func(err error) (CachedData, error) {
+		if have {
+			return CachedData{Data: cached, State: CacheStale, FetchedAt: e.FetchedAt}, err
+		}
+		return CachedData{State: CacheStale}, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return stale(err)
+	}
+	if have {
+		if e.ETag != "" {
+			req.Header.Set("If-None-Match", e.ETag)
+		}
+		if e.LastModified != "" {
+			req.Header.Set("If-Modified-Since", e.LastModified)
+		}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return stale(err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && have:
+		e.FetchedAt = time.Now()
+		httpDiskCache.store(e, nil)
+		return CachedData{Data: cached, State: CacheRevalidated, FetchedAt: e.FetchedAt}, nil
+
+	case resp.StatusCode != http.StatusOK:
+		return stale(fmt.Errorf("HTTP %d", resp.StatusCode))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return stale(err)
+	}
+	if len(data) == 0 {
+		return stale(errors.New("risposta vuota"))
+	}
+
+	now := time.Now()
+	httpDiskCache.store(diskEntry{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    now,
+	}, data)
+
+	return CachedData{Data: data, State: CacheDownloaded, FetchedAt: now}, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useTempDiskCache sostituisce la cache su disco globale con una in una
+// cartella temporanea, ripristinandola a fine test.
+func useTempDiskCache(t *testing.T, budget int64) *diskCache {
+	t.Helper()
+	c := &diskCache{budget: budget}
+	c.mu.Lock()
+	c.openLocked(t.TempDir())
+	c.mu.Unlock()
+
+	old := httpDiskCache
+	httpDiskCache = c
+	t.Cleanup(func() { httpDiskCache = old })
+	return c
+}
+
+func TestFetchCachedTTL(t *testing.T) {
+	useTempDiskCache(t, DefaultDiskCacheBudget)
+
+	var hits, conditional atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/etag":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				conditional.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+		case "/lastmod":
+			if r.Header.Get("If-Modified-Since") == "Mon, 06 May 2024 10:00:00 GMT" {
+				conditional.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Last-Modified", "Mon, 06 May 2024 10:00:00 GMT")
+		}
+		w.Write([]byte("dati " + r.URL.Path))
+	}))
+	defer srv.Close()
+
+	for _, path := range []string{"/etag", "/lastmod"} {
+		url := srv.URL + path
+		hits.Store(0)
+		conditional.Store(0)
+
+		got, err := fetchCachedTTL(url, time.Hour)
+		if err != nil || got.State != CacheDownloaded || string(got.Data) != "dati "+path {
+			t.Fatalf("%s primo download: %v, %q, %v", path, got.State, got.Data, err)
+		}
+
+		// entro il TTL: dal disco, senza rete
+		got, err = fetchCachedTTL(url, time.Hour)
+		if err != nil || got.State != CacheFresh || hits.Load() != 1 {
+			t.Errorf("%s entro il TTL: stato %v, richieste %d, %v", path, got.State, hits.Load(), err)
+		}
+
+		// scaduto: GET condizionale, il server risponde 304
+		got, err = fetchCachedTTL(url, 0)
+		if err != nil || got.State != CacheRevalidated || string(got.Data) != "dati "+path {
+			t.Errorf("%s rivalidato: stato %v, %q, %v", path, got.State, got.Data, err)
+		}
+		if conditional.Load() != 1 {
+			t.Errorf("%s: %d richieste condizionali, attesa 1", path, conditional.Load())
+		}
+	}
+
+	// rete giù: la copia scaduta torna come CacheStale insieme all'errore
+	url := srv.URL + "/etag"
+	srv.Close()
+	got, err := fetchCachedTTL(url, 0)
+	if err == nil || got.State != CacheStale || string(got.Data) != "dati /etag" {
+		t.Errorf("rete giù: stato %v, %q, errore %v", got.State, got.Data, err)
+	}
+}
+
+func TestDiskCacheEvictsLRU(t *testing.T) {
+	c := useTempDiskCache(t, 25)
+	data := []byte("0123456789")
+
+	c.store(diskEntry{URL: "a"}, data)
+	time.Sleep(2 * time.Millisecond)
+	c.store(diskEntry{URL: "b"}, data)
+	time.Sleep(2 * time.Millisecond)
+	if _, _, ok := c.lookup("a"); !ok { // "a" diventa la più recente
+		t.Fatal("a non trovata")
+	}
+	time.Sleep(2 * time.Millisecond)
+	c.store(diskEntry{URL: "c"}, data) // 30 byte > 25: esce "b"
+
+	for url, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, _, ok := c.lookup(url); ok != want {
+			t.Errorf("%s in cache = %v, atteso %v", url, ok, want)
+		}
+	}
+	if _, err := os.Stat(c.dataPath(cacheKey("b"))); !os.IsNotExist(err) {
+		t.Errorf("file di b ancora su disco: %v", err)
+	}
+}
+
+func TestDiskCacheOpenRemovesOrphans(t *testing.T) {
+	dir := t.TempDir()
+	c := &diskCache{budget: DefaultDiskCacheBudget}
+	c.mu.Lock()
+	c.openLocked(dir)
+	c.mu.Unlock()
+	c.store(diskEntry{URL: "valida"}, []byte("dati"))
+
+	key := cacheKey("valida")
+	orphans := []string{
+		key + ".bin.tmp12345", // writeFileAtomic interrotta
+		"deadbeef.bin",        // dati senza sidecar
+		"rotto.json",          // sidecar illeggibile
+	}
+	for _, name := range orphans {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c = &diskCache{budget: DefaultDiskCacheBudget}
+	c.mu.Lock()
+	c.openLocked(dir)
+	c.mu.Unlock()
+
+	for _, name := range orphans {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s non rimosso", name)
+		}
+	}
+	if data, _, ok := c.lookup("valida"); !ok || string(data) != "dati" {
+		t.Errorf("voce valida persa: %q, %v", data, ok)
+	}
+}
@@ -1,50 +1,226 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
 )
 
-// DSOCatalogURL è l'URL pubblico del catalogo DSO.
+// DSOCatalogURL è l'URL pubblico di default del catalogo DSO.
 const DSOCatalogURL = "https://raw.githubusercontent.com/cr4sh87/astro-lair-go/main/catalog/dso_catalog.json"
 
+// DSOCatalogURLEnv permette di puntare a un mirror senza toccare le preferenze.
+const DSOCatalogURLEnv = "ASTROLAIR_CATALOG_URL"
+
 // DSOCatalogLocalPath è il path locale dove viene salvato il catalogo.
 const DSOCatalogLocalPath = "catalog/dso_catalog.json"
 
-// UpdateDSOCatalogFromGitHub scarica l'ultima versione di dso_catalog.json dal repo GitHub
-// e la salva nella cartella locale "catalog", così buildTargetsCatalog() può leggerla.
-func UpdateDSOCatalogFromGitHub() {
-	log.Println("[DSO] Aggiorno dso_catalog.json da GitHub...")
+// DSOCatalogBackupPath conserva il catalogo precedente per il rollback.
+const DSOCatalogBackupPath = "catalog/dso_catalog.prev.json"
+
+// dsoCatalogMaxBytes limita la dimensione accettata per il download.
+const dsoCatalogMaxBytes = 64 << 20
+
+// dsoHTTPClient ha un timeout più lungo di httpClient: il catalogo è grande.
+var dsoHTTPClient = &http.Client{Timeout: 3 * time.Minute}
+
+// ResolveDSOCatalogURL sceglie l'URL del catalogo: valore configurato,
+// poi variabile d'ambiente ASTROLAIR_CATALOG_URL, infine DSOCatalogURL.
+func ResolveDSOCatalogURL(configured string) string {
+	if u := strings.TrimSpace(configured); u != "" {
+		return u
+	}
+	if u := strings.TrimSpace(os.Getenv(DSOCatalogURLEnv)); u != "" {
+		return u
+	}
+	return DSOCatalogURL
+}
+
+// CatalogUpdateResult descrive l'esito di UpdateDSOCatalog.
+type CatalogUpdateResult struct {
+	Updated bool
+	Remote  models.DsoCatalog // solo intestazione (Objects = nil)
+	Local   *models.DsoCatalog
+	Message string
+}
+
+// ValidateDsoCatalog verifica che un catalogo decodificato sia plausibile.
+func ValidateDsoCatalog(cat *models.DsoCatalog) error {
+	if cat.Version <= 0 {
+		return fmt.Errorf("versione non valida: %d", cat.Version)
+	}
+	if len(cat.Objects) == 0 {
+		return errors.New("nessun oggetto nel catalogo")
+	}
+	if cat.ObjectCount != 0 && cat.ObjectCount != len(cat.Objects) {
+		return fmt.Errorf("object_count=%d ma gli oggetti sono %d (file troncato?)", cat.ObjectCount, len(cat.Objects))
+	}
+	for i, o := range cat.Objects {
+		if strings.TrimSpace(o.ID) == "" {
+			return fmt.Errorf("oggetto #%d senza id", i)
+		}
+		if o.RADeg != nil && (*o.RADeg < 0 || *o.RADeg >= 360) {
+			return fmt.Errorf("oggetto %s: ra_deg fuori range (%g)", o.ID, *o.RADeg)
+		}
+		if o.DecDeg != nil && (*o.DecDeg < -90 || *o.DecDeg > 90) {
+			return fmt.Errorf("oggetto %s: dec_deg fuori range (%g)", o.ID, *o.DecDeg)
+		}
+	}
+	return nil
+}
+
+// DecodeDsoCatalog decodifica e valida un dso_catalog.json.
+func DecodeDsoCatalog(data []byte) (*models.DsoCatalog, error) {
+	var cat models.DsoCatalog
+	if err := json.Unmarshal(data, &cat); err != nil {
+		return nil, fmt.Errorf("JSON non valido: %w", err)
+	}
+	if err := ValidateDsoCatalog(&cat); err != nil {
+		return nil, err
+	}
+	return &cat, nil
+}
+
+// ReadLocalDsoCatalog legge e valida il catalogo locale.
+func ReadLocalDsoCatalog() (*models.DsoCatalog, error) {
+	data, err := os.ReadFile(DSOCatalogLocalPath)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeDsoCatalog(data)
+}
+
+// IsNewerCatalog indica se remote è più recente di local (Version, poi GeneratedAt).
+func IsNewerCatalog(remote, local *models.DsoCatalog) bool {
+	if local == nil {
+		return true
+	}
+	if remote.Version != local.Version {
+		return remote.Version > local.Version
+	}
+	rt, err1 := time.Parse(time.RFC3339, remote.GeneratedAt)
+	lt, err2 := time.Parse(time.RFC3339, local.GeneratedAt)
+	if err1 == nil && err2 == nil {
+		return rt.After(lt)
+	}
+	return remote.GeneratedAt > local.GeneratedAt
+}
+
+// progressReader notifica i byte letti durante il download.
+type progressReader struct {
+	r        io.Reader
+	done     int64
+	total    int64
+	progress func(done, total int64)
+}
 
-	resp, err := http.Get(DSOCatalogURL)
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	if p.progress != nil && n > 0 {
+		p.progress(p.done, p.total)
+	}
+	return n, err
+}
+
+// UpdateDSOCatalog scarica il catalogo da url, lo valida come models.DsoCatalog e,
+// se è più recente di quello locale, lo scrive in modo atomico conservando il
+// precedente in DSOCatalogBackupPath. progress (opzionale) riceve i byte scaricati
+// e il totale (-1 se sconosciuto). Va chiamata fuori dal goroutine UI.
+func UpdateDSOCatalog(url string, progress func(done, total int64)) (CatalogUpdateResult, error) {
+	var res CatalogUpdateResult
+	log.Printf("[DSO] Controllo aggiornamenti catalogo da %s...\n", url)
+
+	resp, err := dsoHTTPClient.Get(url)
 	if err != nil {
-		log.Printf("[DSO] Errore nel download: %v\n", err)
-		return
+		return res, fmt.Errorf("errore nel download: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("[DSO] HTTP status non valido: %s\n", resp.Status)
-		return
+		return res, fmt.Errorf("HTTP status non valido: %s", resp.Status)
+	}
+
+	pr := &progressReader{r: io.LimitReader(resp.Body, dsoCatalogMaxBytes+1), total: resp.ContentLength, progress: progress}
+	data, err := io.ReadAll(pr)
+	if err != nil {
+		return res, fmt.Errorf("errore lettura risposta: %w", err)
+	}
+	if len(data) > dsoCatalogMaxBytes {
+		return res, fmt.Errorf("catalogo oltre il limite di %d MB", dsoCatalogMaxBytes>>20)
+	}
+	if resp.ContentLength > 0 && int64(len(data)) != resp.ContentLength {
+		return res, fmt.Errorf("download troncato: %d di %d byte", len(data), resp.ContentLength)
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	remote, err := DecodeDsoCatalog(data)
 	if err != nil {
-		log.Printf("[DSO] Errore lettura risposta: %v\n", err)
-		return
+		return res, fmt.Errorf("catalogo remoto scartato: %w", err)
+	}
+	res.Remote = *remote
+	res.Remote.Objects = nil
+
+	local, localErr := ReadLocalDsoCatalog()
+	if localErr == nil {
+		res.Local = local
+		if !IsNewerCatalog(remote, local) {
+			res.Message = fmt.Sprintf("Catalogo già aggiornato (v%d, %s).", local.Version, local.GeneratedAt)
+			log.Printf("[DSO] %s\n", res.Message)
+			return res, nil
+		}
 	}
 
-	if err := os.MkdirAll("catalog", 0o755); err != nil {
-		log.Printf("[DSO] Impossibile creare cartella catalog/: %v\n", err)
-		return
+	if err := os.MkdirAll(filepath.Dir(DSOCatalogLocalPath), 0o755); err != nil {
+		return res, fmt.Errorf("impossibile creare cartella catalog/: %w", err)
 	}
 
-	if err := os.WriteFile(DSOCatalogLocalPath, data, 0o644); err != nil {
-		log.Printf("[DSO] Errore nel salvataggio di %s: %v\n", DSOCatalogLocalPath, err)
-		return
+	// il file attuale (solo se valido) diventa il punto di rollback
+	if localErr == nil {
+		if old, err := os.ReadFile(DSOCatalogLocalPath); err == nil {
+			if err := writeFileAtomic(DSOCatalogBackupPath, old); err != nil {
+				return res, fmt.Errorf("impossibile salvare il backup: %w", err)
+			}
+		}
 	}
 
-	log.Printf("[DSO] Catalogo aggiornato (%d byte) → %s\n", len(data), DSOCatalogLocalPath)
+	if err := writeFileAtomic(DSOCatalogLocalPath, data); err != nil {
+		return res, fmt.Errorf("errore nel salvataggio di %s: %w", DSOCatalogLocalPath, err)
+	}
+
+	res.Updated = true
+	res.Message = fmt.Sprintf("Catalogo aggiornato a v%d (%s, %d oggetti).", remote.Version, remote.GeneratedAt, len(remote.Objects))
+	log.Printf("[DSO] %s → %s\n", res.Message, DSOCatalogLocalPath)
+	return res, nil
+}
+
+// RollbackDSOCatalog ripristina il catalogo precedente, scambiandolo con quello attuale.
+func RollbackDSOCatalog() error {
+	prev, err := os.ReadFile(DSOCatalogBackupPath)
+	if err != nil {
+		return fmt.Errorf("nessun catalogo precedente: %w", err)
+	}
+	if _, err := DecodeDsoCatalog(prev); err != nil {
+		return fmt.Errorf("catalogo precedente non valido: %w", err)
+	}
+
+	cur, curErr := os.ReadFile(DSOCatalogLocalPath)
+	if err := writeFileAtomic(DSOCatalogLocalPath, prev); err != nil {
+		return err
+	}
+	if curErr == nil {
+		if err := writeFileAtomic(DSOCatalogBackupPath, cur); err != nil {
+			return err
+		}
+	}
+	log.Printf("[DSO] Ripristinato il catalogo precedente (%s)\n", DSOCatalogBackupPath)
+	return nil
 }
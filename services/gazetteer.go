@@ -0,0 +1,368 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Gazetteer offline (GeoNames)
+// =======================
+//
+// Le località sono incorporate nel binario come TSV compresso con gzip
+// (assets/gazetteer.tsv.gz), generato da cmd/gazgen a partire da
+// cities1000.txt di GeoNames. Colonne:
+//
+//	nome, nome ASCII, nomi alternativi (separati da "|"), paese,
+//	lat, lon, quota (m), fuso IANA, popolazione
+//
+// Le righe sono ordinate per popolazione decrescente.
+//
+// Il file nel repository contiene 125 righe di cities1000.txt scelte a mano
+// (capoluoghi italiani, paesi dell'Etna, grandi città e località di
+// osservatori), non l'uscita completa di cmd/gazgen; il dataset completo si
+// ottiene con "go run ./cmd/gazgen -in cities1000.txt".
+
+//go:embed assets/gazetteer.tsv.gz
+var gazetteerData []byte
+
+// gazetteerHeader è la prima riga del TSV.
+const gazetteerHeader = "# name\tascii\talternates\tcountry\tlat\tlon\televation\ttimezone\tpopulation"
+
+// gazetteerEntry è una località con i nomi già normalizzati per la ricerca.
+type gazetteerEntry struct {
+	place models.Place
+	keys  []string // nome, nome ASCII e alternativi normalizzati
+}
+
+var (
+	gazetteerOnce    sync.Once
+	gazetteerEntries []gazetteerEntry
+)
+
+// loadGazetteer decodifica (una volta sola) il gazetteer incorporato.
+func loadGazetteer() []gazetteerEntry {
+	gazetteerOnce.Do(func() {
+		places, err := ReadGazetteer(bytes.NewReader(gazetteerData))
+		if err != nil {
+			log.Printf("[Gazetteer] impossibile leggere il gazetteer incorporato: %v", err)
+			return
+		}
+		gazetteerEntries = make([]gazetteerEntry, len(places))
+		for i, p := range places {
+			keys := []string{foldName(p.Name)}
+			for _, n := range append([]string{p.ASCIIName}, p.Alternates...) {
+				if k := foldName(n); k != "" && !containsString(keys, k) {
+					keys = append(keys, k)
+				}
+			}
+			gazetteerEntries[i] = gazetteerEntry{place: p, keys: keys}
+		}
+	})
+	return gazetteerEntries
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// GazetteerSize restituisce il numero di località disponibili offline.
+func GazetteerSize() int {
+	return len(loadGazetteer())
+}
+
+// SearchPlaces cerca le località il cui nome (o un nome alternativo) inizia
+// con query, senza distinguere maiuscole e accenti. "nome, CC" limita la
+// ricerca al paese CC. Prima i nomi identici, poi i prefissi del nome, poi
+// quelli di una parola interna; a parità, le località più popolose.
+func SearchPlaces(query string, limit int) []models.Place {
+	name, country, _ := strings.Cut(query, ",")
+	q := foldName(name)
+	country = strings.TrimSpace(country)
+	if q == "" {
+		return nil
+	}
+
+	type hit struct {
+		rank int
+		idx  int
+	}
+	var hits []hit
+	entries := loadGazetteer()
+	for i, e := range entries {
+		if country != "" && !strings.EqualFold(e.place.Country, country) {
+			continue
+		}
+		rank := -1
+		for _, k := range e.keys {
+			r := -1
+			switch {
+			case k == q:
+				r = 0
+			case strings.HasPrefix(k, q):
+				r = 1
+			case strings.Contains(k, " "+q):
+				r = 2
+			}
+			if r >= 0 && (rank < 0 || r < rank) {
+				rank = r
+			}
+		}
+		if rank >= 0 {
+			hits = append(hits, hit{rank, i})
+		}
+	}
+
+	// le righe sono già per popolazione decrescente: basta un ordinamento stabile
+	sort.SliceStable(hits, func(a, b int) bool { return hits[a].rank < hits[b].rank })
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	out := make([]models.Place, len(hits))
+	for i, h := range hits {
+		out[i] = entries[h.idx].place
+	}
+	return out
+}
+
+// foldName porta un nome in minuscolo, senza accenti né apostrofi, con gli
+// spazi compattati ("L'Aquila" → "l aquila", "Zürich" → "zurich").
+func foldName(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if f, ok := foldRunes[r]; ok {
+			sb.WriteString(f)
+			space = false
+			continue
+		}
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+			space = false
+		case !space && sb.Len() > 0:
+			sb.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// foldRunes sono le lettere accentate (alfabeto latino) e le loro basi.
+var foldRunes = func() map[rune]string {
+	groups := map[string]string{
+		"a":  "àáâãäåāăą",
+		"ae": "æ",
+		"c":  "çćĉċč",
+		"d":  "ďđ",
+		"e":  "èéêëēĕėęě",
+		"g":  "ĝğġģ",
+		"h":  "ĥħ",
+		"i":  "ìíîïĩīĭįı",
+		"j":  "ĵ",
+		"k":  "ķ",
+		"l":  "ĺļľŀł",
+		"n":  "ñńņňŉ",
+		"o":  "òóôõöøōŏő",
+		"oe": "œ",
+		"r":  "ŕŗř",
+		"s":  "śŝşšș",
+		"ss": "ß",
+		"t":  "ţťŧț",
+		"th": "þ",
+		"u":  "ùúûüũūŭůűų",
+		"w":  "ŵ",
+		"y":  "ýÿŷ",
+		"z":  "źżž",
+	}
+	m := map[rune]string{}
+	for base, runes := range groups {
+		for _, r := range runes {
+			m[r] = base
+		}
+	}
+	return m
+}()
+
+// =======================
+//  Lettura / scrittura
+// =======================
+
+// ReadGazetteer legge il TSV compresso prodotto da WriteGazetteer.
+func ReadGazetteer(r io.Reader) ([]models.Place, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var out []models.Place
+	sc := bufio.NewScanner(zr)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := sc.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		f := strings.Split(text, "\t")
+		if len(f) != 9 {
+			return nil, fmt.Errorf("riga %d: %d colonne invece di 9", line, len(f))
+		}
+		p := models.Place{Name: f[0], ASCIIName: f[1], Country: f[3], Timezone: f[7]}
+		if f[2] != "" {
+			p.Alternates = strings.Split(f[2], "|")
+		}
+		var errs [4]error
+		p.Lat, errs[0] = strconv.ParseFloat(f[4], 64)
+		p.Lon, errs[1] = strconv.ParseFloat(f[5], 64)
+		p.ElevationM, errs[2] = strconv.ParseFloat(f[6], 64)
+		p.Population, errs[3] = strconv.Atoi(f[8])
+		if err := errors.Join(errs[:]...); err != nil {
+			return nil, fmt.Errorf("riga %d: %w", line, err)
+		}
+		out = append(out, p)
+	}
+	return out, sc.Err()
+}
+
+// WriteGazetteer scrive le località come TSV compresso, per popolazione decrescente.
+func WriteGazetteer(w io.Writer, places []models.Place) error {
+	sorted := append([]models.Place(nil), places...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Population > sorted[j].Population })
+
+	zw, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(zw)
+	fmt.Fprintln(bw, gazetteerHeader)
+	for _, p := range sorted {
+		ascii := p.ASCIIName
+		if ascii == p.Name {
+			ascii = ""
+		}
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%.5f\t%.5f\t%.0f\t%s\t%d\n",
+			p.Name, ascii, strings.Join(p.Alternates, "|"), p.Country,
+			p.Lat, p.Lon, p.ElevationM, p.Timezone, p.Population)
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// GeoNamesOptions sono i filtri applicati da ReadGeoNamesCities.
+type GeoNamesOptions struct {
+	MinPopulation int // scarta le località meno popolose
+	// AltMinPopulation: i nomi alternativi (esonimi, es. "Roma" per "Rome")
+	// si tengono solo per le località almeno così popolose, per contenere
+	// le dimensioni; 0 li scarta tutti.
+	AltMinPopulation int
+}
+
+// ReadGeoNamesCities legge un dump "cities*.txt" di GeoNames (19 colonne
+// separate da tab, senza intestazione).
+func ReadGeoNamesCities(r io.Reader, opt GeoNamesOptions) ([]models.Place, error) {
+	var out []models.Place
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 1<<20), 1<<24) // alcune righe di alternatenames sono lunghissime
+	line := 0
+	for sc.Scan() {
+		line++
+		f := strings.Split(sc.Text(), "\t")
+		if len(f) < 19 {
+			return nil, fmt.Errorf("riga %d: %d colonne invece di 19", line, len(f))
+		}
+		pop, _ := strconv.Atoi(f[14])
+		if pop < opt.MinPopulation {
+			continue
+		}
+		lat, err1 := strconv.ParseFloat(f[4], 64)
+		lon, err2 := strconv.ParseFloat(f[5], 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("riga %d: coordinate non valide", line)
+		}
+
+		// quota dichiarata, altrimenti il modello del terreno (-9999 = assente)
+		elev, err := strconv.ParseFloat(f[15], 64)
+		if err != nil {
+			elev, err = strconv.ParseFloat(f[16], 64)
+			if err != nil || elev < -1000 {
+				elev = 0
+			}
+		}
+
+		p := models.Place{
+			Name:       f[1],
+			ASCIIName:  f[2],
+			Country:    f[8],
+			Lat:        lat,
+			Lon:        lon,
+			ElevationM: elev,
+			Timezone:   f[17],
+			Population: pop,
+		}
+		if opt.AltMinPopulation > 0 && pop >= opt.AltMinPopulation {
+			p.Alternates = usefulAlternates(p, f[3])
+		}
+		out = append(out, p)
+	}
+	return out, sc.Err()
+}
+
+// usefulAlternates tiene i nomi alternativi in alfabeto latino che non
+// coincidono (a meno di accenti) con il nome principale: codici aeroportuali,
+// URL e scritture non latine non servono alla ricerca.
+func usefulAlternates(p models.Place, list string) []string {
+	seen := []string{foldName(p.Name), foldName(p.ASCIIName)}
+	var out []string
+	for _, alt := range strings.Split(list, ",") {
+		alt = strings.TrimSpace(alt)
+		if alt == "" || len(alt) > 40 || strings.ContainsAny(alt, "|\t/.") || !isLatinName(alt) {
+			continue
+		}
+		// i codici (IATA, ICAO) sono tutti maiuscoli e corti
+		if len(alt) <= 4 && strings.ToUpper(alt) == alt {
+			continue
+		}
+		k := foldName(alt)
+		if k == "" || containsString(seen, k) {
+			continue
+		}
+		seen = append(seen, k)
+		out = append(out, alt)
+	}
+	return out
+}
+
+// isLatinName indica se s usa solo lettere latine, spazi e punteggiatura dei nomi.
+func isLatinName(s string) bool {
+	for _, r := range s {
+		switch {
+		case r == ' ' || r == '-' || r == '\'' || r == '’':
+		case unicode.Is(unicode.Latin, r):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchPlacesCountry(t *testing.T) {
+	tests := []struct {
+		query string
+		found bool
+	}{
+		{"Catania, IT", true},
+		{"Catania, it", true},
+		{"Catania", true},
+		{"Catania, I", false}, // il codice paese va scritto per intero
+		{"Catania, FR", false},
+	}
+	for _, tt := range tests {
+		got := SearchPlaces(tt.query, 5)
+		if !tt.found && len(got) != 0 {
+			t.Errorf("%q: %d risultati, attesi nessuno", tt.query, len(got))
+		}
+		if tt.found && (len(got) == 0 || got[0].Name != "Catania") {
+			t.Errorf("%q: %v, attesa Catania", tt.query, got)
+		}
+	}
+}
+
+// geoNamesRow compone una riga di cities*.txt con le colonne usate dal lettore.
+func geoNamesRow(name, ascii, alternates, lat, lon, country, pop, elev, dem, tz string) string {
+	f := make([]string, 19)
+	f[0], f[1], f[2], f[3], f[4], f[5] = "1", name, ascii, alternates, lat, lon
+	f[6], f[7], f[8] = "P", "PPL", country
+	f[14], f[15], f[16], f[17], f[18] = pop, elev, dem, tz, "2024-01-01"
+	return strings.Join(f, "\t")
+}
+
+func TestGazetteerRoundTrip(t *testing.T) {
+	dump := strings.Join([]string{
+		geoNamesRow("Catania", "Catania", "CTA,Catane,LICC,Katania,Катания,https://it.wikipedia.org/wiki/Catania,Catània",
+			"37.49223", "15.07041", "IT", "290927", "", "7", "Europe/Rome"),
+		geoNamesRow("Nicolosi", "Nicolosi", "Nicolosi Etnea", "37.61472", "15.02278", "IT", "7000", "698", "700", "Europe/Rome"),
+		geoNamesRow("Borgo", "Borgo", "", "37.5", "15.0", "IT", "500", "", "", "Europe/Rome"),
+		geoNamesRow("Rome", "Rome", "ROM,Roma,Rom", "41.89193", "12.51133", "IT", "2318895", "", "-9999", "Europe/Rome"),
+	}, "\n")
+
+	places, err := ReadGeoNamesCities(strings.NewReader(dump), GeoNamesOptions{MinPopulation: 1000, AltMinPopulation: 100000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteGazetteer(&buf, places); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadGazetteer(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// per popolazione decrescente, senza la località sotto la soglia
+	want := []struct {
+		name       string
+		elev       float64
+		alternates []string
+	}{
+		{"Rome", 0, []string{"Roma", "Rom"}},          // codice "ROM" scartato, DEM -9999 = assente
+		{"Catania", 7, []string{"Catane", "Katania"}}, // quota dal DEM; codici, URL e cirillico scartati
+		{"Nicolosi", 698, nil},                        // troppo piccola per i nomi alternativi
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d località, attese %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		p := got[i]
+		if p.Name != w.name || p.ElevationM != w.elev || !reflect.DeepEqual(p.Alternates, w.alternates) {
+			t.Errorf("riga %d = %s, quota %.0f, alternativi %q; attesa %s, quota %.0f, alternativi %q",
+				i, p.Name, p.ElevationM, p.Alternates, w.name, w.elev, w.alternates)
+		}
+	}
+	if c := got[1]; c.Lat != 37.49223 || c.Lon != 15.07041 || c.Country != "IT" || c.Timezone != "Europe/Rome" || c.Population != 290927 {
+		t.Errorf("Catania riletta male: %+v", c)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  GOES XRS: flusso X e brillamenti (NOAA SWPC)
+// =======================
+
+// Feed JSON del GOES primario: flusso X al minuto (1 o 7 giorni) e lista dei
+// brillamenti della settimana.
+const (
+	xray1DayURL   = "https://services.swpc.noaa.gov/json/goes/primary/xrays-1-day.json"
+	xray7DayURL   = "https://services.swpc.noaa.gov/json/goes/primary/xrays-7-day.json"
+	xrayFlaresURL = "https://services.swpc.noaa.gov/json/goes/primary/xray-flares-7-day.json"
+)
+
+func init() {
+	SetCacheTTL(xray1DayURL, 5*time.Minute)
+	SetCacheTTL(xray7DayURL, 30*time.Minute)
+	SetCacheTTL(xrayFlaresURL, 10*time.Minute)
+}
+
+// Bande energetiche nei feed XRS
+const (
+	xrayLongBand  = "0.1-0.8nm"
+	xrayShortBand = "0.05-0.4nm"
+)
+
+// xrayClasses sono le soglie delle classi (flusso 0.1-0.8 nm in W/m²)
+var xrayClasses = []struct {
+	letter string
+	min    float64
+}{
+	{"X", 1e-4},
+	{"M", 1e-5},
+	{"C", 1e-6},
+	{"B", 1e-7},
+	{"A", 1e-8},
+}
+
+// FetchXRayFlux scarica il flusso X del GOES primario per 1 o 7 giorni.
+// Va chiamata fuori dal goroutine UI.
+func FetchXRayFlux(days int) ([]models.XRayFlux, error) {
+	url := xray1DayURL
+	if days > 1 {
+		url = xray7DayURL
+	}
+	cd, err := FetchCached(url)
+	if err != nil {
+		return nil, err
+	}
+	return parseXRayFlux(cd.Data)
+}
+
+// parseXRayFlux unisce le due bande (una riga per banda e minuto) in punti
+// ordinati nel tempo.
+func parseXRayFlux(data []byte) ([]models.XRayFlux, error) {
+	records, err := parseSWPCRecords(data)
+	if err != nil {
+		return nil, fmt.Errorf("formato GOES XRS non riconosciuto: %w", err)
+	}
+
+	byTime := map[time.Time]*models.XRayFlux{}
+	for _, rec := range records {
+		t, ok := swpcTime(rec["time_tag"])
+		if !ok {
+			continue
+		}
+		flux, ok := swpcFloat(rec["flux"])
+		if !ok || flux <= 0 {
+			flux = math.NaN() // dato mancante o non fisico (scala logaritmica)
+		}
+		band, _ := rec["energy"].(string)
+		band = strings.ReplaceAll(band, " ", "")
+
+		p := byTime[t]
+		if p == nil {
+			p = &models.XRayFlux{Time: t, Long: math.NaN(), Short: math.NaN()}
+			byTime[t] = p
+		}
+		switch band {
+		case xrayLongBand:
+			p.Long = flux
+		case xrayShortBand:
+			p.Short = flux
+		}
+	}
+	if len(records) > 0 && len(byTime) == 0 {
+		return nil, errors.New("nessun valore di flusso X leggibile")
+	}
+
+	out := make([]models.XRayFlux, 0, len(byTime))
+	for _, p := range byTime {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out, nil
+}
+
+// FetchSolarFlares scarica i brillamenti degli ultimi 7 giorni, dal più recente.
+// Va chiamata fuori dal goroutine UI.
+func FetchSolarFlares() ([]models.SolarFlare, error) {
+	cd, err := FetchCached(xrayFlaresURL)
+	if err != nil {
+		return nil, err
+	}
+	return parseSolarFlares(cd.Data)
+}
+
+func parseSolarFlares(data []byte) ([]models.SolarFlare, error) {
+	records, err := parseSWPCRecords(data)
+	if err != nil {
+		return nil, fmt.Errorf("formato brillamenti GOES non riconosciuto: %w", err)
+	}
+
+	var out []models.SolarFlare
+	for _, rec := range records {
+		peak, ok := swpcTime(rec["max_time"])
+		if !ok {
+			continue
+		}
+		f := models.SolarFlare{Peak: peak, PeakFlux: math.NaN()}
+		f.Begin, _ = swpcTime(rec["begin_time"])
+		if end, ok := swpcTime(rec["end_time"]); ok {
+			f.End = &end
+		}
+		if v, ok := swpcFloat(rec["max_xrlong"]); ok && v > 0 {
+			f.PeakFlux = v
+		}
+		f.Class, _ = rec["max_class"].(string)
+		f.Class = strings.TrimSpace(f.Class)
+		if f.Class == "" && !math.IsNaN(f.PeakFlux) {
+			f.Class = XRayClass(f.PeakFlux)
+		}
+		if math.IsNaN(f.PeakFlux) {
+			f.PeakFlux = ParseXRayClass(f.Class)
+		}
+		if sat, ok := swpcFloat(rec["satellite"]); ok {
+			f.Satellite = int(sat)
+		}
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Peak.After(out[j].Peak) })
+	return out, nil
+}
+
+// XRayClass converte un flusso 0.1-0.8 nm nella classe (es. 3.2e-5 → "M3.2").
+// Il valore è arrotondato prima di scegliere la lettera, così 9.96e-6 diventa
+// "M1.0" e non "C10.0". Sotto la classe A restituisce "<A".
+func XRayClass(flux float64) string {
+	if math.IsNaN(flux) {
+		return "—"
+	}
+	for i, c := range xrayClasses {
+		if flux < c.min {
+			continue
+		}
+		v := math.Round(flux/c.min*10) / 10
+		if v >= 10 && i > 0 {
+			return xrayClasses[i-1].letter + "1.0"
+		}
+		return fmt.Sprintf("%s%.1f", c.letter, v)
+	}
+	return "<A"
+}
+
+// ParseXRayClass converte una classe (es. "M2.4") nel flusso in W/m²; NaN se
+// la classe non è riconosciuta.
+func ParseXRayClass(class string) float64 {
+	class = strings.ToUpper(strings.TrimSpace(class))
+	if class == "" {
+		return math.NaN()
+	}
+	for _, c := range xrayClasses {
+		if class[:1] != c.letter {
+			continue
+		}
+		mult := 1.0
+		if rest := class[1:]; rest != "" {
+			v, err := strconv.ParseFloat(rest, 64)
+			if err != nil {
+				return math.NaN()
+			}
+			mult = v
+		}
+		return mult * c.min
+	}
+	return math.NaN()
+}
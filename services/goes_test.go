@@ -0,0 +1,28 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestXRayClass(t *testing.T) {
+	tests := []struct {
+		flux float64
+		want string
+	}{
+		{3.2e-5, "M3.2"},
+		{1.5e-4, "X1.5"},
+		{2.3e-3, "X23.0"},
+		{9.94e-6, "C9.9"},
+		{9.96e-6, "M1.0"}, // arrotondato prima della scelta della lettera
+		{9.99e-5, "X1.0"},
+		{1e-6, "C1.0"},
+		{5e-9, "<A"},
+		{math.NaN(), "—"},
+	}
+	for _, tt := range tests {
+		if got := XRayClass(tt.flux); got != tt.want {
+			t.Errorf("XRayClass(%g) = %q, atteso %q", tt.flux, got, tt.want)
+		}
+	}
+}
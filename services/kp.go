@@ -0,0 +1,267 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Indice Kp (NOAA SWPC)
+// =======================
+
+// Feed SWPC: serie Kp a 3 ore osservate (ultimi giorni), previsione a 3 giorni
+// (osservati + stimati + previsti) e stima al minuto più recente.
+const (
+	kpURL         = "https://services.swpc.noaa.gov/products/noaa-planetary-k-index.json"
+	kpForecastURL = "https://services.swpc.noaa.gov/products/noaa-planetary-k-index-forecast.json"
+	kp1mURL       = "https://services.swpc.noaa.gov/json/planetary_k_index_1m.json"
+)
+
+// kpInterval è la durata di un intervallo Kp
+const kpInterval = 3 * time.Hour
+
+func init() {
+	SetCacheTTL(kpURL, 15*time.Minute)
+	SetCacheTTL(kpForecastURL, 30*time.Minute)
+	SetCacheTTL(kp1mURL, 5*time.Minute)
+}
+
+// FetchLatestKp scarica l'ultimo indice Kp pubblicato.
+// Va chiamata fuori dal goroutine UI.
+func FetchLatestKp() (models.KpReading, error) {
+	cd, err := FetchCached(kpURL)
+	if err != nil {
+		return models.KpReading{}, err
+	}
+	series, err := parseKpSeries(cd.Data, models.KpObserved, "Kp", "kp", "kp_index")
+	if err != nil {
+		return models.KpReading{}, err
+	}
+	if len(series) == 0 {
+		return models.KpReading{}, errors.New("serie Kp vuota")
+	}
+	return series[len(series)-1], nil
+}
+
+// FetchKpOutlook scarica Kp attuale, ultime 24 ore e previsione a 3 giorni.
+// I feed che non rispondono vengono saltati; l'errore arriva solo se non
+// resta nulla da mostrare. Va chiamata fuori dal goroutine UI.
+func FetchKpOutlook(now time.Time) (models.KpOutlook, error) {
+	var out models.KpOutlook
+	var errs []error
+	since := now.Add(-24 * time.Hour)
+
+	var observed []models.KpReading
+	if cd, err := FetchCached(kpURL); err != nil {
+		errs = append(errs, err)
+	} else if observed, err = parseKpSeries(cd.Data, models.KpObserved, "Kp", "kp", "kp_index"); err != nil {
+		errs = append(errs, err)
+	}
+
+	var forecast []models.KpReading
+	if cd, err := FetchCached(kpForecastURL); err != nil {
+		errs = append(errs, err)
+	} else if forecast, err = parseKpSeries(cd.Data, models.KpPredicted, "kp", "Kp"); err != nil {
+		errs = append(errs, err)
+	}
+
+	// se manca la serie osservata si usano le righe "observed" della previsione
+	if len(observed) == 0 {
+		for _, r := range forecast {
+			if r.Status == models.KpObserved {
+				observed = append(observed, r)
+			}
+		}
+	}
+	for _, r := range observed {
+		if r.Time.Add(kpInterval).After(since) && !r.Time.After(now) {
+			out.History = append(out.History, r)
+		}
+	}
+	var lastObserved time.Time
+	if len(out.History) > 0 {
+		lastObserved = out.History[len(out.History)-1].Time
+	}
+	for _, r := range forecast {
+		if r.Status != models.KpObserved && r.Time.After(lastObserved) && r.Time.Add(kpInterval).After(now) {
+			out.Forecast = append(out.Forecast, r)
+		}
+	}
+
+	if cd, err := FetchCached(kp1mURL); err != nil {
+		errs = append(errs, err)
+	} else if recent, err := parseKpSeries(cd.Data, models.KpEstimated, "estimated_kp", "kp_index"); err != nil {
+		errs = append(errs, err)
+	} else if len(recent) > 0 {
+		out.Current = &recent[len(recent)-1]
+	}
+	if out.Current == nil && len(out.History) > 0 {
+		out.Current = &out.History[len(out.History)-1]
+	}
+
+	if out.Current == nil && len(out.Forecast) == 0 {
+		if err := errors.Join(errs...); err != nil {
+			return out, err
+		}
+		return out, errors.New("nessun dato Kp disponibile")
+	}
+	return out, nil
+}
+
+// parseKpSeries legge un feed Kp SWPC in uno dei due formati pubblicati:
+// tabella (array di array, la prima riga sono le intestazioni) o array di
+// oggetti. Il valore è il primo campo numerico fra kpKeys; lo stato viene
+// dalla colonna "observed" se presente, altrimenti è status.
+func parseKpSeries(data []byte, status string, kpKeys ...string) ([]models.KpReading, error) {
+	records, err := parseSWPCRecords(data)
+	if err != nil {
+		return nil, fmt.Errorf("formato Kp non riconosciuto: %w", err)
+	}
+
+	var out []models.KpReading
+	for _, rec := range records {
+		t, ok := swpcTime(rec["time_tag"])
+		if !ok {
+			continue
+		}
+		var kp float64
+		for _, k := range kpKeys {
+			if kp, ok = swpcFloat(rec[k]); ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		r := models.KpReading{Time: t, Kp: kp, Status: status}
+		if s, ok := rec["observed"].(string); ok && s != "" {
+			r.Status = s
+		}
+		out = append(out, r)
+	}
+	if len(records) > 0 && len(out) == 0 {
+		return nil, errors.New("nessun valore Kp leggibile")
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out, nil
+}
+
+// parseSWPCRecords converte un feed JSON SWPC in una lista di record
+// campo → valore, sia dal formato tabella sia da quello a oggetti.
+func parseSWPCRecords(data []byte) ([]map[string]any, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	if b := bytes.TrimSpace(raw[0]); len(b) == 0 || b[0] != '[' {
+		out := make([]map[string]any, 0, len(raw))
+		for _, r := range raw {
+			var rec map[string]any
+			if err := json.Unmarshal(r, &rec); err != nil {
+				return nil, err
+			}
+			out = append(out, rec)
+		}
+		return out, nil
+	}
+
+	var header []string
+	if err := json.Unmarshal(raw[0], &header); err != nil {
+		return nil, fmt.Errorf("intestazioni non valide: %w", err)
+	}
+	out := make([]map[string]any, 0, len(raw)-1)
+	for _, r := range raw[1:] {
+		var row []any
+		if err := json.Unmarshal(r, &row); err != nil {
+			return nil, err
+		}
+		rec := make(map[string]any, len(header))
+		for i, h := range header {
+			if i < len(row) {
+				rec[h] = row[i]
+			}
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+// swpcFloat accetta numeri JSON e numeri scritti come stringa.
+func swpcFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// swpcTimeLayouts sono i formati di time_tag usati dai feed SWPC (sempre UTC)
+var swpcTimeLayouts = []string{
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04",
+}
+
+func swpcTime(v any) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, layout := range swpcTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// MaxKp restituisce la lettura col Kp più alto (ok false se la lista è vuota).
+func MaxKp(readings []models.KpReading) (models.KpReading, bool) {
+	if len(readings) == 0 {
+		return models.KpReading{}, false
+	}
+	best := readings[0]
+	for _, r := range readings[1:] {
+		if r.Kp > best.Kp {
+			best = r
+		}
+	}
+	return best, true
+}
+
+// KpLabel descrive il livello di attività geomagnetica.
+func KpLabel(kp float64) string {
+	switch {
+	case kp >= 9:
+		return "tempesta estrema (G5)"
+	case kp >= 8:
+		return "tempesta severa (G4)"
+	case kp >= 7:
+		return "tempesta forte (G3)"
+	case kp >= 6:
+		return "tempesta moderata (G2)"
+	case kp >= 5:
+		return "tempesta minore (G1)"
+	case kp >= 4:
+		return "attivo"
+	default:
+		return "quieto"
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// Feed Kp nei due formati SWPC: tabella con intestazione e numeri come
+// stringa (serie osservata), array di oggetti con colonna "observed"
+// (previsione a 3 giorni).
+const (
+	kpTableFixture = `[
+		["time_tag","Kp","a_running","station_count"],
+		["2026-10-16 09:00:00.000","1.67","6","8"],
+		["2026-10-17 06:00:00.000","2.33","9","8"],
+		["2026-10-17 09:00:00.000","3.00","15","8"]
+	]`
+	kpForecastFixture = `[
+		{"time_tag":"2026-10-17T06:00:00","kp":2.33,"observed":"observed","noaa_scale":null},
+		{"time_tag":"2026-10-17T09:00:00","kp":3.33,"observed":"estimated","noaa_scale":null},
+		{"time_tag":"2026-10-17T12:00:00","kp":"4.00","observed":"estimated","noaa_scale":null},
+		{"time_tag":"2026-10-17T15:00:00","kp":5.67,"observed":"predicted","noaa_scale":"G1"},
+		{"time_tag":"2026-10-17T18:00:00","kp":null,"observed":"predicted","noaa_scale":null}
+	]`
+)
+
+func TestParseKpSeries(t *testing.T) {
+	table, err := parseKpSeries([]byte(kpTableFixture), models.KpObserved, "Kp", "kp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table) != 3 || table[2].Kp != 3 || table[2].Status != models.KpObserved ||
+		!table[2].Time.Equal(time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("tabella: %+v", table)
+	}
+
+	objects, err := parseKpSeries([]byte(kpForecastFixture), models.KpPredicted, "kp", "Kp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// la riga con kp null è scartata, lo stato viene dalla colonna "observed"
+	wantStatus := []string{models.KpObserved, models.KpEstimated, models.KpEstimated, models.KpPredicted}
+	if len(objects) != len(wantStatus) {
+		t.Fatalf("oggetti: %d righe, attese %d", len(objects), len(wantStatus))
+	}
+	for i, s := range wantStatus {
+		if objects[i].Status != s {
+			t.Errorf("riga %d: stato %q, atteso %q", i, objects[i].Status, s)
+		}
+	}
+	if objects[2].Kp != 4 {
+		t.Errorf("Kp scritto come stringa: %v, atteso 4", objects[2].Kp)
+	}
+
+	if _, err := parseKpSeries([]byte(`{"kp":1}`), models.KpObserved, "kp"); err == nil {
+		t.Error("oggetto singolo accettato come serie")
+	}
+	if _, err := parseKpSeries([]byte(`[["time_tag","Kp"],["2026-10-17 09:00:00.000",null]]`), models.KpObserved, "Kp"); err == nil {
+		t.Error("serie senza valori leggibili accettata")
+	}
+}
+
+func TestFetchKpOutlook(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 30, 0, 0, time.UTC)
+	seed := func(c *diskCache, feeds map[string]string) {
+		for url, body := range feeds {
+			c.store(diskEntry{URL: url, FetchedAt: time.Now()}, []byte(body))
+		}
+	}
+
+	t.Run("serie osservata e previsione", func(t *testing.T) {
+		seed(useTempDiskCache(t, DefaultDiskCacheBudget), map[string]string{
+			kpURL: kpTableFixture, kpForecastURL: kpForecastFixture, kp1mURL: `[]`,
+		})
+		o, err := FetchKpOutlook(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// ultime 24 ore: l'intervallo del 16 alle 09 è finito alle 12, fuori finestra
+		if len(o.History) != 2 || o.History[1].Kp != 3 {
+			t.Errorf("storico %+v, attesi gli intervalli delle 06 e 09", o.History)
+		}
+		// la stima delle 09 è già coperta dall'osservato; restano 12 (stimato) e 15 (previsto)
+		if len(o.Forecast) != 2 || o.Forecast[0].Status != models.KpEstimated || o.Forecast[0].Kp != 4 ||
+			o.Forecast[1].Status != models.KpPredicted {
+			t.Errorf("previsione %+v, attesi stimato 12:00 e previsto 15:00", o.Forecast)
+		}
+		if o.Current == nil || o.Current.Kp != 3 {
+			t.Errorf("Kp attuale %+v, atteso l'ultimo osservato (3)", o.Current)
+		}
+	})
+
+	t.Run("senza serie osservata", func(t *testing.T) {
+		seed(useTempDiskCache(t, DefaultDiskCacheBudget), map[string]string{
+			kpURL: `[]`, kpForecastURL: kpForecastFixture, kp1mURL: `[{"time_tag":"2026-10-17T12:29:00","kp_index":3,"estimated_kp":3.33}]`,
+		})
+		o, err := FetchKpOutlook(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// lo storico viene dalle righe "observed" della previsione
+		if len(o.History) != 1 || o.History[0].Kp != 2.33 {
+			t.Errorf("storico %+v, atteso l'osservato delle 06", o.History)
+		}
+		// la stima delle 09 segue l'ultimo osservato ma il suo intervallo è già finito
+		if len(o.Forecast) != 2 || o.Forecast[0].Kp != 4 {
+			t.Errorf("previsione %+v, attese le righe delle 12 e 15", o.Forecast)
+		}
+		if o.Current == nil || o.Current.Kp != 3.33 || o.Current.Status != models.KpEstimated {
+			t.Errorf("Kp attuale %+v, attesa la stima al minuto (3.33)", o.Current)
+		}
+	})
+}
+
+func TestGeomagneticLatitude(t *testing.T) {
+	// con il polo IGRF-14 (epoca 2025) Tromsø risulta ~67.5°; le tabelle
+	// più vecchie, con il polo più lontano, danno ~66.5-67°
+	tests := []struct {
+		name     string
+		lat, lon float64
+		min, max float64
+	}{
+		{"Catania", 37.50, 15.09, 37, 38},
+		{"Tromsø", 69.65, 18.96, 66, 68},
+	}
+	for _, tt := range tests {
+		if m := GeomagneticLatitude(tt.lat, tt.lon); m < tt.min || m > tt.max {
+			t.Errorf("%s: latitudine geomagnetica %.2f°, attesa fra %.0f° e %.0f°", tt.name, m, tt.min, tt.max)
+		}
+	}
+}
+
+func TestAuroraChanceAt(t *testing.T) {
+	// Tromsø sta già sotto l'ovale con Kp 0
+	if c := AuroraChanceAt(69.65, 18.96, 0); c.KpForHorizon != 0 || !c.Overhead {
+		t.Errorf("Tromsø: %+v, attesa aurora allo zenit con Kp 0", c)
+	}
+
+	c := AuroraChanceAt(37.50, 15.09, 5)
+	want := (auroraOvalKp0 - auroraViewMargin - c.GeomagLat) / auroraOvalPerKp
+	if math.Abs(c.KpForHorizon-want) > 1e-9 || c.KpForHorizon <= auroraMaxKp {
+		t.Errorf("Catania: Kp per l'orizzonte %.2f, atteso %.2f (oltre la scala)", c.KpForHorizon, want)
+	}
+	if c.OnHorizon || c.Overhead {
+		t.Errorf("Catania con Kp 5: %+v, attesa aurora non visibile", c)
+	}
+
+	// appena sopra la soglia calcolata l'aurora compare all'orizzonte
+	site := AuroraChanceAt(55, 10, 0)
+	if c := AuroraChanceAt(55, 10, site.KpForHorizon+0.01); !c.OnHorizon || c.Overhead {
+		t.Errorf("Kp %.2f a 55°N: %+v, attesa aurora bassa all'orizzonte", site.KpForHorizon+0.01, c)
+	}
+	if c := AuroraChanceAt(55, 10, site.KpForHorizon-0.01); c.OnHorizon {
+		t.Errorf("Kp %.2f a 55°N: aurora visibile sotto la soglia", site.KpForHorizon-0.01)
+	}
+}
@@ -0,0 +1,388 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// =======================
+//  Posizione del dispositivo
+// =======================
+//
+// La posizione arriva da una catena di LocationProvider provati in ordine:
+// un ricevitore GPS tramite gpsd, la geolocalizzazione dall'IP pubblico
+// (ip-api.com o ipwho.is) oppure coordinate inserite a mano.
+
+// DeviceLocation rappresenta la posizione geografica del dispositivo
+type DeviceLocation struct {
+	Lat        float64
+	Lon        float64
+	ElevationM float64 // NaN se la fonte non fornisce la quota
+	AccuracyM  float64 // incertezza orizzontale in metri, NaN se ignota
+	Provider   string  // nome della fonte che ha risposto
+}
+
+// LocationProvider è una fonte di posizione.
+type LocationProvider interface {
+	// Name è l'identificativo della fonte (usato anche nell'ordine configurabile)
+	Name() string
+	// Locate restituisce la posizione o un errore se la fonte non risponde
+	Locate() (*DeviceLocation, error)
+}
+
+// Nomi delle fonti disponibili
+const (
+	LocationGpsd    = "gpsd"
+	LocationIPAPI   = "ip-api"
+	LocationIPWhois = "ipwho.is"
+	LocationManual  = "manuale"
+)
+
+// DefaultLocationOrder è l'ordine usato se l'utente non ne configura uno:
+// prima il GPS (se gpsd è in ascolto), poi i due servizi di IP-geo.
+var DefaultLocationOrder = []string{LocationGpsd, LocationIPAPI, LocationIPWhois}
+
+// IPGeoAccuracyM è l'incertezza tipica della geolocalizzazione via IP
+// (di solito indica la città del provider, non il dispositivo).
+const IPGeoAccuracyM = 25000
+
+// locateHTTPClient ha un timeout breve: se un servizio non risponde si passa
+// subito alla fonte successiva.
+var locateHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// AutoLocateDevice prova le fonti di DefaultLocationOrder (senza coordinate
+// manuali) e restituisce la prima posizione trovata.
+func AutoLocateDevice() (*DeviceLocation, error) {
+	providers, err := LocationProviders(DefaultLocationOrder, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return LocateDevice(providers)
+}
+
+// LocateDevice prova le fonti in ordine e restituisce la prima posizione
+// valida; se nessuna risponde l'errore elenca il motivo di ciascuna.
+func LocateDevice(providers []LocationProvider) (*DeviceLocation, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("nessuna fonte di posizione configurata")
+	}
+	var errs []error
+	for _, p := range providers {
+		loc, err := p.Locate()
+		if err == nil {
+			err = checkLocation(loc)
+		}
+		if err != nil {
+			log.Printf("[Location] %s: %v", p.Name(), err)
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+			continue
+		}
+		if loc.Provider == "" {
+			loc.Provider = p.Name()
+		}
+		return loc, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+func checkLocation(loc *DeviceLocation) error {
+	if loc == nil || math.IsNaN(loc.Lat) || math.IsNaN(loc.Lon) ||
+		math.Abs(loc.Lat) > 90 || math.Abs(loc.Lon) > 180 {
+		return errors.New("coordinate non valide")
+	}
+	return nil
+}
+
+// LocationProviders costruisce le fonti nell'ordine indicato. gpsdAddr vuoto
+// usa l'indirizzo di default di gpsd; manual sono le coordinate inserite a
+// mano (nil = la fonte "manuale" viene saltata).
+func LocationProviders(order []string, gpsdAddr string, manual *DeviceLocation) ([]LocationProvider, error) {
+	var out []LocationProvider
+	for _, name := range order {
+		switch name {
+		case LocationGpsd:
+			out = append(out, GpsdProvider{Addr: gpsdAddr})
+		case LocationIPAPI:
+			out = append(out, IPAPIProvider{})
+		case LocationIPWhois:
+			out = append(out, IPWhoisProvider{})
+		case LocationManual:
+			if manual != nil {
+				out = append(out, ManualProvider{Lat: manual.Lat, Lon: manual.Lon, ElevationM: manual.ElevationM})
+			}
+		default:
+			return nil, fmt.Errorf("fonte di posizione sconosciuta: %q", name)
+		}
+	}
+	return out, nil
+}
+
+// ParseLocationOrder legge un ordine di fonti separato da virgole o spazi,
+// es. "gpsd, ip-api, manuale". Vuoto = DefaultLocationOrder.
+func ParseLocationOrder(text string) ([]string, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == ';' })
+	if len(fields) == 0 {
+		return append([]string(nil), DefaultLocationOrder...), nil
+	}
+	var order []string
+	for _, f := range fields {
+		name := strings.ToLower(f)
+		switch name {
+		case LocationGpsd, LocationIPAPI, LocationIPWhois, LocationManual:
+		default:
+			return nil, fmt.Errorf("fonte di posizione sconosciuta: %q (valide: %s, %s, %s, %s)",
+				f, LocationGpsd, LocationIPAPI, LocationIPWhois, LocationManual)
+		}
+		if !containsString(order, name) {
+			order = append(order, name)
+		}
+	}
+	return order, nil
+}
+
+// =======================
+//  Geolocalizzazione via IP
+// =======================
+
+// IPAPIProvider usa ip-api.com (il piano gratuito è solo HTTP in chiaro).
+type IPAPIProvider struct{}
+
+// ipAPIResponse è la risposta dal servizio ip-api.com
+type ipAPIResponse struct {
+	Status  string  `json:"status"`
+	Message string  `json:"message"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+func (IPAPIProvider) Name() string { return LocationIPAPI }
+
+func (IPAPIProvider) Locate() (*DeviceLocation, error) {
+	var data ipAPIResponse
+	if err := getLocationJSON("http://ip-api.com/json/", &data); err != nil {
+		return nil, err
+	}
+	if data.Status != "success" {
+		if data.Message != "" {
+			return nil, fmt.Errorf("IP-geo fallita: %s", data.Message)
+		}
+		return nil, fmt.Errorf("IP-geo fallita con stato: %s", data.Status)
+	}
+	return ipLocation(data.Lat, data.Lon), nil
+}
+
+// IPWhoisProvider usa ipwho.is (HTTPS, senza chiave).
+type IPWhoisProvider struct{}
+
+// ipWhoisResponse è la risposta dal servizio ipwho.is
+type ipWhoisResponse struct {
+	Success   bool    `json:"success"`
+	Message   string  `json:"message"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (IPWhoisProvider) Name() string { return LocationIPWhois }
+
+func (IPWhoisProvider) Locate() (*DeviceLocation, error) {
+	var data ipWhoisResponse
+	if err := getLocationJSON("https://ipwho.is/", &data); err != nil {
+		return nil, err
+	}
+	if !data.Success {
+		if data.Message != "" {
+			return nil, fmt.Errorf("IP-geo fallita: %s", data.Message)
+		}
+		return nil, errors.New("IP-geo fallita")
+	}
+	return ipLocation(data.Latitude, data.Longitude), nil
+}
+
+func ipLocation(lat, lon float64) *DeviceLocation {
+	return &DeviceLocation{Lat: lat, Lon: lon, ElevationM: math.NaN(), AccuracyM: IPGeoAccuracyM}
+}
+
+// getLocationJSON scarica e decodifica una risposta JSON (mai dalla cache:
+// la posizione può cambiare a ogni richiesta).
+func getLocationJSON(url string, v any) error {
+	resp, err := locateHTTPClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("errore richiesta IP-geo: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("IP-geo: risposta HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("errore lettura risposta IP-geo: %w", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("errore decode JSON IP-geo: %w", err)
+	}
+	return nil
+}
+
+// =======================
+//  Posizione manuale
+// =======================
+
+// ManualProvider restituisce coordinate fisse inserite dall'utente
+// (utile senza rete né GPS). La precisione non è nota.
+type ManualProvider struct {
+	Lat        float64
+	Lon        float64
+	ElevationM float64 // NaN o 0 se non indicata
+}
+
+func (ManualProvider) Name() string { return LocationManual }
+
+func (m ManualProvider) Locate() (*DeviceLocation, error) {
+	return &DeviceLocation{Lat: m.Lat, Lon: m.Lon, ElevationM: m.ElevationM, AccuracyM: math.NaN()}, nil
+}
+
+// =======================
+//  gpsd (protocollo JSON su TCP)
+// =======================
+
+// Valori di default per gpsd
+const (
+	DefaultGpsdAddr    = "localhost:2947"
+	DefaultGpsdTimeout = 5 * time.Second
+)
+
+// GpsdProvider legge la posizione da gpsd (ricevitori GPS USB/seriali).
+// Attiva il flusso JSON con ?WATCH e attende il primo TPV con un fix 2D o 3D.
+type GpsdProvider struct {
+	Addr    string        // host:porta, vuoto = DefaultGpsdAddr
+	Timeout time.Duration // attesa massima del fix, 0 = DefaultGpsdTimeout
+}
+
+// gpsdReport contiene i campi dei messaggi gpsd che ci interessano
+// (TPV, DEVICES, ERROR); i campi facoltativi sono puntatori.
+type gpsdReport struct {
+	Class   string     `json:"class"`
+	Mode    int        `json:"mode"` // 0 ignoto, 1 nessun fix, 2 fix 2D, 3 fix 3D
+	Lat     *float64   `json:"lat"`
+	Lon     *float64   `json:"lon"`
+	AltMSL  *float64   `json:"altMSL"`
+	Alt     *float64   `json:"alt"` // gpsd < 3.20
+	Eph     *float64   `json:"eph"` // errore orizzontale stimato (m)
+	Epx     *float64   `json:"epx"`
+	Epy     *float64   `json:"epy"`
+	Devices []struct{} `json:"devices"`
+	Message string     `json:"message"`
+}
+
+func (GpsdProvider) Name() string { return LocationGpsd }
+
+func (g GpsdProvider) Locate() (*DeviceLocation, error) {
+	addr := g.Addr
+	if addr == "" {
+		addr = DefaultGpsdAddr
+	}
+	timeout := g.Timeout
+	if timeout <= 0 {
+		timeout = DefaultGpsdTimeout
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("gpsd non raggiungibile su %s: %w", addr, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(conn, `?WATCH={"enable":true,"json":true};`+"\n"); err != nil {
+		return nil, fmt.Errorf("errore invio a gpsd: %w", err)
+	}
+	loc, err := readGpsdFix(conn)
+	// chiude il flusso in modo pulito (gpsd lo farebbe comunque alla disconnessione)
+	io.WriteString(conn, `?WATCH={"enable":false};`+"\n")
+	return loc, err
+}
+
+// readGpsdFix legge i messaggi gpsd (uno per riga) fino al primo fix valido.
+func readGpsdFix(r io.Reader) (*DeviceLocation, error) {
+	sc := bufio.NewScanner(r)
+	lastMode := -1
+	for sc.Scan() {
+		var rep gpsdReport
+		if err := json.Unmarshal(sc.Bytes(), &rep); err != nil {
+			continue // riga non JSON o troncata: si ignora
+		}
+		switch rep.Class {
+		case "ERROR":
+			return nil, fmt.Errorf("gpsd: %s", rep.Message)
+		case "DEVICES":
+			if rep.Devices != nil && len(rep.Devices) == 0 {
+				return nil, errors.New("nessun ricevitore GPS collegato a gpsd")
+			}
+		case "TPV":
+			lastMode = rep.Mode
+			if rep.Mode < 2 || rep.Lat == nil || rep.Lon == nil {
+				continue
+			}
+			return gpsdLocation(rep), nil
+		}
+	}
+
+	err := sc.Err()
+	var ne net.Error
+	switch {
+	case errors.As(err, &ne) && ne.Timeout() && lastMode >= 0:
+		return nil, fmt.Errorf("nessun fix GPS entro il timeout (mode %d)", lastMode)
+	case errors.As(err, &ne) && ne.Timeout():
+		return nil, errors.New("gpsd non ha inviato posizioni entro il timeout")
+	case err != nil:
+		return nil, fmt.Errorf("errore lettura da gpsd: %w", err)
+	}
+	return nil, errors.New("gpsd ha chiuso la connessione senza un fix")
+}
+
+func gpsdLocation(rep gpsdReport) *DeviceLocation {
+	loc := &DeviceLocation{
+		Lat:        *rep.Lat,
+		Lon:        *rep.Lon,
+		ElevationM: math.NaN(),
+		AccuracyM:  math.NaN(),
+	}
+	// la quota è affidabile solo con un fix 3D
+	if rep.Mode >= 3 {
+		if rep.AltMSL != nil {
+			loc.ElevationM = *rep.AltMSL
+		} else if rep.Alt != nil {
+			loc.ElevationM = *rep.Alt
+		}
+	}
+	switch {
+	case rep.Eph != nil:
+		loc.AccuracyM = *rep.Eph
+	case rep.Epx != nil && rep.Epy != nil:
+		loc.AccuracyM = math.Max(*rep.Epx, *rep.Epy)
+	}
+	return loc
+}
+
+// FormatLocationAccuracy descrive la precisione di una posizione.
+func FormatLocationAccuracy(m float64) string {
+	switch {
+	case math.IsNaN(m):
+		return "precisione ignota"
+	case m < 1000:
+		return fmt.Sprintf("±%.0f m", m)
+	default:
+		return fmt.Sprintf("±%.0f km", m/1000)
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadGpsdFix(t *testing.T) {
+	tests := []struct {
+		name     string
+		stream   string
+		lat      float64
+		elev     float64 // NaN = quota assente
+		accuracy float64 // NaN = precisione ignota
+		err      string  // sottostringa attesa nell'errore, vuota = fix valido
+	}{
+		{
+			name: "attende il fix 3D dopo un TPV senza fix",
+			stream: `{"class":"VERSION","release":"3.25"}
+{"class":"DEVICES","devices":[{"path":"/dev/ttyACM0"}]}
+{"class":"TPV","mode":1}
+riga troncata {"class":
+{"class":"TPV","mode":3,"lat":37.5,"lon":15.1,"altMSL":120.5,"alt":160,"eph":8.2}`,
+			lat: 37.5, elev: 120.5, accuracy: 8.2,
+		},
+		{
+			name:   "quota da alt con gpsd vecchi, precisione da epx/epy",
+			stream: `{"class":"TPV","mode":3,"lat":37.5,"lon":15.1,"alt":160,"epx":6,"epy":9}`,
+			lat:    37.5, elev: 160, accuracy: 9,
+		},
+		{
+			name:   "fix 2D senza quota",
+			stream: `{"class":"TPV","mode":2,"lat":37.5,"lon":15.1,"altMSL":120.5}`,
+			lat:    37.5, elev: math.NaN(), accuracy: math.NaN(),
+		},
+		{
+			name:   "nessun ricevitore",
+			stream: `{"class":"DEVICES","devices":[]}`,
+			err:    "nessun ricevitore GPS",
+		},
+		{
+			name:   "errore di gpsd",
+			stream: `{"class":"ERROR","message":"unrecognized request"}`,
+			err:    "gpsd: unrecognized request",
+		},
+		{
+			name:   "connessione chiusa senza fix",
+			stream: `{"class":"TPV","mode":1}`,
+			err:    "senza un fix",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := readGpsdFix(strings.NewReader(tt.stream))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("errore %v, atteso %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loc.Lat != tt.lat || !sameOrNaN(loc.ElevationM, tt.elev) || !sameOrNaN(loc.AccuracyM, tt.accuracy) {
+				t.Errorf("posizione %+v, attesa lat %v, quota %v, precisione %v", loc, tt.lat, tt.elev, tt.accuracy)
+			}
+		})
+	}
+}
+
+// sameOrNaN confronta due valori considerando uguali due NaN.
+func sameOrNaN(a, b float64) bool {
+	return a == b || math.IsNaN(a) && math.IsNaN(b)
+}
+
+func TestGpsdLocation(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	rep := gpsdReport{Class: "TPV", Mode: 2, Lat: f(45), Lon: f(9), Alt: f(300), Epx: f(4)}
+	loc := gpsdLocation(rep)
+	// fix 2D: la quota non è affidabile; con solo epx la precisione resta ignota
+	if !math.IsNaN(loc.ElevationM) || !math.IsNaN(loc.AccuracyM) {
+		t.Errorf("fix 2D: quota %v, precisione %v; attese entrambe NaN", loc.ElevationM, loc.AccuracyM)
+	}
+
+	rep.Mode, rep.Epy, rep.Eph = 3, f(7), f(5)
+	loc = gpsdLocation(rep)
+	if loc.ElevationM != 300 || loc.AccuracyM != 5 {
+		t.Errorf("fix 3D: quota %v, precisione %v; attese 300 e 5 (eph prima di epx/epy)", loc.ElevationM, loc.AccuracyM)
+	}
+}
+
+func TestParseLocationOrder(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+		ok   bool
+	}{
+		{"", DefaultLocationOrder, true},
+		{"gpsd, ip-api; manuale", []string{LocationGpsd, LocationIPAPI, LocationManual}, true},
+		{"GPSD ipwho.is gpsd", []string{LocationGpsd, LocationIPWhois}, true}, // maiuscole e doppioni
+		{"gpsd, satellite", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseLocationOrder(tt.text)
+		if (err == nil) != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: %v, %v; atteso %v (ok %v)", tt.text, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+// fakeProvider è una fonte di posizione con risposta fissa.
+type fakeProvider struct {
+	name  string
+	loc   *DeviceLocation
+	err   error
+	calls *int
+}
+
+func (f fakeProvider) Name() string { return f.name }
+
+func (f fakeProvider) Locate() (*DeviceLocation, error) {
+	*f.calls++
+	return f.loc, f.err
+}
+
+func TestLocateDeviceFallsThrough(t *testing.T) {
+	var calls int
+	gpsErr := errors.New("gpsd non raggiungibile")
+	providers := []LocationProvider{
+		fakeProvider{name: "gps", err: gpsErr, calls: &calls},
+		fakeProvider{name: "rotto", loc: &DeviceLocation{Lat: 120, Lon: 9}, calls: &calls},
+		fakeProvider{name: "ip", loc: &DeviceLocation{Lat: 45, Lon: 9}, calls: &calls},
+		fakeProvider{name: "mai", loc: &DeviceLocation{Lat: 0, Lon: 0}, calls: &calls},
+	}
+
+	loc, err := LocateDevice(providers)
+	if err != nil || loc.Lat != 45 || loc.Provider != "ip" {
+		t.Fatalf("posizione %+v, %v; attesa quella di ip", loc, err)
+	}
+	if calls != 3 {
+		t.Errorf("%d fonti interrogate, attese 3 (ci si ferma alla prima valida)", calls)
+	}
+
+	// nessuna fonte valida: l'errore riporta il motivo di ciascuna
+	_, err = LocateDevice(providers[:2])
+	if err == nil || !errors.Is(err, gpsErr) {
+		t.Fatalf("errore %v, atteso che includa %v", err, gpsErr)
+	}
+	for _, want := range []string{"gps: gpsd non raggiungibile", "rotto: coordinate non valide"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("errore %q senza %q", err, want)
+		}
+	}
+}
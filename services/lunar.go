@@ -0,0 +1,397 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Efemeride lunare (Meeus, cap. 47)
+// =======================
+//
+// Serie troncata della teoria ELP-2000/82 come riportata da Meeus
+// ("Astronomical Algorithms", cap. 47): precisione ~10" in longitudine e
+// ~4" in latitudine, ampiamente sufficiente per fasi, sorgere/tramonto e
+// planning osservativo.
+
+// lunarTerm è un termine periodico: moltiplicatori di D, M, M', F e
+// coefficienti (1e-6 gradi per longitudine/latitudine, 1e-3 km per la distanza).
+type lunarTerm struct {
+	d, m, mp, f int8
+	sl, sr      float64
+}
+
+// Tabella 47.A: longitudine (Σl) e distanza (Σr)
+var lunarLR = []lunarTerm{
+	{0, 0, 1, 0, 6288774, -20905355},
+	{2, 0, -1, 0, 1274027, -3699111},
+	{2, 0, 0, 0, 658314, -2955968},
+	{0, 0, 2, 0, 213618, -569925},
+	{0, 1, 0, 0, -185116, 48888},
+	{0, 0, 0, 2, -114332, -3149},
+	{2, 0, -2, 0, 58793, 246158},
+	{2, -1, -1, 0, 57066, -152138},
+	{2, 0, 1, 0, 53322, -170733},
+	{2, -1, 0, 0, 45758, -204586},
+	{0, 1, -1, 0, -40923, -129620},
+	{1, 0, 0, 0, -34720, 108743},
+	{0, 1, 1, 0, -30383, 104755},
+	{2, 0, 0, -2, 15327, 10321},
+	{0, 0, 1, 2, -12528, 0},
+	{0, 0, 1, -2, 10980, 79661},
+	{4, 0, -1, 0, 10675, -34782},
+	{0, 0, 3, 0, 10034, -23210},
+	{4, 0, -2, 0, 8548, -21636},
+	{2, 1, -1, 0, -7888, 24208},
+	{2, 1, 0, 0, -6766, 30824},
+	{1, 0, -1, 0, -5163, -8379},
+	{1, 1, 0, 0, 4987, -16675},
+	{2, -1, 1, 0, 4036, -12831},
+	{2, 0, 2, 0, 3994, -10445},
+	{4, 0, 0, 0, 3861, -11650},
+	{2, 0, -3, 0, 3665, 14403},
+	{0, 1, -2, 0, -2689, -7003},
+	{2, 0, -1, 2, -2602, 0},
+	{2, -1, -2, 0, 2390, 10056},
+	{1, 0, 1, 0, -2348, 6322},
+	{2, -2, 0, 0, 2236, -9884},
+	{0, 1, 2, 0, -2120, 5751},
+	{0, 2, 0, 0, -2069, 0},
+	{2, -2, -1, 0, 2048, -4950},
+	{2, 0, 1, -2, -1773, 4130},
+	{2, 0, 0, 2, -1595, 0},
+	{4, -1, -1, 0, 1215, -3958},
+	{0, 0, 2, 2, -1110, 0},
+	{3, 0, -1, 0, -892, 3258},
+	{2, 1, 1, 0, -810, 2616},
+	{4, -1, -2, 0, 759, -1897},
+	{0, 2, -1, 0, -713, -2117},
+	{2, 2, -1, 0, -700, 2354},
+	{2, 1, -2, 0, 691, 0},
+	{2, -1, 0, -2, 596, 0},
+	{4, 0, 1, 0, 549, -1423},
+	{0, 0, 4, 0, 537, -1117},
+	{4, -1, 0, 0, 520, -1571},
+	{1, 0, -2, 0, -487, -1739},
+	{2, 1, 0, -2, -399, 0},
+	{0, 0, 2, -2, -381, -4421},
+	{1, 1, 1, 0, 351, 0},
+	{3, 0, -2, 0, -340, 0},
+	{4, 0, -3, 0, 330, 0},
+	{2, -1, 2, 0, 327, 0},
+	{0, 2, 1, 0, -323, 1165},
+	{1, 1, -1, 0, 299, 0},
+	{2, 0, 3, 0, 294, 0},
+	{2, 0, -1, -2, 0, 8752},
+}
+
+// Tabella 47.B: latitudine (Σb); sr non usato
+var lunarB = []lunarTerm{
+	{0, 0, 0, 1, 5128122, 0},
+	{0, 0, 1, 1, 280602, 0},
+	{0, 0, 1, -1, 277693, 0},
+	{2, 0, 0, -1, 173237, 0},
+	{2, 0, -1, 1, 55413, 0},
+	{2, 0, -1, -1, 46271, 0},
+	{2, 0, 0, 1, 32573, 0},
+	{0, 0, 2, 1, 17198, 0},
+	{2, 0, 1, -1, 9266, 0},
+	{0, 0, 2, -1, 8822, 0},
+	{2, -1, 0, -1, 8216, 0},
+	{2, 0, -2, -1, 4324, 0},
+	{2, 0, 1, 1, 4200, 0},
+	{2, 1, 0, -1, -3359, 0},
+	{2, -1, -1, 1, 2463, 0},
+	{2, -1, 0, 1, 2211, 0},
+	{2, -1, -1, -1, 2065, 0},
+	{0, 1, -1, -1, -1870, 0},
+	{4, 0, -1, -1, 1828, 0},
+	{0, 1, 0, 1, -1794, 0},
+	{0, 0, 0, 3, -1749, 0},
+	{0, 1, -1, 1, -1565, 0},
+	{1, 0, 0, 1, -1491, 0},
+	{0, 1, 1, 1, -1475, 0},
+	{0, 1, 1, -1, -1410, 0},
+	{0, 1, 0, -1, -1344, 0},
+	{1, 0, 0, -1, -1335, 0},
+	{0, 0, 3, 1, 1107, 0},
+	{4, 0, 0, -1, 1021, 0},
+	{4, 0, -1, 1, 833, 0},
+	{0, 0, 1, -3, 777, 0},
+	{4, 0, -2, 1, 671, 0},
+	{2, 0, 0, -3, 607, 0},
+	{2, 0, 2, -1, 596, 0},
+	{2, -1, 1, -1, 491, 0},
+	{2, 0, -2, 1, -451, 0},
+	{0, 0, 3, -1, 439, 0},
+	{2, 0, 2, 1, 422, 0},
+	{2, 0, -3, -1, 421, 0},
+	{2, 1, -1, 1, -366, 0},
+	{2, 1, 0, 1, -351, 0},
+	{4, 0, 0, 1, 331, 0},
+	{2, -1, 1, 1, 315, 0},
+	{2, -2, 0, -1, 302, 0},
+	{0, 0, 1, 3, -283, 0},
+	{2, 1, 1, -1, -229, 0},
+	{1, 1, 0, -1, 223, 0},
+	{1, 1, 0, 1, 223, 0},
+	{0, 1, -2, -1, -220, 0},
+	{2, 1, -1, -1, -220, 0},
+	{1, 0, 1, 1, -185, 0},
+	{2, -1, -2, -1, 181, 0},
+	{0, 1, 2, 1, -177, 0},
+	{4, 0, -2, -1, 176, 0},
+	{4, -1, -1, -1, 166, 0},
+	{1, 0, 1, -1, -164, 0},
+	{4, 0, 1, -1, 132, 0},
+	{1, 0, -1, -1, -119, 0},
+	{4, -1, 0, -1, 115, 0},
+	{2, -2, 0, 1, 107, 0},
+}
+
+const (
+	earthRadiusKm = 6378.14
+	moonRadiusKm  = 1737.4
+	// velocità media dell'elongazione lunare (gradi/giorno)
+	meanElongationRate = 360.0 / synodicMonth
+)
+
+// deltaT approssima TT-UT (Espenak & Meeus, valida 2005-2050; fuori
+// intervallo resta comunque entro qualche decina di secondi).
+func deltaT(t time.Time) time.Duration {
+	y := float64(t.Year()) + (float64(t.YearDay())-0.5)/365.25
+	u := y - 2000.0
+	return time.Duration((62.92 + 0.32217*u + 0.005589*u*u) * float64(time.Second))
+}
+
+// nutation restituisce nutazione in longitudine e in obliquità (gradi),
+// con i termini principali (precisione ~0.5").
+func nutation(T float64) (dPsi, dEps float64) {
+	omega := (125.04452 - 1934.136261*T) * deg2rad
+	L := (280.4665 + 36000.7698*T) * deg2rad
+	Lp := (218.3165 + 481267.8813*T) * deg2rad
+	dPsi = -17.20*math.Sin(omega) - 1.32*math.Sin(2*L) - 0.23*math.Sin(2*Lp) + 0.21*math.Sin(2*omega)
+	dEps = 9.20*math.Cos(omega) + 0.57*math.Cos(2*L) + 0.10*math.Cos(2*Lp) - 0.09*math.Cos(2*omega)
+	return dPsi / 3600.0, dEps / 3600.0
+}
+
+// meanObliquity restituisce l'obliquità media dell'eclittica (gradi).
+func meanObliquity(T float64) float64 {
+	return 23.0 + (26.0+(21.448-T*(46.8150+T*(0.00059-T*0.001813)))/60.0)/60.0
+}
+
+// eclipticToEquatorial converte coordinate eclittiche in equatoriali (gradi).
+func eclipticToEquatorial(lambdaDeg, betaDeg, epsDeg float64) (raDeg, decDeg float64) {
+	l, b, e := lambdaDeg*deg2rad, betaDeg*deg2rad, epsDeg*deg2rad
+	ra := math.Atan2(math.Sin(l)*math.Cos(e)-math.Tan(b)*math.Sin(e), math.Cos(l))
+	dec := math.Asin(math.Sin(b)*math.Cos(e) + math.Cos(b)*math.Sin(e)*math.Sin(l))
+	return normDeg(ra * rad2deg), dec * rad2deg
+}
+
+// moonEcliptic restituisce longitudine e latitudine eclittiche apparenti
+// (gradi) e distanza geocentrica (km) della Luna all'istante UT t.
+func moonEcliptic(t time.Time) (lambdaDeg, betaDeg, distKm float64) {
+	T := julianCenturies(JulianDay(t.Add(deltaT(t))))
+	T2, T3, T4 := T*T, T*T*T, T*T*T*T
+
+	Lp := normDeg(218.3164477 + 481267.88123421*T - 0.0015786*T2 + T3/538841.0 - T4/65194000.0)
+	D := normDeg(297.8501921 + 445267.1114034*T - 0.0018819*T2 + T3/545868.0 - T4/113065000.0)
+	M := normDeg(357.5291092 + 35999.0502909*T - 0.0001536*T2 + T3/24490000.0)
+	Mp := normDeg(134.9633964 + 477198.8675055*T + 0.0087414*T2 + T3/69699.0 - T4/14712000.0)
+	F := normDeg(93.2720950 + 483202.0175233*T - 0.0036539*T2 - T3/3526000.0 + T4/863310000.0)
+
+	A1 := normDeg(119.75 + 131.849*T)
+	A2 := normDeg(53.09 + 479264.290*T)
+	A3 := normDeg(313.45 + 481266.484*T)
+	E := 1.0 - 0.002516*T - 0.0000074*T2
+
+	eccFactor := func(m int8) float64 {
+		switch m {
+		case 1, -1:
+			return E
+		case 2, -2:
+			return E * E
+		}
+		return 1
+	}
+
+	var sl, sr, sb float64
+	for _, tm := range lunarLR {
+		arg := (float64(tm.d)*D + float64(tm.m)*M + float64(tm.mp)*Mp + float64(tm.f)*F) * deg2rad
+		k := eccFactor(tm.m)
+		sl += tm.sl * k * math.Sin(arg)
+		sr += tm.sr * k * math.Cos(arg)
+	}
+	for _, tm := range lunarB {
+		arg := (float64(tm.d)*D + float64(tm.m)*M + float64(tm.mp)*Mp + float64(tm.f)*F) * deg2rad
+		sb += tm.sl * eccFactor(tm.m) * math.Sin(arg)
+	}
+
+	// termini additivi (Venere, Giove, schiacciamento terrestre)
+	sl += 3958*math.Sin(A1*deg2rad) + 1962*math.Sin((Lp-F)*deg2rad) + 318*math.Sin(A2*deg2rad)
+	sb += -2235*math.Sin(Lp*deg2rad) + 382*math.Sin(A3*deg2rad) +
+		175*math.Sin((A1-F)*deg2rad) + 175*math.Sin((A1+F)*deg2rad) +
+		127*math.Sin((Lp-Mp)*deg2rad) - 115*math.Sin((Lp+Mp)*deg2rad)
+
+	dPsi, _ := nutation(T)
+	lambdaDeg = normDeg(Lp + sl/1e6 + dPsi)
+	betaDeg = sb / 1e6
+	distKm = 385000.56 + sr/1000.0
+	return lambdaDeg, betaDeg, distKm
+}
+
+// MoonEquatorial restituisce RA/Dec geocentriche apparenti (gradi) e distanza (km).
+func MoonEquatorial(t time.Time) (raDeg, decDeg, distKm float64) {
+	lambda, beta, dist := moonEcliptic(t)
+	T := julianCenturies(JulianDay(t.Add(deltaT(t))))
+	_, dEps := nutation(T)
+	raDeg, decDeg = eclipticToEquatorial(lambda, beta, meanObliquity(T)+dEps)
+	return raDeg, decDeg, dist
+}
+
+// topocentric corregge RA/Dec per la parallasse di un osservatore al livello
+// del mare (Meeus, cap. 40).
+func topocentric(raDeg, decDeg, distKm, latDeg, lonDeg float64, t time.Time) (topoRA, topoDec float64) {
+	sinPi := earthRadiusKm / distKm
+	lat := latDeg * deg2rad
+	u := math.Atan(0.99664719 * math.Tan(lat))
+	rhoSin := 0.99664719 * math.Sin(u)
+	rhoCos := math.Cos(u)
+
+	H := (LocalSiderealDeg(t, lonDeg) - raDeg) * deg2rad
+	dec := decDeg * deg2rad
+
+	dRA := math.Atan2(-rhoCos*sinPi*math.Sin(H), math.Cos(dec)-rhoCos*sinPi*math.Cos(H))
+	topoDec = math.Atan2((math.Sin(dec)-rhoSin*sinPi)*math.Cos(dRA), math.Cos(dec)-rhoCos*sinPi*math.Cos(H))
+	return normDeg(raDeg + dRA*rad2deg), topoDec * rad2deg
+}
+
+// moonElongation restituisce la differenza di longitudine eclittica
+// Luna − Sole (0..360°): 0 = nuova, 90 = primo quarto, 180 = piena, 270 = ultimo quarto.
+func moonElongation(t time.Time) float64 {
+	lambda, _, _ := moonEcliptic(t)
+	sunLambda, _ := sunEcliptic(t)
+	return normDeg(lambda - sunLambda)
+}
+
+// MoonPhaseFraction restituisce:
+//   - phase: valore tra 0.0 e 1.0 (0 = luna nuova, 0.5 = piena, ecc.),
+//     dalla differenza di longitudine eclittica Luna − Sole
+//   - ageDays: giorni trascorsi dall'ultima luna nuova
+func MoonPhaseFraction(t time.Time) (phase float64, ageDays float64) {
+	elong := moonElongation(t)
+	prevNew := findMoonPhase(t.Add(-time.Duration(elong/meanElongationRate*24*float64(time.Hour))), 0)
+	return elong / 360.0, t.Sub(prevNew).Hours() / 24.0
+}
+
+// MoonIlluminatedFraction restituisce la frazione illuminata del disco (0..1)
+// dall'angolo di fase Sole-Luna-Terra.
+func MoonIlluminatedFraction(t time.Time) float64 {
+	ra, dec, dist := MoonEquatorial(t)
+	sunRA, sunDec, sunDist := sunApparent(t)
+	_, k, _ := moonIllumination(ra, dec, dist, sunRA, sunDec, sunDist)
+	return k
+}
+
+// moonIllumination calcola elongazione geocentrica ψ, frazione illuminata k e
+// angolo di fase i (gradi) dalle posizioni di Luna e Sole (Meeus, cap. 48).
+func moonIllumination(ra, dec, distKm, sunRA, sunDec, sunDistAU float64) (psiDeg, k, iDeg float64) {
+	d, d0 := dec*deg2rad, sunDec*deg2rad
+	dRA := (sunRA - ra) * deg2rad
+	cosPsi := math.Sin(d0)*math.Sin(d) + math.Cos(d0)*math.Cos(d)*math.Cos(dRA)
+	psi := math.Acos(math.Max(-1, math.Min(1, cosPsi)))
+
+	R := sunDistAU * 149597870.7
+	i := math.Atan2(R*math.Sin(psi), distKm-R*math.Cos(psi))
+	return psi * rad2deg, (1 + math.Cos(i)) / 2, i * rad2deg
+}
+
+// brightLimbAngle restituisce l'angolo di posizione (da Nord verso Est) del
+// punto medio del lembo illuminato.
+func brightLimbAngle(ra, dec, sunRA, sunDec float64) float64 {
+	d, d0 := dec*deg2rad, sunDec*deg2rad
+	dRA := (sunRA - ra) * deg2rad
+	chi := math.Atan2(math.Cos(d0)*math.Sin(dRA), math.Sin(d0)*math.Cos(d)-math.Cos(d0)*math.Sin(d)*math.Cos(dRA))
+	return normDeg(chi * rad2deg)
+}
+
+// ComputeMoonPosition calcola l'efemeride completa della Luna per il sito.
+func ComputeMoonPosition(t time.Time, latDeg, lonDeg float64) models.MoonPosition {
+	ra, dec, dist := MoonEquatorial(t)
+	sunRA, sunDec, sunDist := sunApparent(t)
+
+	topoRA, topoDec := topocentric(ra, dec, dist, latDeg, lonDeg, t)
+	alt, az, _ := EquatorialToHorizontal(topoRA, topoDec, latDeg, lonDeg, t)
+
+	psi, k, i := moonIllumination(ra, dec, dist, sunRA, sunDec, sunDist)
+	phase, age := MoonPhaseFraction(t)
+
+	return models.MoonPosition{
+		RADeg:             ra,
+		DecDeg:            dec,
+		TopoRADeg:         topoRA,
+		TopoDecDeg:        topoDec,
+		AltDeg:            alt,
+		AzDeg:             az,
+		DistanceKm:        dist,
+		ElongationDeg:     psi,
+		PhaseAngleDeg:     i,
+		Illumination:      k,
+		BrightLimbDeg:     brightLimbAngle(ra, dec, sunRA, sunDec),
+		Phase:             phase,
+		AgeDays:           age,
+		PhaseName:         MoonPhaseName(phase),
+		AngularDiamArcmin: 2 * math.Asin(moonRadiusKm/dist) * rad2deg * 60,
+	}
+}
+
+// findMoonPhase raffina, a partire dalla stima guess, l'istante in cui
+// l'elongazione Luna − Sole vale targetDeg (iterazione a pendenza media,
+// converge al secondo in pochi passi).
+func findMoonPhase(guess time.Time, targetDeg float64) time.Time {
+	t := guess
+	for i := 0; i < 30; i++ {
+		diff := normDeg180(targetDeg - moonElongation(t))
+		step := time.Duration(diff / meanElongationRate * 24 * float64(time.Hour))
+		t = t.Add(step)
+		if step.Abs() < time.Second {
+			break
+		}
+	}
+	return t.Truncate(time.Second)
+}
+
+// principalPhases elenca le fasi principali con la loro elongazione.
+var principalPhases = []struct {
+	name  string
+	phase float64
+}{
+	{"Luna Nuova", 0},
+	{"Primo Quarto", 0.25},
+	{"Luna Piena", 0.5},
+	{"Ultimo Quarto", 0.75},
+}
+
+// NextMoonPhases restituisce le prossime quattro fasi principali dopo t,
+// in ordine cronologico.
+func NextMoonPhases(t time.Time) []models.MoonPhaseEvent {
+	elong := moonElongation(t)
+	out := make([]models.MoonPhaseEvent, 0, len(principalPhases))
+
+	for _, p := range principalPhases {
+		target := p.phase * 360.0
+		ahead := normDeg(target - elong)
+		guess := t.Add(time.Duration(ahead / meanElongationRate * 24 * float64(time.Hour)))
+		at := findMoonPhase(guess, target)
+		if !at.After(t) {
+			at = findMoonPhase(at.Add(time.Duration(synodicMonth*24*float64(time.Hour))), target)
+		}
+		out = append(out, models.MoonPhaseEvent{Name: p.name, Phase: p.phase, Time: at})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
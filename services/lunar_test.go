@@ -0,0 +1,64 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// fromTD converte un istante in Tempo Dinamico (quello degli esempi di
+// Meeus) nell'UT atteso dalle funzioni del pacchetto, con lo stesso ΔT.
+func fromTD(year int, month time.Month, day, hour, min int) time.Time {
+	td := time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	return td.Add(-deltaT(td))
+}
+
+// angleDiff restituisce la differenza a-b in gradi, ricondotta a ±180°.
+func angleDiff(a, b float64) float64 {
+	return math.Abs(normDeg180(a - b))
+}
+
+func TestMoonMeeus47a(t *testing.T) {
+	// Meeus, esempio 47.a: 1992 aprile 12, 0h TD
+	tm := fromTD(1992, time.April, 12, 0, 0)
+	lambda, beta, dist := moonEcliptic(tm)
+	ra, dec, _ := MoonEquatorial(tm)
+
+	tests := []struct {
+		name      string
+		got, want float64
+		tol       float64
+		angle     bool
+	}{
+		// λ apparente = 133.162655° + Δψ (0.004610°)
+		{"longitudine apparente λ", lambda, 133.167265, 0.001, true},
+		{"latitudine β", beta, -3.229126, 0.0005, false},
+		{"distanza Δ (km)", dist, 368409.7, 1, false},
+		{"ascensione retta α", ra, 134.688470, 0.002, true},
+		{"declinazione δ", dec, 13.768368, 0.001, false},
+	}
+	for _, tt := range tests {
+		diff := math.Abs(tt.got - tt.want)
+		if tt.angle {
+			diff = angleDiff(tt.got, tt.want)
+		}
+		if diff > tt.tol {
+			t.Errorf("%s = %.6f, atteso %.6f (±%g)", tt.name, tt.got, tt.want, tt.tol)
+		}
+	}
+}
+
+func TestMoonIlluminationMeeus48a(t *testing.T) {
+	// Meeus, esempio 48.a: stessa data del 47.a, k = 0.6786, i = 69.0756°
+	tm := fromTD(1992, time.April, 12, 0, 0)
+	ra, dec, dist := MoonEquatorial(tm)
+	sunRA, sunDec, sunDist := sunApparent(tm)
+	_, k, i := moonIllumination(ra, dec, dist, sunRA, sunDec, sunDist)
+
+	if math.Abs(k-0.6786) > 0.0005 {
+		t.Errorf("frazione illuminata = %.4f, attesa 0.6786", k)
+	}
+	if math.Abs(i-69.0756) > 0.01 {
+		t.Errorf("angolo di fase = %.4f°, atteso 69.0756°", i)
+	}
+}
@@ -3,6 +3,11 @@ package services
 import (
 	"embed"
 	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
 
 	"fyne.io/fyne/v2"
 )
@@ -54,3 +59,203 @@ func GetMoonSpriteByIndex(idx int) fyne.Resource {
 	}
 	return nil
 }
+
+// =======================
+//  MoonPhaseCalculator
+// =======================
+
+// Durata media del mese sinodico in giorni (usata solo come stima iniziale
+// nella ricerca delle fasi; vedi lunar.go)
+const synodicMonth = 29.53058867
+
+// MoonPhaseName restituisce il nome italiano della fase (0..1).
+func MoonPhaseName(phase float64) string {
+	// normalizziamo nel range [0,1)
+	f := math.Mod(phase, 1.0)
+	if f < 0 {
+		f += 1.0
+	}
+
+	switch {
+	case isNearPhase(f, 0.0):
+		return "Luna Nuova"
+	case isNearPhase(f, 0.25):
+		return "Primo Quarto"
+	case isNearPhase(f, 0.5):
+		return "Luna Piena"
+	case isNearPhase(f, 0.75):
+		return "Ultimo Quarto"
+	case f >= 0.0 && f < 0.25:
+		return "Falce Crescente"
+	case f >= 0.25 && f < 0.5:
+		return "Gibbosa Crescente"
+	case f >= 0.5 && f < 0.75:
+		return "Gibbosa Calante"
+	default:
+		return "Falce Calante"
+	}
+}
+
+func isNearPhase(value, target float64) bool {
+	const tol = 0.03
+	diff := math.Abs(value - target)
+	return diff <= tol || diff >= 1.0-tol
+}
+
+// =======================
+//  Best nights per osservazione
+// =======================
+
+// Luna "sopra l'orizzonte": centro topocentrico sopra -0.83° (rifrazione +
+// semidiametro), come per il Sole.
+const moonHorizonAlt = SunriseAlt
+
+// bestNightStep è il passo con cui si campiona l'altezza della Luna
+const bestNightStep = 10 * time.Minute
+
+// qualityFromDark valuta la notte dalla quota di buio astronomico senza Luna
+// e dalla sua durata assoluta.
+func qualityFromDark(moonlessHours, darkHours float64) string {
+	if darkHours <= 0 {
+		return "Scarsa"
+	}
+	frac := moonlessHours / darkHours
+	switch {
+	case frac >= 0.9 && moonlessHours >= 4:
+		return "Eccellente"
+	case frac >= 0.6 && moonlessHours >= 3:
+		return "Buona"
+	case moonlessHours >= 1.5:
+		return "Discreta"
+	default:
+		return "Scarsa"
+	}
+}
+
+// moonAltitude restituisce l'altezza topocentrica della Luna (gradi).
+func moonAltitude(t time.Time, latDeg, lonDeg float64) float64 {
+	ra, dec, dist := MoonEquatorial(t)
+	topoRA, topoDec := topocentric(ra, dec, dist, latDeg, lonDeg, t)
+	alt, _, _ := EquatorialToHorizontal(topoRA, topoDec, latDeg, lonDeg, t)
+	return alt
+}
+
+// MoonRiseSet cerca il primo sorgere e il primo tramonto della Luna in
+// [from, to]; nil se non avvengono nell'intervallo.
+func MoonRiseSet(from, to time.Time, latDeg, lonDeg float64) (rise, set *time.Time) {
+	up := func(t time.Time) bool {
+		return moonAltitude(t, latDeg, lonDeg) >= moonHorizonAlt
+	}
+
+	prevT, prevUp := from, up(from)
+	for t := from.Add(bestNightStep); rise == nil || set == nil; t = t.Add(bestNightStep) {
+		if t.After(to) {
+			t = to
+		}
+		cur := up(t)
+		if cur != prevUp {
+			edge := bisectTime(prevT, t, up)
+			if cur && rise == nil {
+				rise = &edge
+			} else if !cur && set == nil {
+				set = &edge
+			}
+		}
+		prevT, prevUp = t, cur
+		if !t.Before(to) {
+			break
+		}
+	}
+	return rise, set
+}
+
+// moonlessWindows restituisce gli intervalli di [start, end] in cui la Luna è
+// sotto l'orizzonte.
+func moonlessWindows(start, end time.Time, latDeg, lonDeg float64) []models.NightWindow {
+	down := func(t time.Time) bool {
+		return moonAltitude(t, latDeg, lonDeg) < moonHorizonAlt
+	}
+
+	var out []models.NightWindow
+	prevT, prevDown := start, down(start)
+	var winStart time.Time
+	if prevDown {
+		winStart = start
+	}
+
+	for t := start.Add(bestNightStep); ; t = t.Add(bestNightStep) {
+		if t.After(end) {
+			t = end
+		}
+		cur := down(t)
+		if cur != prevDown {
+			edge := bisectTime(prevT, t, down)
+			if cur {
+				winStart = edge
+			} else {
+				out = append(out, models.NightWindow{Start: winStart, End: edge})
+			}
+		}
+		prevT, prevDown = t, cur
+		if !t.Before(end) {
+			break
+		}
+	}
+	if prevDown {
+		out = append(out, models.NightWindow{Start: winStart, End: end})
+	}
+	return out
+}
+
+// ComputeBestNights calcola le "migliori notti" nei prossimi `days` giorni per
+// il sito lat/lon: per ogni notte intersecano il buio astronomico con gli
+// intervalli in cui la Luna è sotto l'orizzonte. Le notti con almeno un po' di
+// buio senza Luna sono ordinate per ore utili (a parità, in ordine di data).
+func ComputeBestNights(start time.Time, days int, latDeg, lonDeg float64) []models.BestNightEntry {
+	var nights []models.BestNightEntry
+
+	for i := 0; i < days; i++ {
+		day := NightDay(start).AddDate(0, 0, i)
+		darkStart, darkEnd, ok := AstronomicalNight(day, latDeg, lonDeg)
+		if !ok {
+			continue
+		}
+
+		windows := moonlessWindows(darkStart, darkEnd, latDeg, lonDeg)
+		var hours float64
+		var longest models.NightWindow
+		for _, w := range windows {
+			d := w.End.Sub(w.Start)
+			hours += d.Hours()
+			if d > longest.End.Sub(longest.Start) {
+				longest = w
+			}
+		}
+		if hours <= 0 {
+			continue
+		}
+
+		mid := longest.Start.Add(longest.End.Sub(longest.Start) / 2)
+		phase, _ := MoonPhaseFraction(mid)
+		darkHours := darkEnd.Sub(darkStart).Hours()
+		y, m, d := day.Date()
+
+		nights = append(nights, models.BestNightEntry{
+			Date:         time.Date(y, m, d, 0, 0, 0, 0, day.Location()),
+			DarkStart:    darkStart,
+			DarkEnd:      darkEnd,
+			Start:        longest.Start,
+			End:          longest.End,
+			Windows:      windows,
+			DarkHours:    hours,
+			Illumination: MoonIlluminatedFraction(mid),
+			PhaseName:    MoonPhaseName(phase),
+			Quality:      qualityFromDark(hours, darkHours),
+		})
+	}
+
+	sort.SliceStable(nights, func(i, j int) bool {
+		return nights[i].DarkHours > nights[j].DarkHours
+	})
+	return nights
+}
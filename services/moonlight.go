@@ -0,0 +1,78 @@
+package services
+
+import (
+	"math"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Disturbo lunare (Krisciunas & Schaefer 1991)
+// =======================
+
+const (
+	// coefficiente di estinzione in banda V (mag/airmass) di un sito medio
+	defaultExtinctionV = 0.172
+	// brillanza del cielo buio allo zenit (mag/arcsec², banda V)
+	darkSkyZenithMag = 21.587
+)
+
+// ksAirmass è l'airmass del modello K&S (valida anche vicino all'orizzonte).
+func ksAirmass(zenithDeg float64) float64 {
+	s := math.Sin(zenithDeg * deg2rad)
+	return 1.0 / math.Sqrt(1.0-0.96*s*s)
+}
+
+// magToNanoLambert converte una brillanza in mag/arcsec² in nanoLambert.
+func magToNanoLambert(mag float64) float64 {
+	return 34.08 * math.Exp(20.7233-0.92104*mag)
+}
+
+// moonSkyBrightening calcola di quante mag/arcsec² la Luna schiarisce il fondo
+// cielo in direzione del target (Krisciunas & Schaefer 1991, PASP 103, 1033).
+// phaseAngleDeg: 0 = Luna piena, 180 = nuova; sepDeg: distanza Luna-target.
+func moonSkyBrightening(moonAltDeg, targetAltDeg, sepDeg, phaseAngleDeg, k float64) float64 {
+	if moonAltDeg <= 0 || targetAltDeg <= 0 {
+		return 0
+	}
+
+	alpha := math.Abs(phaseAngleDeg)
+	istar := math.Pow(10, -0.4*(3.84+0.026*alpha+4e-9*math.Pow(alpha, 4)))
+
+	rho := sepDeg
+	cosRho := math.Cos(rho * deg2rad)
+	fRho := math.Pow(10, 5.36)*(1.06+cosRho*cosRho) + math.Pow(10, 6.15-rho/40.0)
+
+	xMoon := ksAirmass(90 - moonAltDeg)
+	xTarget := ksAirmass(90 - targetAltDeg)
+
+	bMoon := fRho * istar * math.Pow(10, -0.4*k*xMoon) * (1 - math.Pow(10, -0.4*k*xTarget))
+	bSky := magToNanoLambert(darkSkyZenithMag) * xTarget * math.Pow(10, -0.4*k*(xTarget-1))
+
+	return 2.5 * math.Log10((bMoon+bSky)/bSky)
+}
+
+// ComputeMoonInterference calcola separazione dalla Luna e schiarimento del
+// cielo per un target a raDeg/decDeg che si trova a targetAltDeg.
+func ComputeMoonInterference(moon models.MoonPosition, raDeg, decDeg, targetAltDeg float64) models.MoonInterference {
+	sep := AngularSeparation(moon.TopoRADeg, moon.TopoDecDeg, raDeg, decDeg)
+	return models.MoonInterference{
+		SeparationDeg:     sep,
+		SkyBrighteningMag: moonSkyBrightening(moon.AltDeg, targetAltDeg, sep, moon.PhaseAngleDeg, defaultExtinctionV),
+		MoonUp:            moon.AltDeg > 0,
+	}
+}
+
+// MoonInterferenceLabel descrive a parole lo schiarimento del cielo.
+func MoonInterferenceLabel(mag float64) string {
+	switch {
+	case mag < 0.1:
+		return "trascurabile"
+	case mag < 0.5:
+		return "lieve"
+	case mag < 1.5:
+		return "moderato"
+	default:
+		return "forte"
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+func TestMoonSkyBrightening(t *testing.T) {
+	// Luna piena a 60° d'altezza, target a 45° d'altezza e 45° dalla Luna,
+	// k = 0.172. Calcolo a mano con le formule di Krisciunas & Schaefer:
+	//   I* = 10^(-0.4·3.84) = 0.02911
+	//   f(45°) = 10^5.36·(1.06 + 0.5) + 10^(6.15 - 45/40) = 449556
+	//   X(30°) = 1.1471, X(45°) = 1.3868
+	//   B_luna = 449556 · 0.02911 · 10^(-0.4·0.172·1.1471) · (1 - 10^(-0.4·0.172·1.3868)) = 2218 nL
+	//   B_cielo = 79.01 · 1.3868 · 10^(-0.4·0.172·0.3868) = 103.1 nL
+	//   Δm = 2.5·log10((2218 + 103.1)/103.1) = 3.38 mag
+	if got := moonSkyBrightening(60, 45, 45, 0, 0.172); math.Abs(got-3.38) > 0.01 {
+		t.Errorf("Luna piena: schiarimento %.3f mag, atteso 3.38", got)
+	}
+
+	// meno illuminata o più lontana, la Luna schiarisce meno
+	full := moonSkyBrightening(60, 45, 45, 0, 0.172)
+	quarter := moonSkyBrightening(60, 45, 45, 90, 0.172)
+	far := moonSkyBrightening(60, 45, 120, 0, 0.172)
+	if !(quarter < full && far < full && quarter > 0 && far > 0) {
+		t.Errorf("piena %.2f, quarto %.2f, lontana %.2f: attese piena > quarto, lontana > 0", full, quarter, far)
+	}
+}
+
+func TestMoonSkyBrighteningBelowHorizon(t *testing.T) {
+	if got := moonSkyBrightening(-5, 45, 45, 0, 0.172); got != 0 {
+		t.Errorf("Luna sotto l'orizzonte: schiarimento %.3f, atteso 0", got)
+	}
+	if got := moonSkyBrightening(30, -1, 45, 0, 0.172); got != 0 {
+		t.Errorf("target sotto l'orizzonte: schiarimento %.3f, atteso 0", got)
+	}
+
+	moon := models.MoonPosition{AltDeg: -10, TopoRADeg: 100, TopoDecDeg: 20, PhaseAngleDeg: 0}
+	mi := ComputeMoonInterference(moon, 110, 20, 50)
+	if mi.MoonUp || mi.SkyBrighteningMag != 0 {
+		t.Errorf("Luna tramontata: %+v, atteso nessun disturbo", mi)
+	}
+	if math.Abs(mi.SeparationDeg-9.396) > 0.01 {
+		t.Errorf("separazione %.3f°, attesa 9.396°", mi.SeparationDeg)
+	}
+}
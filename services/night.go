@@ -0,0 +1,134 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Visibilità notturna dei target
+// =======================
+
+// nightSampleStep è il passo di campionamento della notte
+const nightSampleStep = 5 * time.Minute
+
+// NightPlanner precalcola il tempo siderale lungo la finestra di buio, così
+// da valutare velocemente migliaia di target.
+type NightPlanner struct {
+	Window  models.NightWindow
+	lat     float64
+	times   []time.Time
+	lst     []float64             // gradi
+	horizon []models.HorizonPoint // orizzonte locale (vuoto = piatto)
+}
+
+// NewNightPlanner prepara la notte astronomica che comincia la sera di day.
+// Restituisce false se al sito non c'è buio astronomico.
+func NewNightPlanner(day time.Time, latDeg, lonDeg float64) (*NightPlanner, bool) {
+	start, end, ok := AstronomicalNight(day, latDeg, lonDeg)
+	if !ok {
+		return nil, false
+	}
+
+	np := &NightPlanner{
+		Window: models.NightWindow{Start: start, End: end},
+		lat:    latDeg,
+	}
+	for t := start; t.Before(end); t = t.Add(nightSampleStep) {
+		np.times = append(np.times, t)
+		np.lst = append(np.lst, LocalSiderealDeg(t, lonDeg))
+	}
+	// l'ultimo campione è sempre la fine del buio: chiude l'ultimo intervallo
+	np.times = append(np.times, end)
+	np.lst = append(np.lst, LocalSiderealDeg(end, lonDeg))
+	return np, true
+}
+
+// SetHorizon imposta il profilo d'orizzonte del sito: un target conta come
+// visibile solo sopra la soglia richiesta e sopra l'orizzonte locale.
+func (np *NightPlanner) SetHorizon(points []models.HorizonPoint) {
+	np.horizon = points
+}
+
+// NightDay restituisce il giorno la cui sera apre la notte che contiene t:
+// prima di mezzogiorno si considera ancora la notte iniziata il giorno prima.
+func NightDay(t time.Time) time.Time {
+	if t.Hour() < 12 {
+		return t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// Visibility calcola le ore sopra minAltDeg (e sopra l'orizzonte locale, vedi
+// SetHorizon) e l'altezza massima del target durante la finestra di buio.
+// Ogni campione sopra la soglia conta l'intervallo semiaperto [t_i, t_i+1)
+// fino al campione successivo.
+func (np *NightPlanner) Visibility(raDeg, decDeg, minAltDeg float64) models.NightVisibility {
+	var out models.NightVisibility
+	out.MaxAltDeg = -90
+
+	sinLat, cosLat := math.Sincos(np.lat * deg2rad)
+	sinDec, cosDec := math.Sincos(decDeg * deg2rad)
+	sinMin := math.Sin(minAltDeg * deg2rad)
+
+	var above time.Duration
+	for i, lst := range np.lst {
+		sinHA, cosHA := math.Sincos((lst - raDeg) * deg2rad)
+		sinAlt := sinLat*sinDec + cosLat*cosDec*cosHA
+
+		limit := sinMin
+		if len(np.horizon) > 0 {
+			// azimut da nord verso est, come EquatorialToHorizontal
+			az := math.Atan2(-cosDec*sinHA, sinDec*cosLat-cosDec*sinLat*cosHA) * rad2deg
+			if h := HorizonAltitude(np.horizon, normDeg(az)); h > minAltDeg {
+				limit = math.Sin(h * deg2rad)
+			}
+		}
+		if sinAlt >= limit && i+1 < len(np.times) {
+			above += np.times[i+1].Sub(np.times[i])
+		}
+		if alt := math.Asin(sinAlt) * rad2deg; alt > out.MaxAltDeg {
+			out.MaxAltDeg = alt
+			out.MaxAltTime = np.times[i]
+		}
+	}
+	out.HoursAbove = above.Hours()
+	return out
+}
+
+// FilterTonight seleziona i target sopra f.MinAltDeg (e sopra l'orizzonte
+// locale) durante il buio e con magnitudine/dimensioni entro i limiti,
+// ordinati per ore di visibilità
+// (a parità, per altezza massima). I target senza coordinate sono scartati;
+// con un filtro attivo sono scartati anche quelli senza magnitudine/dimensioni.
+func (np *NightPlanner) FilterTonight(targets []models.TargetObject, f models.TonightFilter) []models.TonightTarget {
+	var out []models.TonightTarget
+	for _, t := range targets {
+		if t.RADeg == nil || t.DecDeg == nil {
+			continue
+		}
+		if f.MaxMagnitude != 0 && (t.Magnitude == nil || *t.Magnitude > f.MaxMagnitude) {
+			continue
+		}
+		if f.MinSizeArcmin > 0 && (t.SizeMajor == nil || *t.SizeMajor < f.MinSizeArcmin) {
+			continue
+		}
+
+		v := np.Visibility(*t.RADeg, *t.DecDeg, f.MinAltDeg)
+		if v.HoursAbove <= 0 {
+			continue
+		}
+		out = append(out, models.TonightTarget{Target: t, NightVisibility: v})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].HoursAbove != out[j].HoursAbove {
+			return out[i].HoursAbove > out[j].HoursAbove
+		}
+		return out[i].MaxAltDeg > out[j].MaxAltDeg
+	})
+	return out
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// Sito di prova alle medie latitudini, notte d'autunno con buio astronomico.
+const (
+	nightTestLat = 45.0
+	nightTestLon = 9.0
+)
+
+func nightTestPlanner(t *testing.T) *NightPlanner {
+	t.Helper()
+	np, ok := NewNightPlanner(time.Date(2026, 10, 17, 18, 0, 0, 0, time.UTC), nightTestLat, nightTestLon)
+	if !ok {
+		t.Fatal("nessun buio astronomico al sito di prova")
+	}
+	return np
+}
+
+func TestVisibilityHoursAbove(t *testing.T) {
+	np := nightTestPlanner(t)
+	night := np.Window.End.Sub(np.Window.Start).Hours()
+
+	// il polo nord celeste è sempre a 45°: conta tutta la notte, non un campione in più
+	v := np.Visibility(0, 90, 30)
+	if math.Abs(v.HoursAbove-night) > 1e-9 {
+		t.Errorf("ore sopra 30° del polo = %.4f, attese %.4f (durata del buio)", v.HoursAbove, night)
+	}
+
+	// il polo sud celeste non sorge mai
+	if v := np.Visibility(0, -90, 0); v.HoursAbove != 0 {
+		t.Errorf("ore sopra l'orizzonte del polo sud = %.4f, attese 0", v.HoursAbove)
+	}
+}
+
+func TestVisibilityHorizon(t *testing.T) {
+	np := nightTestPlanner(t)
+	flat := np.Visibility(0, 90, 30).HoursAbove
+	equator := np.Visibility(0, 0, 20).HoursAbove
+	northWall := []models.HorizonPoint{{AzDeg: 0, AltDeg: 60}, {AzDeg: 30, AltDeg: 0}, {AzDeg: 330, AltDeg: 0}}
+
+	// un muro alto 60° solo verso nord nasconde il polo (a 45°, azimut 0)
+	np.SetHorizon(northWall)
+	if v := np.Visibility(0, 90, 30); v.HoursAbove != 0 {
+		t.Errorf("polo dietro il muro: %.4f ore, attese 0", v.HoursAbove)
+	}
+	// l'equatore celeste resta sopra l'orizzonte tra est e ovest, lontano dal muro
+	if got := np.Visibility(0, 0, 20).HoursAbove; equator == 0 || got != equator {
+		t.Errorf("equatore con muro a nord: %.4f ore, attese %.4f", got, equator)
+	}
+
+	// un orizzonte sotto la soglia richiesta non cambia nulla
+	np.SetHorizon([]models.HorizonPoint{{AzDeg: 0, AltDeg: 10}, {AzDeg: 180, AltDeg: 10}})
+	if got := np.Visibility(0, 90, 30).HoursAbove; got != flat {
+		t.Errorf("orizzonte a 10° con soglia 30°: %.4f ore, attese %.4f", got, flat)
+	}
+}
+
+func TestFilterTonightMagnitude(t *testing.T) {
+	np := nightTestPlanner(t)
+	pole := func(code string, mag *float64) models.TargetObject {
+		return models.TargetObject{Code: code, RADeg: models.FloatPtr(0), DecDeg: models.FloatPtr(89), Magnitude: mag}
+	}
+	targets := []models.TargetObject{
+		pole("zero", models.FloatPtr(0)), // magnitudine 0 valida (es. Vega), non "mancante"
+		pole("debole", models.FloatPtr(12)),
+		pole("ignota", nil),
+	}
+
+	tests := []struct {
+		name   string
+		maxMag float64
+		want   []string
+	}{
+		{"senza filtro", 0, []string{"zero", "debole", "ignota"}},
+		{"entro mag 10", 10, []string{"zero"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := np.FilterTonight(targets, models.TonightFilter{MinAltDeg: 20, MaxMagnitude: tt.maxMag})
+			var got []string
+			for _, tt := range list {
+				got = append(got, tt.Target.Code)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("target = %v, attesi %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("target = %v, attesi %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Generatore catalogo DSO (OpenNGC)
+// =======================
+
+// OpenNGCSource è la descrizione della sorgente scritta nel catalogo generato.
+const OpenNGCSource = "OpenNGC (NGC.csv + addendum.csv)"
+
+// OpenNGCRow è una riga del CSV OpenNGC indicizzata per nome di colonna.
+type OpenNGCRow map[string]string
+
+// first restituisce il primo valore non vuoto tra le colonne indicate.
+func (r OpenNGCRow) first(keys ...string) string {
+	for _, k := range keys {
+		if v := r[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ReadOpenNGCCSV legge un CSV OpenNGC (separatore ';', prima riga = intestazione).
+// Struttura attuale del CSV:
+//
+//	Name;Type;RA;Dec;Const;MajAx;MinAx;PosAng;B-Mag;V-Mag;J-Mag;H-Mag;K-Mag;SurfBr;Hubble;Pax;Pm-RA;Pm-Dec;RadVel;Redshift;Cstar U-Mag;Cstar B-Mag;Cstar V-Mag;M;NGC;IC;Cstar Names;Identifiers;Common names;NED notes;OpenNGC notes;Sources
+func ReadOpenNGCCSV(r io.Reader) ([]OpenNGCRow, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = ';'
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("intestazione CSV: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	var rows []OpenNGCRow
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(OpenNGCRow, len(header))
+		for i, h := range header {
+			if i < len(rec) {
+				row[h] = rec[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseFloatLoose: parsing float robusto, accetta anche virgole.
+func parseFloatLoose(value string) *float64 {
+	v := strings.TrimSpace(value)
+	if v == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", "."), 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// parseAngle converte una stringa di angolo in gradi.
+//   - Se contiene ':' o spazi → sessagesimale: RA = HH:MM:SS (×15), Dec = ±DD:MM:SS
+//   - Altrimenti prova come float già in gradi.
+func parseAngle(value string, isRA bool) *float64 {
+	v := strings.TrimSpace(value)
+	if v == "" {
+		return nil
+	}
+
+	if !strings.ContainsAny(v, ": ") {
+		return parseFloatLoose(v)
+	}
+
+	norm := strings.ReplaceAll(v, " ", ":")
+	sign := 1.0
+	if !isRA && (norm[0] == '+' || norm[0] == '-') {
+		if norm[0] == '-' {
+			sign = -1.0
+		}
+		norm = norm[1:]
+	}
+
+	parts := strings.Split(norm, ":")
+	var hms [3]float64
+	for i := 0; i < len(parts) && i < 3; i++ {
+		f, err := strconv.ParseFloat(parts[i], 64)
+		if err != nil {
+			return nil
+		}
+		hms[i] = f
+	}
+
+	var deg float64
+	if isRA {
+		deg = (hms[0] + hms[1]/60.0 + hms[2]/3600.0) * 15.0
+	} else {
+		if hms[0] < 0 {
+			hms[0] = -hms[0]
+		}
+		deg = sign * (hms[0] + hms[1]/60.0 + hms[2]/3600.0)
+	}
+	return &deg
+}
+
+func strPtrOrNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// BuildDsoObjects converte le righe OpenNGC (NGC.csv o addendum.csv) nel formato
+// di dso_catalog.json. Le righe senza coordinate vengono scartate e contate in skipped.
+func BuildDsoObjects(rows []OpenNGCRow, defaultCatalog string) (objects []models.DsoObject, skipped int) {
+	for _, row := range rows {
+		name := row.first("Name", "NAME", "name")
+		common := row.first("Common names", "Common name", "CommonName", "Common")
+		objType := row.first("Type", "TYPE")
+		constellation := row.first("Const", "CONST", "Constellation")
+
+		// RA/Dec: HH:MM:SS / ±DD:MM:SS, con fallback per colonne RAJ2000/DEJ2000/RAdeg/DEdeg
+		ra := parseAngle(row.first("RA", "RAJ2000", "RAdeg"), true)
+		dec := parseAngle(row.first("Dec", "DEJ2000", "DEdeg"), false)
+		if ra == nil || dec == nil {
+			skipped++
+			continue
+		}
+
+		// Magnitudine: preferisci V, fallback B
+		mag := parseFloatLoose(row.first("V-Mag", "Vmag", "m_V", "V_MAG"))
+		if mag == nil {
+			mag = parseFloatLoose(row.first("B-Mag", "Bmag", "m_B", "B_MAG"))
+		}
+
+		surfBr := parseFloatLoose(row.first("SurfBr", "SurfBr_V", "SurfaceBrightness"))
+		majAx := parseFloatLoose(row["MajAx"])
+		minAx := parseFloatLoose(row["MinAx"])
+
+		// Messier, se presente
+		messier := strings.TrimSpace(row.first("M", "Messier"))
+
+		var catalog, code string
+		var number *int
+		if messier != "" {
+			catalog = "Messier"
+			code = "M" + messier
+			if n, err := strconv.Atoi(messier); err == nil {
+				number = &n
+			}
+		} else {
+			catalog = defaultCatalog
+			code = strings.TrimSpace(name)
+			var digits strings.Builder
+			for _, ch := range code {
+				if ch >= '0' && ch <= '9' {
+					digits.WriteRune(ch)
+				}
+			}
+			if n, err := strconv.Atoi(digits.String()); err == nil {
+				number = &n
+			}
+		}
+
+		// ID interno: code (Mxx / NGCxxxx ecc) come chiave primaria
+		id := code
+		if id == "" {
+			id = strings.TrimSpace(name)
+		}
+		if id == "" {
+			id = "UNKNOWN"
+		}
+
+		display := common
+		if display == "" {
+			display = name
+		}
+		if display == "" {
+			display = code
+		}
+
+		objects = append(objects, models.DsoObject{
+			ID:                id,
+			Catalog:           catalog,
+			Code:              code,
+			Number:            number,
+			NGC:               strPtrOrNil(row["NGC"]),
+			IC:                strPtrOrNil(row["IC"]),
+			Name:              strings.TrimSpace(display),
+			Type:              objType,
+			Constellation:     strings.TrimSpace(constellation),
+			RADeg:             ra,
+			DecDeg:            dec,
+			Mag:               mag,
+			SurfaceBrightness: surfBr,
+			SizeMajor:         majAx,
+			SizeMinor:         minAx,
+			ImageURL:          nil, // placeholder per uso futuro
+		})
+	}
+	return objects, skipped
+}
+
+// GenerateDsoCatalog costruisce il catalogo completo da NGC.csv + addendum.csv.
+func GenerateDsoCatalog(ngcRows, addendumRows []OpenNGCRow, version int, now time.Time) (*models.DsoCatalog, DsoCatalogStats) {
+	ngcObjects, ngcSkipped := BuildDsoObjects(ngcRows, "NGC/IC")
+	addObjects, addSkipped := BuildDsoObjects(addendumRows, "Addendum")
+
+	all := append(ngcObjects, addObjects...)
+
+	cat := &models.DsoCatalog{
+		Version:     version,
+		GeneratedAt: now.UTC().Format("2006-01-02T15:04:05Z"),
+		Source:      OpenNGCSource,
+		ObjectCount: len(all),
+		Objects:     all,
+	}
+
+	stats := ComputeDsoCatalogStats(cat)
+	stats.RowsRead = len(ngcRows) + len(addendumRows)
+	stats.Skipped = ngcSkipped + addSkipped
+	return cat, stats
+}
+
+// =======================
+//  Statistiche catalogo
+// =======================
+
+// DsoCatalogStats riassume il contenuto di un catalogo generato.
+type DsoCatalogStats struct {
+	RowsRead      int
+	Skipped       int // righe senza coordinate
+	Objects       int
+	ByCatalog     map[string]int
+	ByType        map[string]int
+	WithMag       int
+	WithSize      int
+	WithSurfBr    int
+	WithNGC       int
+	WithIC        int
+	DuplicatedIDs []string
+}
+
+// ComputeDsoCatalogStats calcola le statistiche di cat.
+func ComputeDsoCatalogStats(cat *models.DsoCatalog) DsoCatalogStats {
+	st := DsoCatalogStats{
+		Objects:   len(cat.Objects),
+		ByCatalog: make(map[string]int),
+		ByType:    make(map[string]int),
+	}
+	seen := make(map[string]int)
+
+	for _, o := range cat.Objects {
+		st.ByCatalog[o.Catalog]++
+		st.ByType[o.Type]++
+		if o.Mag != nil {
+			st.WithMag++
+		}
+		if o.SizeMajor != nil {
+			st.WithSize++
+		}
+		if o.SurfaceBrightness != nil {
+			st.WithSurfBr++
+		}
+		if o.NGC != nil {
+			st.WithNGC++
+		}
+		if o.IC != nil {
+			st.WithIC++
+		}
+		seen[o.ID]++
+		if seen[o.ID] == 2 {
+			st.DuplicatedIDs = append(st.DuplicatedIDs, o.ID)
+		}
+	}
+	return st
+}
+
+// WriteReport scrive il report delle statistiche in formato leggibile.
+func (st DsoCatalogStats) WriteReport(w io.Writer) {
+	pct := func(n int) float64 {
+		if st.Objects == 0 {
+			return 0
+		}
+		return 100.0 * float64(n) / float64(st.Objects)
+	}
+
+	fmt.Fprintf(w, "Righe lette:            %d\n", st.RowsRead)
+	fmt.Fprintf(w, "Scartate (senza coord): %d\n", st.Skipped)
+	fmt.Fprintf(w, "Oggetti:                %d\n", st.Objects)
+	fmt.Fprintf(w, "Con magnitudine:        %d (%.1f%%)\n", st.WithMag, pct(st.WithMag))
+	fmt.Fprintf(w, "Con dimensioni:         %d (%.1f%%)\n", st.WithSize, pct(st.WithSize))
+	fmt.Fprintf(w, "Con lum. superficiale:  %d (%.1f%%)\n", st.WithSurfBr, pct(st.WithSurfBr))
+	fmt.Fprintf(w, "Con cross-id NGC:       %d\n", st.WithNGC)
+	fmt.Fprintf(w, "Con cross-id IC:        %d\n", st.WithIC)
+	if len(st.DuplicatedIDs) > 0 {
+		fmt.Fprintf(w, "ID duplicati:           %d (es. %s)\n", len(st.DuplicatedIDs), st.DuplicatedIDs[0])
+	}
+
+	writeCounts := func(title string, m map[string]int) {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if m[keys[i]] != m[keys[j]] {
+				return m[keys[i]] > m[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+		fmt.Fprintf(w, "\n%s:\n", title)
+		for _, k := range keys {
+			label := k
+			if label == "" {
+				label = "(vuoto)"
+			}
+			fmt.Fprintf(w, "  %-10s %6d\n", label, m[k])
+		}
+	}
+	writeCounts("Per catalogo", st.ByCatalog)
+	writeCounts("Per tipo", st.ByType)
+}
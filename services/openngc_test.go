@@ -0,0 +1,264 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Intestazione ridotta del CSV OpenNGC (solo le colonne lette dal generatore).
+const openNGCHeader = "Name;Type;RA;Dec;Const;MajAx;MinAx;PosAng;B-Mag;V-Mag;SurfBr;M;NGC;IC;Common names\n"
+
+func TestReadOpenNGCCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		rows    int
+		wantErr bool
+		check   func(t *testing.T, rows []OpenNGCRow)
+	}{
+		{
+			name: "intestazione con BOM",
+			csv:  "\ufeff" + openNGCHeader + "NGC0224;G;00:42:44.35;+41:16:08.6;And;177.83;69.66;35;4.29;3.44;23.63;031;;;Andromeda Galaxy\n",
+			rows: 1,
+			check: func(t *testing.T, rows []OpenNGCRow) {
+				if got := rows[0]["Name"]; got != "NGC0224" {
+					t.Errorf("Name = %q, la BOM non è stata rimossa dall'intestazione", got)
+				}
+			},
+		},
+		{
+			name: "solo intestazione",
+			csv:  openNGCHeader,
+			rows: 0,
+		},
+		{
+			name: "riga corta",
+			csv:  openNGCHeader + "NGC0001;G;00:07:15.84;+27:42:29.1\n",
+			rows: 1,
+			check: func(t *testing.T, rows []OpenNGCRow) {
+				if _, ok := rows[0]["Const"]; ok {
+					t.Errorf("colonna Const presente in una riga che non la contiene")
+				}
+			},
+		},
+		{
+			name:    "file vuoto",
+			csv:     "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadOpenNGCCSV(strings.NewReader(tt.csv))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("errore = %v, atteso errore: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(rows) != tt.rows {
+				t.Fatalf("righe = %d, attese %d", len(rows), tt.rows)
+			}
+			if tt.check != nil {
+				tt.check(t, rows)
+			}
+		})
+	}
+}
+
+func TestParseAngle(t *testing.T) {
+	tests := []struct {
+		in   string
+		isRA bool
+		want float64 // NaN = rifiutato
+	}{
+		{"00:42:44.35", true, (0 + 42/60.0 + 44.35/3600) * 15},
+		{"05 34 31.94", true, (5 + 34/60.0 + 31.94/3600) * 15},
+		{"+41:16:08.6", false, 41 + 16/60.0 + 8.6/3600},
+		{"-05:23:28", false, -(5 + 23/60.0 + 28/3600.0)},
+		{"-00:30:00", false, -0.5}, // il segno vale anche con i gradi a zero
+		{"83.63", true, 83.63},
+		{"12,5", false, 12.5},
+		{"", true, math.NaN()},
+		{"12:xx:00", true, math.NaN()},
+		{"abc", false, math.NaN()},
+	}
+	for _, tt := range tests {
+		got := parseAngle(tt.in, tt.isRA)
+		if math.IsNaN(tt.want) {
+			if got != nil {
+				t.Errorf("parseAngle(%q) = %v, atteso nil", tt.in, *got)
+			}
+			continue
+		}
+		if got == nil {
+			t.Errorf("parseAngle(%q) = nil, atteso %v", tt.in, tt.want)
+			continue
+		}
+		if math.Abs(*got-tt.want) > 1e-9 {
+			t.Errorf("parseAngle(%q) = %v, atteso %v", tt.in, *got, tt.want)
+		}
+	}
+}
+
+func TestBuildDsoObjects(t *testing.T) {
+	ptr := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name        string
+		rows        string // righe dopo l'intestazione
+		catalog     string
+		wantSkipped int
+		want        []dsoWant
+	}{
+		{
+			name:    "Messier con magnitudine V",
+			rows:    "NGC0224;G;00:42:44.35;+41:16:08.6;And;177.83;69.66;35;4.29;3.44;23.63;031;;;Andromeda Galaxy\n",
+			catalog: "NGC/IC",
+			want: []dsoWant{{
+				// il codice conserva lo zero-padding della colonna M (come lo script Python)
+				id: "M031", catalog: "Messier", number: 31, name: "Andromeda Galaxy",
+				mag: ptr(3.44), sizeMajor: ptr(177.83), constellation: "And",
+			}},
+		},
+		{
+			name: "magnitudini mancanti",
+			rows: "NGC0002;G;00:07:17.10;+27:40:42.2;Peg;0.93;0.47;110;15.33;;;;;;\n" +
+				"NGC0006;G;00:09:32.69;+33:18:31.5;And;1.2;0.8;;;;;;;;\n",
+			catalog: "NGC/IC",
+			want: []dsoWant{
+				{id: "NGC0002", catalog: "NGC/IC", number: 2, name: "NGC0002", mag: ptr(15.33), sizeMajor: ptr(0.93), constellation: "Peg"},
+				{id: "NGC0006", catalog: "NGC/IC", number: 6, name: "NGC0006", sizeMajor: ptr(1.2), constellation: "And"},
+			},
+		},
+		{
+			name: "RA/Dec non validi",
+			rows: "NGC0001;G;00:07:15.84;+27:42:29.1;Peg;1.57;1.07;112;13.69;12.93;23.13;;;;\n" +
+				"NGC0003;G;xx:07:16.80;+08:18:05.9;Psc;;;;;;;;;;\n" +
+				"NGC0004;G;00:07:24.46;;Psc;;;;;;;;;;\n" +
+				"IC0001;**;;;Peg;;;;;;;;;;\n",
+			catalog:     "NGC/IC",
+			wantSkipped: 3,
+			want: []dsoWant{
+				{id: "NGC0001", catalog: "NGC/IC", number: 1, name: "NGC0001", mag: ptr(12.93), sizeMajor: ptr(1.57), constellation: "Peg"},
+			},
+		},
+		{
+			name: "duplicati e alias",
+			// NGC5866 è a volte identificata con M102: due righe con lo stesso M;
+			// le righe "Dup" di OpenNGC non hanno coordinate e vengono scartate
+			rows: "NGC5457;G;14:03:12.58;+54:20:55.5;UMa;23.99;23.07;;8.31;;23.94;101;;;Pinwheel Galaxy\n" +
+				"NGC5866;G;15:06:29.56;+55:45:47.9;Dra;4.68;1.91;128;10.74;9.89;22.40;102;;;Spindle Galaxy\n" +
+				"NGC5457A;G;14:03:12.58;+54:20:55.5;UMa;;;;;;;102;;;\n" +
+				"NGC5458;Dup;;;;;;;;;;;5457;;\n",
+			catalog:     "NGC/IC",
+			wantSkipped: 1,
+			want: []dsoWant{
+				{id: "M101", catalog: "Messier", number: 101, name: "Pinwheel Galaxy", mag: ptr(8.31), sizeMajor: ptr(23.99), constellation: "UMa"},
+				{id: "M102", catalog: "Messier", number: 102, name: "Spindle Galaxy", mag: ptr(9.89), sizeMajor: ptr(4.68), constellation: "Dra"},
+				{id: "M102", catalog: "Messier", number: 102, name: "NGC5457A", constellation: "UMa"},
+			},
+		},
+		{
+			name:    "addendum",
+			rows:    "Mel022;OCl;03:47:28.6;+24:06:19;Tau;;;;;1.6;;;;;Pleiades\n",
+			catalog: "Addendum",
+			want: []dsoWant{
+				{id: "Mel022", catalog: "Addendum", number: 22, name: "Pleiades", mag: ptr(1.6), constellation: "Tau"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadOpenNGCCSV(strings.NewReader(openNGCHeader + tt.rows))
+			if err != nil {
+				t.Fatal(err)
+			}
+			objects, skipped := BuildDsoObjects(rows, tt.catalog)
+			if skipped != tt.wantSkipped {
+				t.Errorf("scartate = %d, attese %d", skipped, tt.wantSkipped)
+			}
+			if len(objects) != len(tt.want) {
+				t.Fatalf("oggetti = %d, attesi %d", len(objects), len(tt.want))
+			}
+			for i, w := range tt.want {
+				o := objects[i]
+				if o.ID != w.id || o.Catalog != w.catalog || o.Name != w.name || o.Constellation != w.constellation {
+					t.Errorf("oggetto %d = {%s %s %q %s}, atteso {%s %s %q %s}",
+						i, o.ID, o.Catalog, o.Name, o.Constellation, w.id, w.catalog, w.name, w.constellation)
+				}
+				if o.Number == nil || *o.Number != w.number {
+					t.Errorf("%s: numero = %v, atteso %d", w.id, o.Number, w.number)
+				}
+				if !reflect.DeepEqual(o.Mag, w.mag) {
+					t.Errorf("%s: magnitudine = %v, attesa %v", w.id, deref(o.Mag), deref(w.mag))
+				}
+				if !reflect.DeepEqual(o.SizeMajor, w.sizeMajor) {
+					t.Errorf("%s: asse maggiore = %v, atteso %v", w.id, deref(o.SizeMajor), deref(w.sizeMajor))
+				}
+			}
+		})
+	}
+}
+
+// dsoWant sono i campi controllati per ogni oggetto generato.
+type dsoWant struct {
+	id, catalog, name, constellation string
+	number                           int
+	mag, sizeMajor                   *float64
+}
+
+func deref(f *float64) any {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
+
+func TestGenerateDsoCatalogStats(t *testing.T) {
+	ngc, err := ReadOpenNGCCSV(strings.NewReader(openNGCHeader +
+		"NGC5457;G;14:03:12.58;+54:20:55.5;UMa;23.99;23.07;;8.31;;23.94;101;;;Pinwheel Galaxy\n" +
+		"NGC5866;G;15:06:29.56;+55:45:47.9;Dra;4.68;1.91;128;10.74;9.89;22.40;102;;;Spindle Galaxy\n" +
+		"NGC5457A;G;14:03:12.58;+54:20:55.5;UMa;;;;;;;102;;;\n" +
+		"NGC5458;Dup;;;;;;;;;;;5457;;\n" +
+		"IC0434;Neb;05:41:00.88;-02:27:13.6;Ori;60;10;;;;;;;;Horsehead Nebula\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	add, err := ReadOpenNGCCSV(strings.NewReader(openNGCHeader +
+		"Mel022;OCl;03:47:28.6;+24:06:19;Tau;;;;;1.6;;;;;Pleiades\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	cat, st := GenerateDsoCatalog(ngc, add, 3, now)
+
+	if cat.Version != 3 || cat.GeneratedAt != "2026-10-17T10:00:00Z" || cat.ObjectCount != 5 {
+		t.Errorf("intestazione catalogo = v%d %s %d oggetti", cat.Version, cat.GeneratedAt, cat.ObjectCount)
+	}
+	if st.RowsRead != 6 || st.Skipped != 1 || st.Objects != 5 {
+		t.Errorf("righe/scartate/oggetti = %d/%d/%d, attesi 6/1/5", st.RowsRead, st.Skipped, st.Objects)
+	}
+	wantCatalogs := map[string]int{"Messier": 3, "NGC/IC": 1, "Addendum": 1}
+	if !reflect.DeepEqual(st.ByCatalog, wantCatalogs) {
+		t.Errorf("per catalogo = %v, atteso %v", st.ByCatalog, wantCatalogs)
+	}
+	if st.WithMag != 3 || st.WithSize != 3 || st.WithSurfBr != 2 {
+		t.Errorf("con mag/dimensioni/lum. superficiale = %d/%d/%d, attesi 3/3/2", st.WithMag, st.WithSize, st.WithSurfBr)
+	}
+	if !reflect.DeepEqual(st.DuplicatedIDs, []string{"M102"}) {
+		t.Errorf("ID duplicati = %v, atteso [M102]", st.DuplicatedIDs)
+	}
+
+	var report strings.Builder
+	st.WriteReport(&report)
+	if !strings.Contains(report.String(), "ID duplicati:           1 (es. M102)") {
+		t.Errorf("il report non segnala il duplicato:\n%s", report.String())
+	}
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  OVATION: probabilità di aurora (NOAA SWPC)
+// =======================
+
+// ovationURL è l'ultima previsione a breve termine (30-90 minuti) del modello
+// OVATION: griglia lon 0..359 × lat -90..90 a passo di 1° con la probabilità
+// di aurora in percentuale.
+const ovationURL = "https://services.swpc.noaa.gov/json/ovation_aurora_latest.json"
+
+func init() {
+	SetCacheTTL(ovationURL, 5*time.Minute)
+}
+
+// Dimensioni della griglia OVATION
+const (
+	ovationLons = 360
+	ovationLats = 181
+)
+
+// Visibilità a distanza: l'aurora si estende fino a ~200 km di quota, quindi
+// si vede anche da centinaia di km, purché sia almeno qualche grado sopra
+// l'orizzonte. Sotto auroraFullElevDeg la probabilità conta in proporzione
+// all'altezza (bassa sull'orizzonte è più facile che la nascondano foschia
+// e ostacoli).
+const (
+	auroraTopKm       = 200.0
+	auroraMinElevDeg  = 5.0
+	auroraFullElevDeg = 15.0
+	auroraSearchLat   = 20 // gradi di latitudine esplorati attorno al sito
+	auroraSearchLon   = 40 // gradi di longitudine esplorati per lato (alle alte latitudini)
+	ovationEarthRadKm = 6371.0
+)
+
+// OvationGrid è la griglia delle probabilità di aurora (0-100).
+type OvationGrid struct {
+	ObservationTime time.Time
+	ForecastTime    time.Time
+	prob            [ovationLons][ovationLats]float32
+}
+
+// ovationResponse è il JSON pubblicato da SWPC
+type ovationResponse struct {
+	ObservationTime string      `json:"Observation Time"`
+	ForecastTime    string      `json:"Forecast Time"`
+	Coordinates     [][]float64 `json:"coordinates"` // [lon, lat, probabilità]
+}
+
+// FetchOvation scarica l'ultima griglia OVATION.
+// Va chiamata fuori dal goroutine UI.
+func FetchOvation() (*OvationGrid, error) {
+	cd, err := FetchCached(ovationURL)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOvation(cd.Data)
+}
+
+// ParseOvation legge il JSON OVATION. Le longitudini negative (-180..180)
+// vengono riportate a 0..359.
+func ParseOvation(data []byte) (*OvationGrid, error) {
+	var resp ovationResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("formato OVATION non riconosciuto: %w", err)
+	}
+	if len(resp.Coordinates) == 0 {
+		return nil, errors.New("griglia OVATION vuota")
+	}
+
+	g := &OvationGrid{}
+	g.ObservationTime, _ = swpcTime(resp.ObservationTime)
+	g.ForecastTime, _ = swpcTime(resp.ForecastTime)
+
+	n := 0
+	for _, c := range resp.Coordinates {
+		if len(c) < 3 {
+			continue
+		}
+		lon := int(math.Round(c[0]))
+		lat := int(math.Round(c[1]))
+		if lat < -90 || lat > 90 {
+			continue
+		}
+		lon = ((lon % 360) + 360) % 360
+		g.prob[lon][lat+90] = float32(math.Max(0, math.Min(100, c[2])))
+		n++
+	}
+	if n == 0 {
+		return nil, errors.New("griglia OVATION senza valori validi")
+	}
+	return g, nil
+}
+
+// At restituisce la probabilità (%) interpolata bilinearmente in lat/lon.
+func (g *OvationGrid) At(latDeg, lonDeg float64) float64 {
+	lat := math.Max(-90, math.Min(90, latDeg)) + 90
+	lon := math.Mod(lonDeg, 360)
+	if lon < 0 {
+		lon += 360
+	}
+
+	i0 := int(math.Floor(lon))
+	j0 := int(math.Floor(lat))
+	if j0 >= ovationLats-1 {
+		j0 = ovationLats - 2
+	}
+	fx, fy := lon-float64(i0), lat-float64(j0)
+	i0 %= ovationLons
+	i1 := (i0 + 1) % ovationLons // la longitudine si richiude a 360°
+
+	v00 := float64(g.prob[i0][j0])
+	v10 := float64(g.prob[i1][j0])
+	v01 := float64(g.prob[i0][j0+1])
+	v11 := float64(g.prob[i1][j0+1])
+	return (v00*(1-fx)+v10*fx)*(1-fy) + (v01*(1-fx)+v11*fx)*fy
+}
+
+// OvationAtSite restituisce la probabilità allo zenit del sito e il punto
+// della griglia più favorevole fra quelli visibili dal sito: di solito verso
+// il polo, ma per i siti oltre l'ovale anche verso l'equatore. La probabilità
+// "visibile" è quella del punto pesata con la sua altezza sull'orizzonte.
+func OvationAtSite(g *OvationGrid, latDeg, lonDeg float64) models.AuroraProbability {
+	p := models.AuroraProbability{
+		ObservationTime: g.ObservationTime,
+		ForecastTime:    g.ForecastTime,
+		SiteProb:        g.At(latDeg, lonDeg),
+	}
+	p.VisibleProb = p.SiteProb
+	p.VisibleLat, p.VisibleLon = latDeg, lonDeg
+	p.VisibleElevDeg = 90
+
+	lat0 := int(math.Round(latDeg))
+	lon0 := int(math.Round(lonDeg))
+	lonSpan := int(math.Min(180, auroraSearchLon/math.Max(0.25, math.Cos(latDeg*deg2rad))))
+
+	for lat := max(-90, lat0-auroraSearchLat); lat <= min(90, lat0+auroraSearchLat); lat++ {
+		for di := -lonSpan; di <= lonSpan; di++ {
+			lon := lon0 + di
+			v := float64(g.prob[((lon%360)+360)%360][lat+90])
+			if v <= p.VisibleProb {
+				continue
+			}
+			dist, az := greatCircle(latDeg, lonDeg, float64(lat), float64(lon))
+			elev := auroraElevation(dist)
+			if elev < auroraMinElevDeg {
+				continue
+			}
+			if v *= math.Min(1, elev/auroraFullElevDeg); v <= p.VisibleProb {
+				continue
+			}
+			p.VisibleProb = v
+			p.VisibleLat, p.VisibleLon = float64(lat), normalizeLon(float64(lon))
+			p.VisibleDistKm, p.VisibleAzDeg, p.VisibleElevDeg = dist, az, elev
+		}
+	}
+	return p
+}
+
+// auroraElevation è l'altezza apparente (gradi) della sommità dell'aurora
+// (auroraTopKm) vista a distanza distKm lungo la superficie.
+func auroraElevation(distKm float64) float64 {
+	theta := distKm / ovationEarthRadKm
+	if theta < 1e-6 {
+		return 90
+	}
+	r := ovationEarthRadKm / (ovationEarthRadKm + auroraTopKm)
+	return math.Atan2(math.Cos(theta)-r, math.Sin(theta)) * rad2deg
+}
+
+// greatCircle restituisce distanza (km) e azimut iniziale (0 = nord) fra due punti.
+func greatCircle(lat1, lon1, lat2, lon2 float64) (distKm, azDeg float64) {
+	p1, p2 := lat1*deg2rad, lat2*deg2rad
+	dl := (lon2 - lon1) * deg2rad
+	a := math.Sin((p2-p1)/2)*math.Sin((p2-p1)/2) + math.Cos(p1)*math.Cos(p2)*math.Sin(dl/2)*math.Sin(dl/2)
+	distKm = 2 * ovationEarthRadKm * math.Asin(math.Min(1, math.Sqrt(a)))
+	y := math.Sin(dl) * math.Cos(p2)
+	x := math.Cos(p1)*math.Sin(p2) - math.Sin(p1)*math.Cos(p2)*math.Cos(dl)
+	azDeg = math.Mod(math.Atan2(y, x)*rad2deg+360, 360)
+	return distKm, azDeg
+}
+
+// normalizeLon riporta una longitudine in -180..180.
+func normalizeLon(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
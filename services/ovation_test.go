@@ -0,0 +1,81 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOvationSeam(t *testing.T) {
+	// longitudini -180..180 come nel feed: -1 diventa 359
+	g, err := ParseOvation([]byte(`{
+		"Observation Time": "2026-10-17T21:05:00Z",
+		"Forecast Time": "2026-10-17T21:50:00Z",
+		"coordinates": [[-1, 60, 10], [0, 60, 30], [-1, 61, 50], [0, 61, 70], [200, 95, 99]]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.prob[359][60+90]; got != 10 {
+		t.Errorf("cella a lon -1: %v in 359, attesa 10", got)
+	}
+
+	tests := []struct {
+		lat, lon, want float64
+	}{
+		{60, 359, 10},
+		{60, 359.5, 20},   // a metà fra 359 e 0
+		{60, -0.5, 20},    // la stessa longitudine scritta in negativo
+		{60.5, 359.5, 40}, // bilineare sulle quattro celle attorno alla cucitura
+		{60.25, 359.75, 35},
+	}
+	for _, tt := range tests {
+		if got := g.At(tt.lat, tt.lon); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("At(%v, %v) = %.4f, atteso %.4f", tt.lat, tt.lon, got, tt.want)
+		}
+	}
+	if g.ForecastTime.Sub(g.ObservationTime).Minutes() != 45 {
+		t.Errorf("orari %v / %v", g.ObservationTime, g.ForecastTime)
+	}
+}
+
+func TestAuroraElevation(t *testing.T) {
+	if got := auroraElevation(0); got != 90 {
+		t.Errorf("allo zenit: %.2f°, attesi 90°", got)
+	}
+	// la sommità a 200 km scende a 5° sull'orizzonte poco oltre i 1000 km (~1110 km)
+	if e := auroraElevation(1000); e <= auroraMinElevDeg {
+		t.Errorf("a 1000 km: %.2f°, attesa sopra 5°", e)
+	}
+	if e := auroraElevation(1200); e >= auroraMinElevDeg {
+		t.Errorf("a 1200 km: %.2f°, attesa sotto 5°", e)
+	}
+	if a, b := auroraElevation(300), auroraElevation(600); !(a > b) {
+		t.Errorf("altezza non decrescente con la distanza: %.2f° a 300 km, %.2f° a 600 km", a, b)
+	}
+}
+
+func TestOvationAtSite(t *testing.T) {
+	// una sola cella accesa 6° più a nord del sito
+	g, err := ParseOvation([]byte(`{"coordinates": [[10, 66, 80]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := OvationAtSite(g, 60, 10)
+
+	wantDist := 6 * deg2rad * ovationEarthRadKm
+	wantElev := auroraElevation(wantDist)
+	if p.SiteProb != 0 || p.VisibleLat != 66 || p.VisibleLon != 10 {
+		t.Fatalf("punto visibile %+v, attesa la cella a 66°N 10°E", p)
+	}
+	if math.Abs(p.VisibleDistKm-wantDist) > 0.1 || angleDiff(p.VisibleAzDeg, 0) > 1e-6 {
+		t.Errorf("distanza %.1f km, azimut %.2f°; attesi %.1f km verso nord", p.VisibleDistKm, p.VisibleAzDeg, wantDist)
+	}
+	if want := 80 * wantElev / auroraFullElevDeg; math.Abs(p.VisibleElevDeg-wantElev) > 1e-9 || math.Abs(p.VisibleProb-want) > 1e-4 {
+		t.Errorf("altezza %.2f°, probabilità %.2f%%; attese %.2f° e %.2f%%", p.VisibleElevDeg, p.VisibleProb, wantElev, want)
+	}
+
+	// troppo lontana per essere sopra l'orizzonte: resta solo lo zenit
+	if p := OvationAtSite(g, 50, 10); p.VisibleProb != 0 || p.VisibleElevDeg != 90 {
+		t.Errorf("da 16° di distanza: %+v, attesa nessuna aurora visibile", p)
+	}
+}
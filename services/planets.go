@@ -0,0 +1,304 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Pianeti: posizioni apparenti da VSOP87
+// =======================
+//
+// Posizioni eliocentriche dalle serie VSOP87 troncate (vedi vsop87.go),
+// geocentriche con il tempo luce, poi correzione al sistema FK5, nutazione e
+// aberrazione annua come nel cap. 33 di Meeus. Sufficiente per schemi,
+// pianificazione e satelliti, non per occultazioni o astrometria.
+
+// planetDef descrive un pianeta: serie VSOP87, semidiametro equatoriale a 1 UA
+// (secondi d'arco, Meeus cap. 55) e magnitudine assoluta con il coefficiente
+// di fase lineare dell'Astronomical Almanac (Meeus cap. 41). Mercurio, Venere
+// e Saturno hanno termini in più, vedi planetMagnitude.
+type planetDef struct {
+	name      string
+	series    *vsopSeries
+	semidiam  float64
+	absMag    float64
+	phaseCoef float64
+}
+
+var planets = []planetDef{
+	{"Mercurio", mercuryVSOP, 3.36, -0.42, 0.0380},
+	{"Venere", venusVSOP, 8.41, -4.40, 0.0009},
+	{"Marte", marsVSOP, 4.68, -1.52, 0.016},
+	{"Giove", jupiterVSOP, 98.44, -9.40, 0.005},
+	{"Saturno", saturnVSOP, 82.73, -8.88, 0},
+	{"Urano", uranusVSOP, 35.02, -7.19, 0},
+	{"Nettuno", neptuneVSOP, 33.50, -6.87, 0},
+}
+
+// PlanetNames elenca i pianeti gestiti, dal Sole verso l'esterno.
+func PlanetNames() []string {
+	names := make([]string, len(planets))
+	for i, p := range planets {
+		names[i] = p.name
+	}
+	return names
+}
+
+// aberrationConst è la costante dell'aberrazione annua (gradi)
+const aberrationConst = 20.49552 / 3600.0
+
+// obliquityJ2000 è l'obliquità dell'eclittica all'epoca J2000 (gradi)
+const obliquityJ2000 = 23.43928
+
+// lightDaysPerAU è il tempo luce per unità astronomica (giorni)
+const lightDaysPerAU = 0.0057755183
+
+// vec3 è un vettore cartesiano
+type vec3 struct{ x, y, z float64 }
+
+func (v vec3) sub(o vec3) vec3      { return vec3{v.x - o.x, v.y - o.y, v.z - o.z} }
+func (v vec3) scale(k float64) vec3 { return vec3{v.x * k, v.y * k, v.z * k} }
+func (v vec3) norm() float64        { return math.Sqrt(v.x*v.x + v.y*v.y + v.z*v.z) }
+func (v vec3) dot(o vec3) float64   { return v.x*o.x + v.y*o.y + v.z*o.z }
+
+// sphericalToVec converte longitudine, latitudine (gradi) e distanza in un
+// vettore cartesiano.
+func sphericalToVec(lonDeg, latDeg, r float64) vec3 {
+	return vec3{
+		r * cosDeg(latDeg) * cosDeg(lonDeg),
+		r * cosDeg(latDeg) * sinDeg(lonDeg),
+		r * sinDeg(latDeg),
+	}
+}
+
+// vecToSpherical è l'inversa di sphericalToVec.
+func vecToSpherical(v vec3) (lonDeg, latDeg, r float64) {
+	r = v.norm()
+	return normDeg(math.Atan2(v.y, v.x) * rad2deg), math.Asin(v.z/r) * rad2deg, r
+}
+
+// precessEcliptic porta longitudine e latitudine eclittiche dall'equinozio
+// jdFrom a jdTo (Meeus, formula 21.5).
+func precessEcliptic(lambdaDeg, betaDeg, jdFrom, jdTo float64) (float64, float64) {
+	T := (jdFrom - jdJ2000) / 36525.0
+	t := (jdTo - jdFrom) / 36525.0
+	eta := ((47.0029-0.06603*T+0.000598*T*T)*t + (-0.03302+0.000598*T)*t*t + 0.000060*t*t*t) / 3600.0
+	pi := 174.876384 + (3289.4789*T+0.60622*T*T-(869.8089+0.50491*T)*t+0.03536*t*t)/3600.0
+	p := ((5029.0966+2.22226*T-0.000042*T*T)*t + (1.11113-0.000042*T)*t*t - 0.000006*t*t*t) / 3600.0
+
+	a := cosDeg(eta)*cosDeg(betaDeg)*sinDeg(pi-lambdaDeg) - sinDeg(eta)*sinDeg(betaDeg)
+	b := cosDeg(betaDeg) * cosDeg(pi-lambdaDeg)
+	c := cosDeg(eta)*sinDeg(betaDeg) + sinDeg(eta)*cosDeg(betaDeg)*sinDeg(pi-lambdaDeg)
+	return normDeg(p + pi - math.Atan2(a, b)*rad2deg), math.Asin(c) * rad2deg
+}
+
+// precessVec applica precessEcliptic a un vettore eclittico.
+func precessVec(v vec3, jdFrom, jdTo float64) vec3 {
+	lon, lat, r := vecToSpherical(v)
+	lon, lat = precessEcliptic(lon, lat, jdFrom, jdTo)
+	return sphericalToVec(lon, lat, r)
+}
+
+// eclipticToEquatorialVec ruota un vettore eclittico J2000 in equatoriale J2000.
+func eclipticToEquatorialVec(v vec3) vec3 {
+	c, s := math.Cos(obliquityJ2000*deg2rad), math.Sin(obliquityJ2000*deg2rad)
+	return vec3{v.x, c*v.y - s*v.z, s*v.y + c*v.z}
+}
+
+// equatorialToEclipticVec è l'inversa di eclipticToEquatorialVec.
+func equatorialToEclipticVec(v vec3) vec3 {
+	c, s := math.Cos(obliquityJ2000*deg2rad), math.Sin(obliquityJ2000*deg2rad)
+	return vec3{v.x, c*v.y + s*v.z, -s*v.y + c*v.z}
+}
+
+// vecToRADec converte un vettore equatoriale in RA/Dec (gradi) e distanza.
+func vecToRADec(v vec3) (raDeg, decDeg, dist float64) {
+	dist = v.norm()
+	raDeg = normDeg(math.Atan2(v.y, v.x) * rad2deg)
+	decDeg = math.Asin(v.z/dist) * rad2deg
+	return raDeg, decDeg, dist
+}
+
+// heliocentricVec restituisce la posizione eliocentrica eclittica della data
+// (UA) al giorno giuliano (TT) jde.
+func (s *vsopSeries) heliocentricVec(jde float64) vec3 {
+	return sphericalToVec(s.heliocentric(jde))
+}
+
+// geocentricPlanet restituisce il vettore geocentrico eclittico J2000 (UA)
+// del pianeta, corretto per il tempo luce, e l'istante (TT, giorno giuliano)
+// in cui la luce ha lasciato il pianeta.
+func geocentricPlanet(s *vsopSeries, t time.Time) (vec3, float64) {
+	geo, _, emitted := planetVectors(s, t)
+	return precessVec(geo, JulianDay(t.Add(deltaT(t))), jdJ2000), emitted
+}
+
+// planetVectors restituisce i vettori geocentrico ed eliocentrico (eclittica
+// e equinozio della data, UA, al tempo luce) e l'istante di emissione.
+func planetVectors(s *vsopSeries, t time.Time) (geo, helio vec3, emitted float64) {
+	jde := JulianDay(t.Add(deltaT(t)))
+	earth := earthVSOP.heliocentricVec(jde)
+
+	emitted = jde
+	for i := 0; i < 3; i++ {
+		helio = s.heliocentricVec(emitted)
+		geo = helio.sub(earth)
+		emitted = jde - geo.norm()*lightDaysPerAU
+	}
+	return geo, helio, emitted
+}
+
+// apparentPlace porta un vettore geocentrico eclittico della data alle
+// coordinate equatoriali apparenti: correzione al sistema FK5, nutazione e
+// aberrazione annua con i termini dell'eccentricità (Meeus, capp. 23 e 32).
+// earth è il vettore eliocentrico della Terra all'istante jde.
+func apparentPlace(geo, earth vec3, jde float64) (raDeg, decDeg float64) {
+	T := julianCenturies(jde)
+	lambda, beta, _ := vecToSpherical(geo)
+
+	// dall'eclittica dinamica di VSOP87 al sistema FK5 (32.3)
+	lp := lambda - 1.397*T - 0.00031*T*T
+	lambda += (-0.09033 + 0.03916*(cosDeg(lp)+sinDeg(lp))*math.Tan(beta*deg2rad)) / 3600.0
+	beta += 0.03916 * (cosDeg(lp) - sinDeg(lp)) / 3600.0
+
+	dPsi, dEps := nutation(T)
+	lambda += dPsi
+
+	// aberrazione: longitudine vera del Sole, eccentricità e perielio terrestri
+	sunLon := normDeg(math.Atan2(-earth.y, -earth.x) * rad2deg)
+	e := 0.016708634 - 0.000042037*T
+	peri := 102.93735 + 1.71946*T
+	dLambda := aberrationConst * (-cosDeg(sunLon-lambda) + e*cosDeg(peri-lambda)) / cosDeg(beta)
+	dBeta := -aberrationConst * sinDeg(beta) * (sinDeg(sunLon-lambda) - e*sinDeg(peri-lambda))
+
+	return eclipticToEquatorial(normDeg(lambda+dLambda), beta+dBeta, meanObliquity(T)+dEps)
+}
+
+// ComputePlanetPosition calcola l'efemeride geocentrica del pianeta name
+// (nome italiano, vedi PlanetNames). Restituisce false se il nome non è noto.
+func ComputePlanetPosition(name string, t time.Time) (models.PlanetPosition, bool) {
+	for _, p := range planets {
+		if p.name == name {
+			return p.position(t), true
+		}
+	}
+	return models.PlanetPosition{}, false
+}
+
+// ComputePlanets calcola l'efemeride di tutti i pianeti.
+func ComputePlanets(t time.Time) []models.PlanetPosition {
+	out := make([]models.PlanetPosition, 0, len(planets))
+	for _, p := range planets {
+		out = append(out, p.position(t))
+	}
+	return out
+}
+
+func (p planetDef) position(t time.Time) models.PlanetPosition {
+	geo, helio, _ := planetVectors(p.series, t)
+	jde := JulianDay(t.Add(deltaT(t)))
+	earth := helio.sub(geo)
+	ra, dec := apparentPlace(geo, earth, jde)
+
+	delta, r := geo.norm(), helio.norm()
+	// distanza Terra-Sole dallo stesso modello, per coerenza dei triangoli
+	R := earth.norm()
+
+	phase := math.Acos(clampUnit((r*r+delta*delta-R*R)/(2*r*delta))) * rad2deg
+	elong := math.Acos(clampUnit((R*R+delta*delta-r*r)/(2*R*delta))) * rad2deg
+
+	// segno dell'elongazione: est se il pianeta precede il Sole in longitudine
+	sunLon := math.Atan2(-earth.y, -earth.x) * rad2deg
+	planetLon := math.Atan2(geo.y, geo.x) * rad2deg
+	if normDeg180(planetLon-sunLon) < 0 {
+		elong = -elong
+	}
+
+	return models.PlanetPosition{
+		Name:           p.name,
+		RADeg:          ra,
+		DecDeg:         dec,
+		DistanceAU:     delta,
+		SunDistanceAU:  r,
+		Magnitude:      p.magnitude(r, delta, phase, geo, helio, jde),
+		PhaseAngleDeg:  phase,
+		Illumination:   (1 + math.Cos(phase*deg2rad)) / 2,
+		DiameterArcsec: 2 * p.semidiam / delta,
+		ElongationDeg:  elong,
+	}
+}
+
+// magnitude applica le formule dell'Astronomical Almanac riportate da Meeus
+// (cap. 41); per Saturno include il contributo degli anelli.
+func (p planetDef) magnitude(r, delta, phaseDeg float64, geo, helio vec3, jde float64) float64 {
+	m := p.absMag + 5*math.Log10(r*delta)
+	i := phaseDeg
+	switch p.name {
+	case "Mercurio":
+		return m + 0.0380*i - 0.000273*i*i + 0.000002*i*i*i
+	case "Venere":
+		return m + 0.0009*i + 0.000239*i*i - 0.00000065*i*i*i
+	case "Saturno":
+		B, dU := saturnRingPhase(precessVec(geo, jde, jdJ2000), precessVec(helio, jde, jdJ2000))
+		sinB := math.Abs(sinDeg(B))
+		return m + 0.044*math.Abs(dU) - 2.60*sinB + 1.25*sinB*sinB
+	}
+	return m + p.phaseCoef*i
+}
+
+// saturnRingPhase restituisce la latitudine saturnicentrica della Terra B e
+// la differenza ΔU tra le longitudini saturnicentriche di Sole e Terra, misurate
+// nel piano degli anelli (gradi); i vettori sono eclittici J2000 come il polo.
+func saturnRingPhase(geo, helio vec3) (B, dU float64) {
+	pole := equatorialToEclipticVec(vec3{
+		cosDeg(saturnPoleDec) * cosDeg(saturnPoleRA),
+		cosDeg(saturnPoleDec) * sinDeg(saturnPoleRA),
+		sinDeg(saturnPoleDec),
+	})
+	toEarth := geo.scale(-1 / geo.norm())
+	toSun := helio.scale(-1 / helio.norm())
+	B = math.Asin(pole.dot(toEarth)) * rad2deg
+
+	// proiezioni nel piano degli anelli
+	e := toEarth.sub(pole.scale(pole.dot(toEarth)))
+	s := toSun.sub(pole.scale(pole.dot(toSun)))
+	dU = math.Acos(clampUnit(e.dot(s)/(e.norm()*s.norm()))) * rad2deg
+	return B, dU
+}
+
+// clampUnit limita x a [-1, 1] (errori di arrotondamento prima di acos/asin).
+func clampUnit(x float64) float64 {
+	return math.Max(-1, math.Min(1, x))
+}
+
+// PlanetsTonight calcola i pianeti nella notte che contiene now (vedi
+// NightDay): posizione a metà notte, sorgere/transito/tramonto e ore sopra
+// minAltDeg durante il buio astronomico. ok è false se al sito non fa buio:
+// in quel caso la posizione è quella della mezzanotte locale e la
+// NightVisibility resta vuota.
+func PlanetsTonight(now time.Time, latDeg, lonDeg, minAltDeg float64) (out []models.PlanetTonight, window models.NightWindow, ok bool) {
+	day := NightDay(now)
+	np, ok := NewNightPlanner(day, latDeg, lonDeg)
+
+	ref := time.Date(day.Year(), day.Month(), day.Day(), 24, 0, 0, 0, day.Location())
+	if ok {
+		window = np.Window
+		ref = window.Start.Add(window.End.Sub(window.Start) / 2)
+	}
+
+	for _, p := range planets {
+		pos := p.position(ref)
+		pt := models.PlanetTonight{
+			PlanetPosition: pos,
+			RiseTransitSet: RiseTransitSet(pos.RADeg, pos.DecDeg, latDeg, lonDeg, ref, StandardAltitudeStars),
+		}
+		if ok {
+			pt.NightVisibility = np.Visibility(pos.RADeg, pos.DecDeg, minAltDeg)
+		}
+		out = append(out, pt)
+	}
+	return out, window, ok
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVenusHeliocentricMeeus32a(t *testing.T) {
+	// Meeus, esempio 32.a: Venere il 1992 dicembre 20 a 0h TD
+	L, B, R := venusVSOP.heliocentric(2448976.5)
+	if math.Abs(angleDiff(L, 26.11428)) > 0.0001 {
+		t.Errorf("L = %.5f°, atteso 26.11428°", L)
+	}
+	if math.Abs(B-(-2.62070)) > 0.0001 {
+		t.Errorf("B = %.5f°, atteso -2.62070°", B)
+	}
+	if math.Abs(R-0.724603) > 0.000001 {
+		t.Errorf("R = %.6f UA, atteso 0.724603", R)
+	}
+}
+
+func TestVenusApparentMeeus33a(t *testing.T) {
+	// Meeus, esempio 33.a: α = 21h04m41.454s, δ = −18°53′16.84″, Δ = 0.910947
+	p, ok := ComputePlanetPosition("Venere", fromJDE(2448976.5))
+	if !ok {
+		t.Fatal("Venere non trovata")
+	}
+	const arcsec = 1.0 / 3600.0
+	if d := angleDiff(p.RADeg, 316.172725); math.Abs(d)*cosDeg(p.DecDeg) > 2*arcsec {
+		t.Errorf("α = %.6f°, atteso 316.172725° (scarto %.2f″)", p.RADeg, d*3600)
+	}
+	if d := p.DecDeg - (-18.888011); math.Abs(d) > 2*arcsec {
+		t.Errorf("δ = %.6f°, atteso -18.888011° (scarto %.2f″)", p.DecDeg, d*3600)
+	}
+	if math.Abs(p.DistanceAU-0.910947) > 0.000005 {
+		t.Errorf("Δ = %.6f UA, atteso 0.910947", p.DistanceAU)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Galileiani (Meeus, cap. 44)
+// =======================
+//
+// Metodo "a bassa precisione" di Meeus (cap. 44): errore tipico di qualche
+// centesimo di raggio gioviano, cioè pochi minuti sugli istanti dei fenomeni.
+
+var galileanNames = [4]string{"Io", "Europa", "Ganimede", "Callisto"}
+
+// jupiterFlattening è il rapporto raggio polare / equatoriale di Giove
+const jupiterFlattening = 0.93487
+
+// galileanView contiene le coordinate dei quattro satelliti viste da un punto
+// (Terra o Sole): x, y in raggi equatoriali, behind se oltre il pianeta.
+type galileanView struct {
+	x, y   [4]float64
+	behind [4]bool
+}
+
+// galileanGeometry calcola le posizioni dei Galileiani viste dalla Terra e
+// dal Sole all'istante UT t (la seconda serve per ombre ed eclissi).
+func galileanGeometry(t time.Time) (earth, sun galileanView) {
+	d := JulianDay(t.Add(deltaT(t))) - jdJ2000
+
+	V := 172.74 + 0.00111588*d
+	M := 357.529 + 0.9856003*d
+	N := 20.020 + 0.0830853*d + 0.329*sinDeg(V)
+	J := 66.115 + 0.9025179*d - 0.329*sinDeg(V)
+	A := 1.915*sinDeg(M) + 0.020*sinDeg(2*M)
+	B := 5.555*sinDeg(N) + 0.168*sinDeg(2*N)
+	K := J + A - B
+	R := 1.00014 - 0.01671*cosDeg(M) - 0.00014*cosDeg(2*M)
+	r := 5.20872 - 0.25208*cosDeg(N) - 0.00611*cosDeg(2*N)
+	delta := math.Sqrt(r*r + R*R - 2*r*R*cosDeg(K))
+	psi := math.Asin(R/delta*sinDeg(K)) * rad2deg
+
+	// tempo luce Giove→Terra
+	dl := d - delta/173.0
+	u := [4]float64{
+		163.8069 + 203.4058646*dl - B,
+		358.4140 + 101.2916335*dl - B,
+		5.7176 + 50.2345180*dl - B,
+		224.8092 + 21.4879800*dl - B,
+	}
+	G := 331.18 + 50.310482*dl
+	H := 87.45 + 21.569231*dl
+
+	u1, u2, u3 := u[0], u[1], u[2]
+	u[0] += 0.473 * sinDeg(2*(u1-u2))
+	u[1] += 1.065 * sinDeg(2*(u2-u3))
+	u[2] += 0.165 * sinDeg(G)
+	u[3] += 0.843 * sinDeg(H)
+
+	rs := [4]float64{
+		5.9057 - 0.0244*cosDeg(2*(u1-u2)),
+		9.3966 - 0.0882*cosDeg(2*(u2-u3)),
+		14.9883 - 0.0216*cosDeg(G),
+		26.3627 - 0.1939*cosDeg(H),
+	}
+
+	// inclinazione dell'equatore di Giove vista dal Sole (DS) e dalla Terra (DE)
+	lambda := 34.35 + 0.083091*d + 0.329*sinDeg(V) + B
+	DS := 3.12 * sinDeg(lambda+42.8)
+	DE := DS - 2.22*sinDeg(psi)*cosDeg(lambda+22) - 1.30*(r-delta)/delta*sinDeg(lambda-100.5)
+
+	for i := range u {
+		// dalla Terra: u è riferito al Sole, ψ riporta alla direzione terrestre
+		ue := u[i] + psi
+		earth.x[i] = rs[i] * sinDeg(ue)
+		earth.y[i] = -rs[i] * cosDeg(ue) * sinDeg(DE)
+		earth.behind[i] = cosDeg(ue) < 0
+
+		sun.x[i] = rs[i] * sinDeg(u[i])
+		sun.y[i] = -rs[i] * cosDeg(u[i]) * sinDeg(DS)
+		sun.behind[i] = cosDeg(u[i]) < 0
+	}
+	return earth, sun
+}
+
+// onJupiterDisk indica se il punto (x, y) cade entro il disco schiacciato.
+func onJupiterDisk(x, y float64) bool {
+	yy := y / jupiterFlattening
+	return x*x+yy*yy < 1
+}
+
+func sinDeg(a float64) float64 { return math.Sin(a * deg2rad) }
+func cosDeg(a float64) float64 { return math.Cos(a * deg2rad) }
+
+// galileanStates restituisce, per ogni satellite, transito, occultazione,
+// transito dell'ombra ed eclisse.
+func galileanStates(earth, sun galileanView) [4][4]bool {
+	var st [4][4]bool
+	for i := range galileanNames {
+		onDiskE := onJupiterDisk(earth.x[i], earth.y[i])
+		onDiskS := onJupiterDisk(sun.x[i], sun.y[i])
+		st[i][0] = onDiskE && !earth.behind[i] // transito
+		st[i][1] = onDiskS && !sun.behind[i]   // transito dell'ombra
+		st[i][2] = onDiskE && earth.behind[i]  // occultazione
+		st[i][3] = onDiskS && sun.behind[i]    // eclisse
+	}
+	return st
+}
+
+// nomi dei fenomeni, nello stesso ordine di galileanStates
+var galileanEventKinds = [4]string{"Transito", "Transito ombra", "Occultazione", "Eclisse"}
+
+// ComputeJupiterSatellites restituisce la posizione dei Galileiani vista
+// dalla Terra, con lo stato rispetto al disco e la posizione dell'ombra.
+func ComputeJupiterSatellites(t time.Time) []models.SatellitePos {
+	earth, sun := galileanGeometry(t)
+	st := galileanStates(earth, sun)
+
+	out := make([]models.SatellitePos, 0, len(galileanNames))
+	for i, name := range galileanNames {
+		p := models.SatellitePos{
+			Name:     name,
+			X:        earth.x[i],
+			Y:        earth.y[i],
+			Behind:   earth.behind[i],
+			Transit:  st[i][0],
+			Occulted: st[i][2],
+			Eclipsed: st[i][3],
+		}
+		if st[i][1] {
+			// l'ombra cade dove il satellite si proietta sul disco visto dal Sole
+			p.ShadowOnDisk = true
+			p.ShadowX = sun.x[i]
+			p.ShadowY = sun.y[i]
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// jupiterEventStep è il passo di ricerca dei fenomeni (più breve del più
+// corto dei fenomeni, cioè i transiti radenti)
+const jupiterEventStep = 2 * time.Minute
+
+// JupiterSatelliteEvents elenca inizio/fine di transiti, transiti d'ombra,
+// occultazioni ed eclissi dei Galileiani tra start e start+days, in ordine
+// cronologico.
+func JupiterSatelliteEvents(start time.Time, days int) []models.SatelliteEvent {
+	end := start.Add(time.Duration(days) * 24 * time.Hour)
+	stateAt := func(t time.Time) [4][4]bool {
+		return galileanStates(galileanGeometry(t))
+	}
+
+	var out []models.SatelliteEvent
+	prevT, prev := start, stateAt(start)
+	for t := start.Add(jupiterEventStep); !t.After(end); t = t.Add(jupiterEventStep) {
+		cur := stateAt(t)
+		for i := range galileanNames {
+			for k := range galileanEventKinds {
+				if cur[i][k] == prev[i][k] {
+					continue
+				}
+				edge := bisectTime(prevT, t, func(x time.Time) bool {
+					return stateAt(x)[i][k]
+				})
+				out = append(out, models.SatelliteEvent{
+					Time:      edge,
+					Satellite: galileanNames[i],
+					Kind:      galileanEventKinds[k],
+					Start:     cur[i][k],
+				})
+			}
+		}
+		prevT, prev = t, cur
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
+
+// FormatSatelliteEvent restituisce una descrizione breve del fenomeno.
+func FormatSatelliteEvent(e models.SatelliteEvent) string {
+	phase := "fine"
+	if e.Start {
+		phase = "inizio"
+	}
+	return fmt.Sprintf("%s: %s %s", e.Satellite, phase, strings.ToLower(e.Kind))
+}
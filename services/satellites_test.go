@@ -0,0 +1,36 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestJupiterSatellitesMeeus44a(t *testing.T) {
+	// Meeus, esempio 44.a: 1992 dicembre 16, 0h UT (metodo a bassa precisione)
+	tm := time.Date(1992, time.December, 16, 0, 0, 0, 0, time.UTC)
+	want := []struct {
+		name string
+		x, y float64
+	}{
+		{"Io", -3.44, 0.21},
+		{"Europa", 7.44, 0.25},
+		{"Ganimede", 1.24, 0.65},
+		{"Callisto", 7.08, 1.10},
+	}
+
+	got := ComputeJupiterSatellites(tm)
+	if len(got) != len(want) {
+		t.Fatalf("satelliti = %d, attesi %d", len(got), len(want))
+	}
+	for i, w := range want {
+		p := got[i]
+		if p.Name != w.name {
+			t.Errorf("satellite %d = %s, atteso %s", i, p.Name, w.name)
+		}
+		// Meeus arrotonda al centesimo di raggio gioviano
+		if math.Abs(p.X-w.x) > 0.02 || math.Abs(p.Y-w.y) > 0.02 {
+			t.Errorf("%s: X, Y = %+.2f, %+.2f; attesi %+.2f, %+.2f", w.name, p.X, p.Y, w.x, w.y)
+		}
+	}
+}
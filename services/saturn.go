@@ -0,0 +1,365 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Satelliti di Saturno (Meeus, cap. 46)
+// =======================
+//
+// Elementi orbitali degli otto satelliti dalle teorie riportate da Meeus
+// (cap. 46), ridotti al piano equatoriale di Saturno e proiettati sul cielo
+// con la sua procedura (eclittica B1950, tempo luce e prospettiva). Per
+// Giapeto si aggiungono agli elementi secolari i termini periodici dovuti al
+// Sole e a Titano.
+
+var saturnMoonNames = [8]string{"Mimas", "Encelado", "Teti", "Dione", "Rea", "Titano", "Iperione", "Giapeto"}
+
+// Polo nord di Saturno (IAU, J2000)
+const (
+	saturnPoleRA  = 40.589
+	saturnPoleDec = 83.537
+)
+
+// jdB1950 è l'epoca B1950.0 degli elementi dei satelliti (giorno giuliano)
+const jdB1950 = 2433282.4235
+
+// Raggio equatoriale di Saturno (km), per l'unità di X/Y e la parallasse
+const saturnRadiusKm = 60268.0
+
+// Costanti k di Meeus per la correzione del tempo luce dei singoli satelliti
+var saturnLightTimeK = [8]float64{20947, 23715, 26382, 29876, 35313, 53800, 59222, 91820}
+
+// Nodo e inclinazione dell'equatore di Saturno sull'eclittica B1950 (Meeus)
+const (
+	saturnEqNode = 168.8112
+	saturnEqIncl = 28.0817
+)
+
+// Anelli (raggi saturniani): bordo interno dell'anello C, divisione di
+// Cassini, bordo esterno dell'anello A.
+const (
+	SaturnRingInner   = 1.24
+	SaturnRingBInner  = 1.53
+	SaturnCassiniIn   = 1.95
+	SaturnCassiniOut  = 2.03
+	SaturnRingOuter   = 2.27
+	SaturnPolarRatio  = 0.9020
+	saturnAUPerRadius = saturnRadiusKm / 149597870.7
+)
+
+// satElements: elementi riferiti all'equatore di Saturno (λ, r, γ, Ω in gradi
+// e raggi saturniani), come nel cap. 46.
+type satElements struct {
+	lambda, r, gamma, omega float64
+}
+
+// eclipticSatElements riduce elementi riferiti all'eclittica B1950
+// (longitudine media λ', e, ϖ, a, i, Ω) al piano equatoriale di Saturno
+// (sottoprogramma di Meeus per Rea, Titano, Iperione e Giapeto).
+func eclipticSatElements(lambdaP, e, p, a, i, omega float64) satElements {
+	M := lambdaP - p
+	e2, e3, e4, e5 := e*e, e*e*e, e*e*e*e, e*e*e*e*e
+	C := (2*e-0.25*e3+0.0520833333*e5)*sinDeg(M) +
+		(1.25*e2-0.458333333*e4)*sinDeg(2*M) +
+		(1.083333333*e3-0.671875*e5)*sinDeg(3*M) +
+		1.072917*e4*sinDeg(4*M) + 1.142708*e5*sinDeg(5*M)
+	C *= rad2deg
+	r := a * (1 - e2) / (1 + e*cosDeg(M+C))
+
+	s1, c1 := sinDeg(saturnEqIncl), cosDeg(saturnEqIncl)
+	g := omega - saturnEqNode
+	a1 := sinDeg(i) * sinDeg(g)
+	a2 := c1*sinDeg(i)*cosDeg(g) - s1*cosDeg(i)
+	gamma := math.Asin(math.Sqrt(a1*a1+a2*a2)) * rad2deg
+	u := math.Atan2(a1, a2) * rad2deg
+	w := saturnEqNode + u
+	h := c1*sinDeg(i) - s1*cosDeg(i)*cosDeg(g)
+	psi := math.Atan2(s1*sinDeg(g), h) * rad2deg
+
+	return satElements{
+		lambda: lambdaP + C + u - g - psi,
+		r:      r,
+		gamma:  gamma,
+		omega:  w,
+	}
+}
+
+// saturnMoonElements calcola gli elementi degli otto satelliti al giorno
+// giuliano (TT) jde, già corretto per il tempo luce.
+func saturnMoonElements(jde float64) [8]satElements {
+	t1 := jde - 2411093.0
+	t2 := t1 / 365.25
+	t3 := (jde-2433282.423)/365.25 + 1950.0
+	t4 := jde - 2411368.0
+	t5 := t4 / 365.25
+	t6 := jde - 2415020.0
+	t7 := t6 / 36525.0
+	t8 := t6 / 365.25
+	t9 := (jde - 2442000.5) / 365.25
+	t10 := jde - 2409786.0
+	t11 := t10 / 36525.0
+
+	W0 := 5.095 * (t3 - 1866.39)
+	W1 := 74.4 + 32.39*t2
+	W2 := 134.3 + 92.62*t2
+	W3 := 42.0 - 0.5118*t5
+	W4 := 276.59 + 0.5118*t5
+	W5 := 267.2635 + 1222.1136*t7
+	W6 := 175.4762 + 1221.5515*t7
+	W7 := 2.4891 + 0.002435*t7
+	W8 := 113.35 - 0.2597*t7
+
+	e1 := 0.05589 - 0.000346*t7
+
+	var el [8]satElements
+
+	// Mimas
+	{
+		L := 127.64 + 381.994497*t1 - 43.57*sinDeg(W0) - 0.720*sinDeg(3*W0) - 0.02144*sinDeg(5*W0)
+		p := 106.1 + 365.549*t2
+		M := L - p
+		C := 2.18287*sinDeg(M) + 0.025988*sinDeg(2*M) + 0.00043*sinDeg(3*M)
+		el[0] = satElements{L + C, 3.06879 / (1 + 0.01905*cosDeg(M+C)), 1.563, 54.5 - 365.072*t2}
+	}
+
+	// Encelado
+	{
+		L := 200.317 + 262.7319002*t1 + 0.25667*sinDeg(W1) + 0.20883*sinDeg(W2)
+		p := 309.107 + 123.44121*t2
+		M := L - p
+		C := 0.55577*sinDeg(M) + 0.00168*sinDeg(2*M)
+		el[1] = satElements{L + C, 3.94118 / (1 + 0.00485*cosDeg(M+C)), 0.0262, 348 - 151.95*t2}
+	}
+
+	// Teti
+	el[2] = satElements{
+		285.306 + 190.69791226*t1 + 2.063*sinDeg(W0) + 0.03409*sinDeg(3*W0) + 0.001015*sinDeg(5*W0),
+		4.880998, 1.0976, 111.33 - 72.2441*t2,
+	}
+
+	// Dione
+	{
+		L := 254.712 + 131.53493193*t1 - 0.0215*sinDeg(W1) - 0.01733*sinDeg(W2)
+		p := 174.8 + 30.820*t2
+		M := L - p
+		C := 0.24717*sinDeg(M) + 0.00033*sinDeg(2*M)
+		el[3] = satElements{L + C, 6.24871 / (1 + 0.002157*cosDeg(M+C)), 0.0139, 232 - 30.27*t2}
+	}
+
+	// Rea
+	{
+		pp := 342.7 + 10.057*t2
+		a1 := 0.000265*sinDeg(pp) + 0.001*sinDeg(W4)
+		a2 := 0.000265*cosDeg(pp) + 0.001*cosDeg(W4)
+		e := math.Sqrt(a1*a1 + a2*a2)
+		p := math.Atan2(a1, a2) * rad2deg
+		N := 345 - 10.057*t2
+		lambdaP := 359.244 + 79.6900472*t1 + 0.086754*sinDeg(N)
+		i := 28.0362 + 0.346898*cosDeg(N) + 0.01930*cosDeg(W3)
+		omega := 168.8034 + 0.736936*sinDeg(N) + 0.041*sinDeg(W3)
+		el[4] = eclipticSatElements(lambdaP, e, p, 8.725924, i, omega)
+	}
+
+	// Titano
+	{
+		L := 261.1582 + 22.57697855*t4 + 0.074025*sinDeg(W3)
+		iP := 27.45141 + 0.295999*cosDeg(W3)
+		omegaP := 168.66925 + 0.628808*sinDeg(W3)
+		a1 := sinDeg(W7) * sinDeg(omegaP-W8)
+		a2 := cosDeg(W7)*sinDeg(iP) - sinDeg(W7)*cosDeg(iP)*cosDeg(omegaP-W8)
+		g0 := 102.8623
+		psi := math.Atan2(a1, a2) * rad2deg
+		s := math.Sqrt(a1*a1 + a2*a2)
+		g := W4 - omegaP - psi
+		var varpi float64
+		for k := 0; k < 3; k++ {
+			varpi = W4 + 0.37515*(sinDeg(2*g)-sinDeg(2*g0))
+			g = varpi - omegaP - psi
+		}
+		eP := 0.029092 + 0.00019048*(cosDeg(2*g)-cosDeg(2*g0))
+		q := 2 * (W5 - varpi)
+		b1 := sinDeg(iP) * sinDeg(omegaP-W8)
+		b2 := cosDeg(W7)*sinDeg(iP)*cosDeg(omegaP-W8) - sinDeg(W7)*cosDeg(iP)
+		theta := math.Atan2(b1, b2)*rad2deg + W8
+		e := eP + 0.002778797*eP*cosDeg(q)
+		p := varpi + 0.159215*sinDeg(q)
+		u := 2*W5 - 2*theta + psi
+		h := 0.9375*eP*eP*sinDeg(q) + 0.1875*s*s*sinDeg(2*(W5-theta))
+		lambdaP := L - 0.254744*(e1*sinDeg(W6)+0.75*e1*e1*sinDeg(2*W6)+h)
+		i := iP + 0.031843*s*cosDeg(u)
+		omega := omegaP + 0.031843*s*sinDeg(u)/sinDeg(iP)
+		el[5] = eclipticSatElements(lambdaP, e, p, 20.216193, i, omega)
+	}
+
+	// Iperione
+	{
+		eta := 92.39 + 0.5621071*t6
+		zeta := 148.19 - 19.18*t8
+		theta := 184.8 - 35.41*t9
+		thetaP := theta - 7.5
+		as := 176 + 12.22*t8
+		bs := 8 + 24.44*t8
+		cs := bs + 5
+		varpi := 69.898 - 18.67088*t8
+		phi := 2 * (varpi - W5)
+		chi := 94.9 - 2.292*t8
+		a := 24.50601 - 0.08686*cosDeg(eta) - 0.00166*cosDeg(zeta+eta) + 0.00175*cosDeg(zeta-eta)
+		e := 0.103458 - 0.004099*cosDeg(eta) - 0.000167*cosDeg(zeta+eta) + 0.000235*cosDeg(zeta-eta) +
+			0.02303*cosDeg(zeta) - 0.00212*cosDeg(2*zeta) + 0.000151*cosDeg(3*zeta) + 0.00013*cosDeg(phi)
+		p := varpi + 0.15648*sinDeg(chi) - 0.4457*sinDeg(eta) - 0.2657*sinDeg(zeta+eta) -
+			0.3573*sinDeg(zeta-eta) - 12.872*sinDeg(zeta) + 1.668*sinDeg(2*zeta) -
+			0.2419*sinDeg(3*zeta) - 0.07*sinDeg(phi)
+		lambdaP := 177.047 + 16.91993829*t6 + 0.15648*sinDeg(chi) + 9.142*sinDeg(eta) +
+			0.007*sinDeg(2*eta) - 0.014*sinDeg(3*eta) + 0.2275*sinDeg(zeta+eta) +
+			0.2112*sinDeg(zeta-eta) - 0.26*sinDeg(zeta) - 0.0098*sinDeg(2*zeta) -
+			0.013*sinDeg(as) + 0.017*sinDeg(bs) - 0.0303*sinDeg(phi)
+		i := 27.3347 + 0.6434886*cosDeg(chi) + 0.315*cosDeg(W3) + 0.018*cosDeg(theta) - 0.018*cosDeg(cs)
+		omega := 168.6812 + 1.40136*cosDeg(chi) + 0.68599*sinDeg(W3) - 0.0392*sinDeg(cs) + 0.0366*sinDeg(thetaP)
+		el[6] = eclipticSatElements(lambdaP, e, p, a, i, omega)
+	}
+
+	// Giapeto: elementi secolari più le perturbazioni di Sole e Titano
+	{
+		L := 261.1582 + 22.57697855*t4
+		varpiS := 91.796 + 0.562*t7
+		psi := 4.367 - 0.195*t7
+		theta := 146.819 - 3.198*t7
+		phi := 60.470 + 1.521*t7
+		PHI := 205.055 - 2.091*t7
+		eP := 0.028298 + 0.001156*t11
+		varpi0 := 352.91 + 11.71*t11
+		mu := 76.3852 + 4.53795125*t10
+		iP := 18.4602 - 0.9518*t11 - 0.072*t11*t11 + 0.0054*t11*t11*t11
+		omegaP := 143.198 - 3.919*t11 + 0.116*t11*t11 + 0.008*t11*t11*t11
+
+		l := mu - varpi0
+		g := varpi0 - omegaP - psi
+		g1 := varpi0 - omegaP - phi
+		ls := W5 - varpiS
+		gs := varpiS - theta
+		lT := L - W4
+		gT := W4 - PHI
+		u1 := 2 * (l + g - ls - gs)
+		u2 := l + g1 - lT - gT
+		u3 := l + 2*(g-ls-gs)
+		u4 := lT + gT - g1
+		u5 := 2 * (ls + gs)
+
+		a := 58.935028 + 0.004638*cosDeg(u1) + 0.058222*cosDeg(u2)
+		e := eP - 0.0014097*cosDeg(g1-gT) + 0.0003733*cosDeg(u5-2*g) +
+			0.0001180*cosDeg(u3) + 0.0002408*cosDeg(l) +
+			0.0002849*cosDeg(l+u2) + 0.0006190*cosDeg(u4)
+		w := 0.08077*sinDeg(g1-gT) + 0.02139*sinDeg(u5-2*g) - 0.00676*sinDeg(u3) +
+			0.01380*sinDeg(l) + 0.01632*sinDeg(l+u2) + 0.03547*sinDeg(u4)
+		p := varpi0 + w/eP
+		lambdaP := mu - 0.04299*sinDeg(u2) - 0.00789*sinDeg(u1) - 0.06312*sinDeg(ls) -
+			0.00295*sinDeg(2*ls) - 0.02231*sinDeg(u5) + 0.00650*sinDeg(u5+psi)
+		i := iP + 0.04204*cosDeg(u5+psi) + 0.00235*cosDeg(l+g1+lT+gT+phi) + 0.00360*cosDeg(u2+phi)
+		wP := 0.04204*sinDeg(u5+psi) + 0.00235*sinDeg(l+g1+lT+gT+phi) + 0.00358*sinDeg(u2+phi)
+		omega := omegaP + wP/sinDeg(iP)
+		el[7] = eclipticSatElements(lambdaP, e, p, a, i, omega)
+	}
+
+	return el
+}
+
+// eclipticB1950 converte gli elementi in un vettore eclittico B1950 (raggi saturniani).
+func (s satElements) eclipticB1950() vec3 {
+	u := s.lambda - s.omega
+	w := s.omega - saturnEqNode
+
+	return s.equatorToEclipticB1950(vec3{
+		s.r * (cosDeg(u)*cosDeg(w) - sinDeg(u)*cosDeg(s.gamma)*sinDeg(w)),
+		s.r * (sinDeg(u)*cosDeg(w)*cosDeg(s.gamma) + cosDeg(u)*sinDeg(w)),
+		s.r * sinDeg(u) * sinDeg(s.gamma),
+	})
+}
+
+// equatorToEclipticB1950 ruota un vettore dal riferimento dell'equatore di
+// Saturno (asse x sul nodo ascendente) all'eclittica B1950.
+func (satElements) equatorToEclipticB1950(v vec3) vec3 {
+	s1, c1 := sinDeg(saturnEqIncl), cosDeg(saturnEqIncl)
+	s2, c2 := sinDeg(saturnEqNode), cosDeg(saturnEqNode)
+	a := v.x
+	b := c1*v.y - s1*v.z
+	c := s1*v.y + c1*v.z
+	return vec3{c2*a - s2*b, s2*a + c2*b, c}
+}
+
+// skyProjection porta un vettore eclittico B1950 (raggi saturniani) sul
+// cielo visto dalla Terra: A verso ovest, C verso il polo dell'eclittica,
+// B lungo la visuale (positivo = oltre il pianeta).
+func skyProjection(v vec3, lambda0, beta0 float64) (A, B, C float64) {
+	b := v.x*cosDeg(lambda0) + v.y*sinDeg(lambda0)
+	A = v.x*sinDeg(lambda0) - v.y*cosDeg(lambda0)
+	B = b*cosDeg(beta0) + v.z*sinDeg(beta0)
+	C = v.z*cosDeg(beta0) - b*sinDeg(beta0)
+	return A, B, C
+}
+
+// SaturnRingGeometry restituisce l'apertura degli anelli B (latitudine
+// saturnicentrica della Terra, positiva = vediamo la faccia nord) e l'angolo
+// di posizione P del polo nord (gradi, da nord verso est).
+func SaturnRingGeometry(t time.Time) (B, P float64) {
+	geo, _ := geocentricPlanet(saturnVSOP, t)
+	ra, dec, _ := vecToRADec(eclipticToEquatorialVec(geo))
+	return saturnRingAngles(ra, dec)
+}
+
+func saturnRingAngles(raDeg, decDeg float64) (B, P float64) {
+	d0, a0 := saturnPoleDec, saturnPoleRA
+	sinB := -(sinDeg(d0)*sinDeg(decDeg) + cosDeg(d0)*cosDeg(decDeg)*cosDeg(a0-raDeg))
+	B = math.Asin(sinB) * rad2deg
+	P = normDeg(math.Atan2(cosDeg(d0)*sinDeg(a0-raDeg),
+		sinDeg(d0)*cosDeg(decDeg)-cosDeg(d0)*sinDeg(decDeg)*cosDeg(a0-raDeg)) * rad2deg)
+	return B, P
+}
+
+// ComputeSaturnSystem restituisce la geometria degli anelli e la posizione
+// dei satelliti (X lungo l'asse maggiore degli anelli, positivo verso ovest;
+// Y verso il polo nord; unità: raggio equatoriale di Saturno).
+func ComputeSaturnSystem(t time.Time) models.SaturnSystem {
+	geo, emitted := geocentricPlanet(saturnVSOP, t)
+	ra, dec, _ := vecToRADec(eclipticToEquatorialVec(geo))
+	B, P := saturnRingAngles(ra, dec)
+	sys := models.SaturnSystem{RingTiltDeg: B, PolePADeg: P}
+
+	// direzione di Saturno riferita all'eclittica B1950, come gli elementi
+	lambda0, beta0, delta := vecToSpherical(geo)
+	lambda0, beta0 = precessEcliptic(lambda0, beta0, jdJ2000, jdB1950)
+
+	// D porta l'asse Y sul polo nord di Saturno (Meeus, cap. 46)
+	pole := satElements{}.equatorToEclipticB1950(vec3{0, 0, 1})
+	A0, _, C0 := skyProjection(pole, lambda0, beta0)
+	D := math.Atan2(A0, C0) * rad2deg
+
+	el := saturnMoonElements(emitted)
+	for i, name := range saturnMoonNames {
+		A, z, C := skyProjection(el[i].eclipticB1950(), lambda0, beta0)
+		x := A*cosDeg(D) - C*sinDeg(D)
+		y := A*sinDeg(D) + C*cosDeg(D)
+
+		// differenza di tempo luce fra satellite e pianeta, poi prospettiva
+		d := x / el[i].r
+		x += math.Abs(z) / saturnLightTimeK[i] * math.Sqrt(math.Max(0, 1-d*d))
+		w := delta / (delta + z*saturnAUPerRadius)
+
+		sys.Moons = append(sys.Moons, models.SatellitePos{
+			Name:   name,
+			X:      x * w,
+			Y:      y * w,
+			Behind: z > 0,
+		})
+	}
+	return sys
+}
+
+// ComputeSaturnSatellites restituisce solo le posizioni dei satelliti.
+func ComputeSaturnSatellites(t time.Time) []models.SatellitePos {
+	return ComputeSaturnSystem(t).Moons
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// fromJDE converte un giorno giuliano delle effemeridi nell'istante UT.
+func fromJDE(jde float64) time.Time {
+	td := TimeFromJulianDay(jde)
+	return td.Add(-deltaT(td))
+}
+
+func TestSaturnMoonsMeeus46a(t *testing.T) {
+	// Meeus, esempio 46.a: JDE 2451439.50074 (1999 settembre 13)
+	want := []struct {
+		name string
+		x, y float64
+	}{
+		{"Mimas", 3.102, -0.204},
+		{"Encelado", 3.823, 0.318},
+		{"Teti", 4.027, -1.061},
+		{"Dione", -5.365, -1.148},
+		{"Rea", -0.972, -3.136},
+		{"Titano", 14.568, 4.738},
+		{"Iperione", -18.001, -5.328},
+		{"Giapeto", -48.760, 4.137},
+	}
+
+	got := ComputeSaturnSatellites(fromJDE(2451439.50074))
+	if len(got) != len(want) {
+		t.Fatalf("satelliti = %d, attesi %d", len(got), len(want))
+	}
+	for i, w := range want {
+		p := got[i]
+		if p.Name != w.name {
+			t.Errorf("satellite %d = %s, atteso %s", i, p.Name, w.name)
+		}
+		// Meeus dà tre decimali
+		const tol = 0.002
+		if math.Abs(p.X-w.x) > tol || math.Abs(p.Y-w.y) > tol {
+			t.Errorf("%s: X, Y = %+.3f, %+.3f; attesi %+.3f, %+.3f", w.name, p.X, p.Y, w.x, w.y)
+		}
+	}
+}
+
+func TestSaturnRingMeeus45a(t *testing.T) {
+	// Meeus, esempio 45.a: JDE 2448972.50068 (1992 dicembre 16), B = 16.442°, P = 6.741°
+	B, P := SaturnRingGeometry(fromJDE(2448972.50068))
+	if math.Abs(B-16.442) > 0.02 {
+		t.Errorf("apertura anelli B = %.3f°, attesa 16.442°", B)
+	}
+	if math.Abs(P-6.741) > 0.1 {
+		t.Errorf("angolo di posizione P = %.3f°, atteso 6.741°", P)
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Seeing e trasparenza stimati dal modello meteo
+// =======================
+//
+// Stime empiriche, non misure: il seeing combina tre strati turbolenti
+// (corrente a getto, troposfera media, strato al suolo) sommandoli come
+// ε^(5/3), come si fa per gli strati di Kolmogorov; la trasparenza penalizza
+// umidità in quota, nubi medie/alte e foschia. Sono tarate su siti amatoriali
+// di pianura/collina: servono a confrontare ore e notti, non a prevedere la
+// FWHM al decimo di secondo d'arco.
+
+const (
+	// strato al suolo + strumento tipico di un sito amatoriale (″)
+	seeingGroundBase = 1.0
+	// vento al suolo ideale (m/s): più calmo o più forte peggiora
+	seeingIdealWindMS = 3.0
+	// spessore 850-500 hPa (km), per il gradiente termico
+	layer850to500Km = 4.1
+)
+
+// JetStreamMS è il vento più forte tra 200 e 250 hPa (NaN se mancano entrambi).
+func JetStreamMS(h models.WeatherHour) float64 {
+	switch {
+	case math.IsNaN(h.Wind200MS):
+		return h.Wind250MS
+	case math.IsNaN(h.Wind250MS):
+		return h.Wind200MS
+	}
+	return math.Max(h.Wind200MS, h.Wind250MS)
+}
+
+// EstimateSeeing stima la FWHM del seeing (secondi d'arco) per un'ora di
+// previsione. Restituisce NaN se manca il vento in quota.
+func EstimateSeeing(h models.WeatherHour) float64 {
+	jet := JetStreamMS(h)
+	if math.IsNaN(jet) {
+		return math.NaN()
+	}
+	// corrente a getto: 0.3″ a vento nullo, ~1.8″ a 50 m/s
+	eJet := 0.3 + 0.03*jet
+
+	// troposfera media: un gradiente termico oltre ~5 °C/km indica
+	// un'atmosfera poco stabile
+	eMid := 0.2
+	if !math.IsNaN(h.Temp850C) && !math.IsNaN(h.Temp500C) {
+		lapse := (h.Temp850C - h.Temp500C) / layer850to500Km
+		eMid += 0.12 * math.Max(0, lapse-5)
+	}
+
+	// strato al suolo: vento lontano dall'ideale
+	wind := h.WindSpeedMS
+	if math.IsNaN(wind) {
+		wind = seeingIdealWindMS
+	}
+	eGround := seeingGroundBase + 0.1*math.Abs(wind-seeingIdealWindMS)
+
+	sum := 0.0
+	for _, e := range []float64{eJet, eMid, eGround} {
+		sum += math.Pow(e, 5.0/3.0)
+	}
+	return math.Pow(sum, 3.0/5.0)
+}
+
+// EstimateTransparency stima la trasparenza del cielo (0-100%) per un'ora
+// di previsione. Restituisce NaN se manca l'umidità in quota.
+func EstimateTransparency(h models.WeatherHour) float64 {
+	// umidità media pesata della colonna (l'acqua sta soprattutto in basso)
+	var rh, w float64
+	for _, l := range []struct{ v, w float64 }{{h.RH850Pct, 0.5}, {h.RH700Pct, 0.3}, {h.RH500Pct, 0.2}} {
+		if !math.IsNaN(l.v) {
+			rh += l.v * l.w
+			w += l.w
+		}
+	}
+	if w == 0 {
+		return math.NaN()
+	}
+	rh /= w
+
+	penalty := 0.6 * math.Max(0, rh-30)
+	if !math.IsNaN(h.CloudHighPct) {
+		penalty += 0.5 * h.CloudHighPct // cirri sottili: velano anche se "sereno"
+	}
+	if !math.IsNaN(h.CloudMidPct) {
+		penalty += 0.3 * h.CloudMidPct
+	}
+	if !math.IsNaN(h.VisibilityM) && h.VisibilityM < 20000 {
+		penalty += 1.5 * (20 - h.VisibilityM/1000) // foschia
+	}
+	return math.Max(0, math.Min(100, 100-penalty))
+}
+
+// SeeingLabel classifica il seeing.
+func SeeingLabel(arcsec float64) string {
+	switch {
+	case math.IsNaN(arcsec):
+		return "n/d"
+	case arcsec < 1.5:
+		return "eccellente"
+	case arcsec < 2.0:
+		return "buono"
+	case arcsec < 2.5:
+		return "discreto"
+	case arcsec < 3.5:
+		return "mediocre"
+	default:
+		return "scarso"
+	}
+}
+
+// TransparencyLabel classifica la trasparenza.
+func TransparencyLabel(pct float64) string {
+	switch {
+	case math.IsNaN(pct):
+		return "n/d"
+	case pct >= 80:
+		return "ottima"
+	case pct >= 60:
+		return "buona"
+	case pct >= 40:
+		return "discreta"
+	case pct >= 20:
+		return "scarsa"
+	default:
+		return "pessima"
+	}
+}
+
+// SamplingAdvice confronta il seeing con la scala di campionamento:
+// restituisce i pixel per FWHM e un giudizio (ideale 2-3 px per FWHM).
+func SamplingAdvice(seeingArcsec, scaleArcsecPerPx float64) (pxPerFWHM float64, advice string) {
+	if seeingArcsec <= 0 || scaleArcsecPerPx <= 0 || math.IsNaN(seeingArcsec) {
+		return math.NaN(), "dati insufficienti"
+	}
+	pxPerFWHM = seeingArcsec / scaleArcsecPerPx
+	switch {
+	case pxPerFWHM < 1.5:
+		return pxPerFWHM, "sottocampionato: stelle squadrate, valuta una focale più lunga o il drizzle"
+	case pxPerFWHM <= 3.5:
+		return pxPerFWHM, "ben campionato"
+	default:
+		bin := int(pxPerFWHM / 2.5)
+		return pxPerFWHM, fmt.Sprintf("sovracampionato: valuta il binning %d×%d", bin, bin)
+	}
+}
@@ -1,43 +1,256 @@
 package services
 
 import (
+	"container/list"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
 	"fyne.io/fyne/v2"
 )
 
+// httpClient è il client condiviso per i download (mai senza timeout).
+var httpClient = &http.Client{Timeout: 60 * time.Second}
+
 // =======================
-//  Cache in-memory (shared)
+//  Cache in-memoria (shared)
 // =======================
 
+// Budget di memoria di default per le cache condivise.
+const (
+	DefaultImageCacheBudget     int64 = 64 << 20
+	DefaultAnimationCacheBudget int64 = 192 << 20
+)
+
+// ResourceCache è una cache LRU di risorse fyne sicura per l'uso concorrente.
+// Ogni voce è una lista di risorse (un'immagine = un solo elemento) e pesa
+// quanto la somma dei byte dei suoi contenuti; oltre il budget vengono
+// eliminate le voci usate meno di recente.
+type ResourceCache struct {
+	mu       sync.Mutex
+	budget   int64
+	size     int64
+	ll       *list.List // fronte = usata più di recente
+	items    map[string]*list.Element
+	inflight map[string]*inflightFetch
+
+	hits, misses, evictions, shared uint64
+}
+
+type resourceEntry struct {
+	key       string
+	res       []fyne.Resource
+	fetchedAt time.Time
+	size      int64
+}
+
+// inflightFetch è un download in corso a cui possono agganciarsi altre richieste.
+type inflightFetch struct {
+	done      chan struct{}
+	res       []fyne.Resource
+	fetchedAt time.Time
+	err       error
+}
+
+// CacheStats sono le statistiche di una ResourceCache.
+type CacheStats struct {
+	Hits      uint64 // richieste servite dalla cache
+	Misses    uint64 // richieste che hanno richiesto un download
+	Shared    uint64 // richieste agganciate a un download già in corso
+	Evictions uint64 // voci eliminate per rispettare il budget
+	Entries   int
+	Bytes     int64
+	Budget    int64
+}
+
+func (s CacheStats) String() string {
+	return fmt.Sprintf("%d hit / %d miss (%d condivisi) · %d voci · %.1f/%.0f MB",
+		s.Hits, s.Misses, s.Shared, s.Entries,
+		float64(s.Bytes)/(1<<20), float64(s.Budget)/(1<<20))
+}
+
+// NewResourceCache crea una cache con il budget di memoria indicato (byte).
+func NewResourceCache(budget int64) *ResourceCache {
+	return &ResourceCache{
+		budget:   budget,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		inflight: make(map[string]*inflightFetch),
+	}
+}
+
+func resourcesSize(res []fyne.Resource) int64 {
+	var n int64
+	for _, r := range res {
+		if r != nil {
+			n += int64(len(r.Content()))
+		}
+	}
+	return n
+}
+
+// Get restituisce le risorse per key e l'istante di download, aggiornando l'LRU.
+func (c *ResourceCache) Get(key string) ([]fyne.Resource, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return nil, time.Time{}, false
+	}
+	c.hits++
+	c.ll.MoveToFront(el)
+	e := el.Value.(*resourceEntry)
+	return e.res, e.fetchedAt, true
+}
+
+// Set inserisce o sostituisce la voce key.
+func (c *ResourceCache) Set(key string, res []fyne.Resource, fetchedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(key, res, fetchedAt)
+}
+
+func (c *ResourceCache) setLocked(key string, res []fyne.Resource, fetchedAt time.Time) {
+	size := resourcesSize(res)
+
+	if el, ok := c.items[key]; ok {
+		c.removeLocked(el)
+	}
+	// una voce più grande dell'intero budget non viene memorizzata
+	if size > c.budget {
+		return
+	}
+
+	el := c.ll.PushFront(&resourceEntry{key: key, res: res, fetchedAt: fetchedAt, size: size})
+	c.items[key] = el
+	c.size += size
+
+	for c.size > c.budget {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeLocked(oldest)
+		c.evictions++
+	}
+}
+
+func (c *ResourceCache) removeLocked(el *list.Element) {
+	e := el.Value.(*resourceEntry)
+	c.ll.Remove(el)
+	delete(c.items, e.key)
+	c.size -= e.size
+}
+
+// Fetch esegue fetch per key assicurando un solo download alla volta per chiave:
+// le richieste concorrenti per la stessa key attendono e ricevono lo stesso
+// risultato. In caso di successo la voce viene salvata in cache.
+func (c *ResourceCache) Fetch(key string, fetch func() ([]fyne.Resource, time.Time, error)) ([]fyne.Resource, time.Time, error) {
+	c.mu.Lock()
+	if f, ok := c.inflight[key]; ok {
+		c.shared++
+		c.mu.Unlock()
+		<-f.done
+		return f.res, f.fetchedAt, f.err
+	}
+	f := &inflightFetch{done: make(chan struct{})}
+	c.inflight[key] = f
+	c.mu.Unlock()
+
+	f.res, f.fetchedAt, f.err = fetch()
+
+	c.mu.Lock()
+	if f.err == nil && len(f.res) > 0 {
+		c.setLocked(key, f.res, f.fetchedAt)
+	}
+	delete(c.inflight, key)
+	c.mu.Unlock()
+	close(f.done)
+
+	return f.res, f.fetchedAt, f.err
+}
+
+// SetBudget cambia il budget di memoria, eliminando subito le voci in eccesso.
+func (c *ResourceCache) SetBudget(budget int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.budget = budget
+	for c.size > c.budget && c.ll.Len() > 0 {
+		c.removeLocked(c.ll.Back())
+		c.evictions++
+	}
+}
+
+// Clear svuota la cache (le statistiche restano).
+func (c *ResourceCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.size = 0
+}
+
+// Stats restituisce un'istantanea delle statistiche.
+func (c *ResourceCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Shared:    c.shared,
+		Evictions: c.evictions,
+		Entries:   c.ll.Len(),
+		Bytes:     c.size,
+		Budget:    c.budget,
+	}
+}
+
 // imageCache memorizza le immagini remote scaricate
-var imageCache = map[string]fyne.Resource{}
+var imageCache = NewResourceCache(DefaultImageCacheBudget)
 
 // animationCache memorizza le sequenze di animazioni (liste di frame)
-var animationCache = map[string][]fyne.Resource{}
+var animationCache = NewResourceCache(DefaultAnimationCacheBudget)
 
-// GetImageFromCache ritorna un'immagine dalla cache se esiste
-func GetImageFromCache(url string) (fyne.Resource, bool) {
-	res, ok := imageCache[url]
-	return res, ok
+// GetImageFromCache ritorna un'immagine dalla cache se esiste, con l'istante di download
+func GetImageFromCache(url string) (fyne.Resource, time.Time, bool) {
+	res, fetchedAt, ok := imageCache.Get(url)
+	if !ok || len(res) == 0 {
+		return nil, time.Time{}, false
+	}
+	return res[0], fetchedAt, true
 }
 
 // SetImageInCache salva un'immagine nella cache
-func SetImageInCache(url string, res fyne.Resource) {
-	imageCache[url] = res
+func SetImageInCache(url string, res fyne.Resource, fetchedAt time.Time) {
+	imageCache.Set(url, []fyne.Resource{res}, fetchedAt)
 }
 
-// GetAnimationFromCache ritorna un'animazione dalla cache se esiste
-func GetAnimationFromCache(source string) ([]fyne.Resource, bool) {
-	res, ok := animationCache[source]
-	return res, ok
+// GetAnimationFromCache ritorna un'animazione dalla cache se esiste, con l'istante di download
+func GetAnimationFromCache(source string) ([]fyne.Resource, time.Time, bool) {
+	return animationCache.Get(source)
 }
 
 // SetAnimationInCache salva un'animazione nella cache
-func SetAnimationInCache(source string, frames []fyne.Resource) {
-	animationCache[source] = frames
+func SetAnimationInCache(source string, frames []fyne.Resource, fetchedAt time.Time) {
+	animationCache.Set(source, frames, fetchedAt)
+}
+
+// MemoryCacheStats restituisce le statistiche delle cache di immagini e animazioni.
+func MemoryCacheStats() (images, animations CacheStats) {
+	return imageCache.Stats(), animationCache.Stats()
+}
+
+// IsCacheFresh indica se un contenuto scaricato in fetchedAt è ancora entro il TTL di url.
+func IsCacheFresh(url string, fetchedAt time.Time) bool {
+	return time.Since(fetchedAt) < CacheTTL(url)
 }
 
-// ClearCache svuota tutta la cache
+// ClearCache svuota tutta la cache (memoria e disco)
 func ClearCache() {
-	imageCache = make(map[string]fyne.Resource)
-	animationCache = make(map[string][]fyne.Resource)
+	imageCache.Clear()
+	animationCache.Clear()
+	httpDiskCache.clear()
 }
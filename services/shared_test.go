@@ -0,0 +1,95 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+func testResource(name string, size int) []fyne.Resource {
+	return []fyne.Resource{fyne.NewStaticResource(name, []byte(strings.Repeat("x", size)))}
+}
+
+func TestResourceCacheLRU(t *testing.T) {
+	c := NewResourceCache(25)
+	now := time.Now()
+
+	c.Set("a", testResource("a", 10), now)
+	c.Set("b", testResource("b", 10), now)
+	if _, _, ok := c.Get("a"); !ok { // "a" diventa la più recente
+		t.Fatal("a non trovata")
+	}
+	c.Set("c", testResource("c", 10), now) // 30 byte > 25: esce "b"
+
+	if _, _, ok := c.Get("b"); ok {
+		t.Error("b ancora in cache, doveva essere eliminata")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, _, ok := c.Get(key); !ok {
+			t.Errorf("%s eliminata, doveva restare", key)
+		}
+	}
+
+	// una voce più grande del budget non entra e non svuota la cache
+	c.Set("enorme", testResource("enorme", 100), now)
+	if _, _, ok := c.Get("enorme"); ok {
+		t.Error("voce oltre il budget memorizzata")
+	}
+
+	s := c.Stats()
+	want := CacheStats{Hits: 3, Misses: 2, Evictions: 1, Entries: 2, Bytes: 20, Budget: 25}
+	if s != want {
+		t.Errorf("statistiche = %+v, attese %+v", s, want)
+	}
+}
+
+func TestResourceCacheFetchDedup(t *testing.T) {
+	c := NewResourceCache(DefaultImageCacheBudget)
+	const n = 16
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func() ([]fyne.Resource, time.Time, error) {
+		calls.Add(1)
+		<-release // tiene il download aperto finché tutti si sono agganciati
+		return testResource("img", 10), time.Now(), nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]fyne.Resource, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, _, err := c.Fetch("img", loader)
+			if err != nil {
+				t.Errorf("Fetch %d: %v", i, err)
+			}
+			results[i] = res
+		}(i)
+	}
+
+	// aspetta che le altre n-1 richieste siano in attesa del download in corso
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Stats().Shared < n-1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("loader eseguito %d volte, atteso 1", got)
+	}
+	for i, res := range results {
+		if len(res) != 1 || res[0] != results[0][0] {
+			t.Errorf("richiesta %d: risultato diverso dalle altre", i)
+		}
+	}
+	if _, _, ok := c.Get("img"); !ok {
+		t.Error("risultato non salvato in cache")
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // fusi IANA anche dove il sistema non li fornisce (Android, wasm)
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Siti di osservazione
+// =======================
+
+// ValidateSite controlla i campi di un sito prima di salvarlo.
+func ValidateSite(s models.Site) error {
+	switch {
+	case strings.TrimSpace(s.Name) == "":
+		return errors.New("il sito deve avere un nome")
+	case s.Lat < -90 || s.Lat > 90:
+		return fmt.Errorf("latitudine %.4f fuori intervallo", s.Lat)
+	case s.Lon < -180 || s.Lon > 180:
+		return fmt.Errorf("longitudine %.4f fuori intervallo", s.Lon)
+	case s.Bortle < 0 || s.Bortle > 9:
+		return fmt.Errorf("classe Bortle %d non valida (1-9)", s.Bortle)
+	case s.SQM < 0 || s.SQM > 23:
+		return fmt.Errorf("SQM %.2f non valido", s.SQM)
+	}
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("fuso orario %q sconosciuto", s.Timezone)
+		}
+	}
+	return nil
+}
+
+// SiteLocation restituisce il fuso del sito (quello del dispositivo se non
+// indicato o sconosciuto).
+func SiteLocation(s models.Site) *time.Location {
+	if s.Timezone != "" {
+		if loc, err := time.LoadLocation(s.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// ParseHorizon legge un profilo d'orizzonte nel formato "az:alt" separati da
+// virgole o spazi, es. "0:10, 90:25, 180:5". I punti sono ordinati per azimut.
+func ParseHorizon(text string) ([]models.HorizonPoint, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\t'
+	})
+	var out []models.HorizonPoint
+	for _, f := range fields {
+		az, alt, ok := strings.Cut(f, ":")
+		if !ok {
+			return nil, fmt.Errorf("punto d'orizzonte %q: usa il formato az:alt", f)
+		}
+		a, err1 := strconv.ParseFloat(az, 64)
+		h, err2 := strconv.ParseFloat(alt, 64)
+		if err1 != nil || err2 != nil || a < 0 || a >= 360 || h < -5 || h > 90 {
+			return nil, fmt.Errorf("punto d'orizzonte %q non valido", f)
+		}
+		out = append(out, models.HorizonPoint{AzDeg: a, AltDeg: h})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].AzDeg < out[j].AzDeg })
+	return out, nil
+}
+
+// FormatHorizon è l'inverso di ParseHorizon.
+func FormatHorizon(points []models.HorizonPoint) string {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = fmt.Sprintf("%g:%g", p.AzDeg, p.AltDeg)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// HorizonAltitude interpola linearmente (passando per il nord) l'altezza
+// dell'orizzonte locale all'azimut azDeg; 0 senza profilo.
+func HorizonAltitude(points []models.HorizonPoint, azDeg float64) float64 {
+	switch len(points) {
+	case 0:
+		return 0
+	case 1:
+		return points[0].AltDeg
+	}
+	az := math.Mod(azDeg+360, 360)
+
+	// punto precedente e successivo, con il giro completo tra ultimo e primo
+	i := sort.Search(len(points), func(i int) bool { return points[i].AzDeg > az })
+	prev, next := points[(i+len(points)-1)%len(points)], points[i%len(points)]
+	span := math.Mod(next.AzDeg-prev.AzDeg+360, 360)
+	if span == 0 {
+		return prev.AltDeg
+	}
+	f := math.Mod(az-prev.AzDeg+360, 360) / span
+	return prev.AltDeg + f*(next.AltDeg-prev.AltDeg)
+}
+
+// BortleFromSQM stima la classe di Bortle da una lettura SQM (mag/arcsec²).
+func BortleFromSQM(sqm float64) int {
+	limits := []float64{21.99, 21.89, 21.69, 20.49, 19.50, 18.94, 18.38, 17.80}
+	for i, l := range limits {
+		if sqm >= l {
+			return i + 1
+		}
+	}
+	return 9
+}
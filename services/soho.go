@@ -8,12 +8,14 @@ import (
 	"image/gif"
 	"image/png"
 	"io"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -24,65 +26,100 @@ import (
 //  Remote Image Loading (SOHO/SUVI)
 // =======================
 
-// LoadRemoteImage scarica un'immagine da url, usa cache e aggiorna il canvas.Image.
-func LoadRemoteImage(url, name string, img *canvas.Image, status *widget.Label) {
-	if res, ok := GetImageFromCache(url); ok {
+// setStatus aggiorna la label di stato, se presente.
+func setStatus(status *widget.Label, text string) {
+	if status != nil {
+		status.SetText(text)
+	}
+}
+
+// LoadRemoteImage scarica un'immagine da url, usa cache (memoria + disco) e aggiorna il canvas.Image.
+// Una copia scaduta viene mostrata subito e poi rivalidata in background.
+// onDone (opzionale) viene chiamata sul goroutine UI quando il caricamento è concluso.
+func LoadRemoteImage(url, name string, img *canvas.Image, status *widget.Label, onDone func()) {
+	haveCopy := false
+
+	if res, fetchedAt, ok := GetImageFromCache(url); ok {
 		img.Resource = res
 		img.Refresh()
-		if status != nil {
-			status.SetText(name + " caricata dalla cache.")
+		if IsCacheFresh(url, fetchedAt) {
+			setStatus(status, name+" caricata dalla cache.")
+			if onDone != nil {
+				onDone()
+			}
+			return
 		}
-		return
+		haveCopy = true
+	} else if data, fetchedAt, ok := CachedCopy(url); ok {
+		res := fyne.NewStaticResource(name, data)
+		img.Resource = res
+		img.Refresh()
+		if IsCacheFresh(url, fetchedAt) {
+			SetImageInCache(url, res, fetchedAt)
+			setStatus(status, name+" caricata dalla cache su disco.")
+			if onDone != nil {
+				onDone()
+			}
+			return
+		}
+		haveCopy = true
 	}
 
-	if status != nil {
-		status.SetText(fmt.Sprintf("Scarico immagine… (%s)", name))
+	if haveCopy {
+		setStatus(status, name+": copia in cache non aggiornata, aggiorno…")
+	} else {
+		setStatus(status, fmt.Sprintf("Scarico immagine… (%s)", name))
 	}
 
 	go func() {
-		resp, err := http.Get(url)
+		// più viste sullo stesso URL condividono un unico download; solo chi lo
+		// esegue conosce l'esito (state), gli altri ripiegano sul TTL
+		state, led := CacheFresh, false
+		frames, fetchedAt, err := imageCache.Fetch(url, func() ([]fyne.Resource, time.Time, error) {
+			led = true
+			cd, err := FetchCached(url)
+			if cd.Data == nil {
+				return nil, time.Time{}, err
+			}
+			state = cd.State
+			if err != nil {
+				log.Printf("[SOHO] %s: aggiornamento fallito, uso la copia in cache: %v\n", name, err)
+			}
+			return []fyne.Resource{fyne.NewStaticResource(name, cd.Data)}, cd.FetchedAt, nil
+		})
 		if err != nil {
 			fyne.Do(func() {
-				if status != nil {
-					status.SetText("Errore download " + name + ":\n" + err.Error())
-				}
+				setStatus(status, "Errore download "+name+":\n"+err.Error())
 			})
 			return
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			fyne.Do(func() {
-				if status != nil {
-					status.SetText(fmt.Sprintf("HTTP %d per %s", resp.StatusCode, name))
-				}
-			})
-			return
+		if !led && !IsCacheFresh(url, fetchedAt) {
+			state = CacheStale
 		}
 
-		data, err := io.ReadAll(resp.Body)
-		if err != nil || len(data) == 0 {
-			fyne.Do(func() {
-				if status != nil {
-					status.SetText("Dati non validi per " + name)
-				}
-			})
-			return
-		}
-
-		res := fyne.NewStaticResource(name, data)
-
 		fyne.Do(func() {
-			SetImageInCache(url, res)
-			img.Resource = res
+			img.Resource = frames[0]
 			img.Refresh()
-			if status != nil {
-				status.SetText(name + " aggiornata.")
+			setStatus(status, refreshStatus(name, state))
+			if onDone != nil {
+				onDone()
 			}
 		})
 	}()
 }
 
+// refreshStatus descrive l'esito di un aggiornamento per la label di stato.
+func refreshStatus(name string, state CacheState) string {
+	switch state {
+	case CacheStale:
+		return name + ": aggiornamento non riuscito, mostro la copia in cache."
+	case CacheRevalidated:
+		return name + " verificata: nessuna novità."
+	default:
+		return name + " aggiornata."
+	}
+}
+
 // =======================
 //  Remote Animation Loading (SOHO/SUVI)
 // =======================
@@ -91,212 +128,193 @@ func LoadRemoteImage(url, name string, img *canvas.Image, status *widget.Label)
 //   - se source termina con .gif / .avi → scarica il file e ritorna []Resource
 //   - altrimenti considera source una directory e scarica i frame (png/jpg) in ordine.
 //
-// Usa cache e chiama callback(frames) quando è pronto.
+// Usa cache e chiama callback(frames) quando è pronto. Se la copia in memoria è
+// scaduta, callback riceve subito quella e l'aggiornamento prosegue in
+// background: se porta frame nuovi callback viene chiamata una seconda volta.
 func LoadRemoteAnimation(source, name string, status *widget.Label, callback func([]fyne.Resource)) {
-	if frames, ok := GetAnimationFromCache(source); ok {
-		if status != nil {
-			status.SetText(name + " caricata dalla cache.")
+	if frames, fetchedAt, ok := GetAnimationFromCache(source); ok {
+		fresh := IsCacheFresh(source, fetchedAt)
+		if fresh {
+			setStatus(status, name+" caricata dalla cache.")
+		} else {
+			setStatus(status, name+": copia in cache non aggiornata, aggiorno in background…")
 		}
 		if callback != nil {
 			fyne.Do(func() {
 				callback(frames)
 			})
 		}
-		return
-	}
-
-	if status != nil {
-		status.SetText(fmt.Sprintf("Scarico animazione… (%s)", name))
-	}
+		if fresh {
+			return
+		}
 
-	lower := strings.ToLower(source)
-	if strings.HasSuffix(lower, ".gif") || strings.HasSuffix(lower, ".avi") {
 		go func() {
-			resp, err := http.Get(source)
-			if err != nil {
-				fyne.Do(func() {
-					if status != nil {
-						status.SetText("Errore download " + name + ":\n" + err.Error())
-					}
-				})
-				return
-			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode != http.StatusOK {
-				fyne.Do(func() {
-					if status != nil {
-						status.SetText(fmt.Sprintf("HTTP %d per %s", resp.StatusCode, name))
-					}
-				})
-				return
-			}
-
-			data, err := io.ReadAll(resp.Body)
-			if err != nil || len(data) == 0 {
-				fyne.Do(func() {
-					if status != nil {
-						status.SetText("Dati non validi per " + name)
-					}
-				})
-				return
-			}
-
-			resName := filepath.Base(source)
-			if resName == "" || !strings.Contains(resName, ".") {
-				resName = name
-			}
-
-			var frames []fyne.Resource
-			lowerResName := strings.ToLower(resName)
-			if strings.HasSuffix(lowerResName, ".gif") {
-				if g, err := gif.DecodeAll(bytes.NewReader(data)); err == nil && len(g.Image) > 0 {
-					for i, pal := range g.Image {
-						rgba := image.NewRGBA(pal.Bounds())
-						draw.Draw(rgba, rgba.Bounds(), pal, pal.Bounds().Min, draw.Over)
-
-						var buf bytes.Buffer
-						if err := png.Encode(&buf, rgba); err != nil {
-							continue
-						}
-						fname := fmt.Sprintf("%s_frame_%03d.png", strings.TrimSuffix(resName, ".gif"), i)
-						frames = append(frames, fyne.NewStaticResource(fname, buf.Bytes()))
-					}
-				}
-			}
-
-			if len(frames) == 0 {
-				frames = []fyne.Resource{fyne.NewStaticResource(resName, data)}
+			state, led := CacheFresh, false
+			updated, fetchedAt, err := animationCache.Fetch(source, func() ([]fyne.Resource, time.Time, error) {
+				led = true
+				var frames []fyne.Resource
+				var fetchedAt time.Time
+				var err error
+				frames, fetchedAt, state, err = fetchAnimationFrames(source, name)
+				return frames, fetchedAt, err
+			})
+			if err == nil && !led && !IsCacheFresh(source, fetchedAt) {
+				state = CacheStale
 			}
-
 			fyne.Do(func() {
-				SetAnimationInCache(source, frames)
-				if status != nil {
-					status.SetText(name + " animazione pronta.")
+				if err != nil {
+					setStatus(status, fmt.Sprintf("%s: aggiornamento fallito (%v).", name, err))
+					return
 				}
-				if callback != nil {
-					callback(frames)
+				setStatus(status, refreshStatus(name, state))
+				if state != CacheStale && state != CacheRevalidated && callback != nil {
+					callback(updated)
 				}
 			})
 		}()
 		return
 	}
 
+	if _, fetchedAt, ok := CachedCopy(source); ok && !IsCacheFresh(source, fetchedAt) {
+		setStatus(status, name+": copia in cache non aggiornata, aggiorno…")
+	} else {
+		setStatus(status, fmt.Sprintf("Scarico animazione… (%s)", name))
+	}
+
 	go func() {
-		resp, err := http.Get(source)
+		frames, _, err := animationCache.Fetch(source, func() ([]fyne.Resource, time.Time, error) {
+			frames, fetchedAt, _, err := fetchAnimationFrames(source, name)
+			return frames, fetchedAt, err
+		})
 		if err != nil {
 			fyne.Do(func() {
-				if status != nil {
-					status.SetText("Errore HTTP su directory animazione " + name + ":\n" + err.Error())
-				}
+				setStatus(status, err.Error())
 			})
 			return
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			fyne.Do(func() {
-				if status != nil {
-					status.SetText(fmt.Sprintf("Risposta %d per %s: %s", resp.StatusCode, name, string(body)))
-				}
-			})
-			return
-		}
+		fyne.Do(func() {
+			if len(frames) > 1 {
+				setStatus(status, fmt.Sprintf("%s: %d frame pronti.", name, len(frames)))
+			} else {
+				setStatus(status, name+" animazione pronta.")
+			}
+			if callback != nil {
+				callback(frames)
+			}
+		})
+	}()
+}
 
-		htmlBytes, err := io.ReadAll(resp.Body)
+// fetchAnimationFrames scarica (tramite la cache su disco) un'animazione GIF/AVI
+// o i frame elencati in una directory HTTP; state dice se il contenuto (o
+// l'elenco della directory) è nuovo, rivalidato o una copia scaduta.
+// Va chiamata fuori dal goroutine UI.
+func fetchAnimationFrames(source, name string) (frames []fyne.Resource, fetchedAt time.Time, state CacheState, err error) {
+	lower := strings.ToLower(source)
+	if strings.HasSuffix(lower, ".gif") || strings.HasSuffix(lower, ".avi") {
+		cd, err := FetchCached(source)
+		if cd.Data == nil {
+			return nil, time.Time{}, cd.State, fmt.Errorf("Errore download %s:\n%v", name, err)
+		}
 		if err != nil {
-			fyne.Do(func() {
-				if status != nil {
-					status.SetText("Errore lettura listing " + name + ":\n" + err.Error())
-				}
-			})
-			return
+			log.Printf("[SOHO] %s: aggiornamento fallito, uso la copia in cache: %v\n", name, err)
 		}
-		html := string(htmlBytes)
 
-		re := regexp.MustCompile(`href="([^"]+\.(?:png|jpg|jpeg))"`)
-		matches := re.FindAllStringSubmatch(html, -1)
-		if len(matches) == 0 {
-			fyne.Do(func() {
-				if status != nil {
-					status.SetText("Nessun frame trovato per " + name)
-				}
-			})
-			return
+		resName := filepath.Base(source)
+		if resName == "" || !strings.Contains(resName, ".") {
+			resName = name
 		}
+		return decodeAnimation(resName, cd.Data), cd.FetchedAt, cd.State, nil
+	}
 
-		seen := make(map[string]bool)
-		var names []string
-		for _, m := range matches {
-			if len(m) < 2 {
-				continue
-			}
-			n := m[1]
-			if strings.HasSuffix(n, "/") {
-				continue
-			}
-			if seen[n] {
-				continue
-			}
-			seen[n] = true
-			names = append(names, n)
-		}
+	cd, err := FetchCached(source)
+	if cd.Data == nil {
+		return nil, time.Time{}, cd.State, fmt.Errorf("Errore HTTP su directory animazione %s:\n%v", name, err)
+	}
+	if err != nil {
+		log.Printf("[SOHO] %s: elenco dei frame non aggiornato, uso la copia in cache: %v\n", name, err)
+	}
+	html := string(cd.Data)
 
-		if len(names) == 0 {
-			fyne.Do(func() {
-				if status != nil {
-					status.SetText("Nessun frame valido per " + name)
-				}
-			})
-			return
+	re := regexp.MustCompile(`href="([^"]+\.(?:png|jpg|jpeg))"`)
+	matches := re.FindAllStringSubmatch(html, -1)
+	if len(matches) == 0 {
+		return nil, time.Time{}, cd.State, fmt.Errorf("Nessun frame trovato per %s", name)
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range matches {
+		if len(m) < 2 {
+			continue
+		}
+		n := m[1]
+		if strings.HasSuffix(n, "/") {
+			continue
 		}
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		names = append(names, n)
+	}
 
-		sort.Strings(names)
+	if len(names) == 0 {
+		return nil, time.Time{}, cd.State, fmt.Errorf("Nessun frame valido per %s", name)
+	}
 
-		const maxFrames = 60
-		if len(names) > maxFrames {
-			names = names[len(names)-maxFrames:]
-		}
+	sort.Strings(names)
 
-		var frames []fyne.Resource
-		for _, n := range names {
-			u := n
-			if !strings.HasPrefix(u, "http") {
-				u = source + n
-			}
+	const maxFrames = 60
+	if len(names) > maxFrames {
+		names = names[len(names)-maxFrames:]
+	}
 
-			r, err := http.Get(u)
-			if err != nil {
-				continue
-			}
-			data, err := io.ReadAll(r.Body)
-			r.Body.Close()
-			if err != nil || len(data) == 0 {
-				continue
-			}
-			frames = append(frames, fyne.NewStaticResource(n, data))
+	for _, n := range names {
+		u := n
+		if !strings.HasPrefix(u, "http") {
+			u = source + n
 		}
 
-		if len(frames) == 0 {
-			fyne.Do(func() {
-				if status != nil {
-					status.SetText("Impossibile scaricare i frame per " + name)
-				}
-			})
-			return
+		// i frame hanno il timestamp nel nome: non cambiano più una volta pubblicati
+		fd, _ := fetchCachedTTL(u, ImmutableCacheTTL)
+		if len(fd.Data) == 0 {
+			continue
 		}
+		frames = append(frames, fyne.NewStaticResource(n, fd.Data))
+	}
 
-		fyne.Do(func() {
-			SetAnimationInCache(source, frames)
-			if status != nil {
-				status.SetText(fmt.Sprintf("%s: %d frame pronti.", name, len(frames)))
-			}
-			if callback != nil {
-				callback(frames)
+	if len(frames) == 0 {
+		return nil, time.Time{}, cd.State, fmt.Errorf("Impossibile scaricare i frame per %s", name)
+	}
+	return frames, cd.FetchedAt, cd.State, nil
+}
+
+// decodeAnimation converte una GIF nei suoi frame PNG; altri formati restano un'unica risorsa.
+func decodeAnimation(resName string, data []byte) []fyne.Resource {
+	var frames []fyne.Resource
+	lowerResName := strings.ToLower(resName)
+	if strings.HasSuffix(lowerResName, ".gif") {
+		if g, err := gif.DecodeAll(bytes.NewReader(data)); err == nil && len(g.Image) > 0 {
+			for i, pal := range g.Image {
+				rgba := image.NewRGBA(pal.Bounds())
+				draw.Draw(rgba, rgba.Bounds(), pal, pal.Bounds().Min, draw.Over)
+
+				var buf bytes.Buffer
+				if err := png.Encode(&buf, rgba); err != nil {
+					continue
+				}
+				fname := fmt.Sprintf("%s_frame_%03d.png", strings.TrimSuffix(resName, ".gif"), i)
+				frames = append(frames, fyne.NewStaticResource(fname, buf.Bytes()))
 			}
-		})
-	}()
+		}
+	}
+
+	if len(frames) == 0 {
+		frames = []fyne.Resource{fyne.NewStaticResource(resName, data)}
+	}
+	return frames
 }
 
 // =======================
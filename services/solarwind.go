@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Vento solare in tempo reale (NOAA SWPC, DSCOVR/ACE a L1)
+// =======================
+
+// Feed JSON (tabelle con intestazione) del campo magnetico e del plasma per
+// le ultime 2 ore, 24 ore o 7 giorni, a passo di un minuto.
+const solarWindBaseURL = "https://services.swpc.noaa.gov/products/solar-wind/"
+
+// solarWindFeeds associa la finestra (ore) al suffisso dei file SWPC
+var solarWindFeeds = []struct {
+	hours  int
+	suffix string
+	ttl    time.Duration
+}{
+	{2, "2-hour", 1 * time.Minute},
+	{24, "1-day", 5 * time.Minute},
+	{168, "7-day", 30 * time.Minute},
+}
+
+// Bz sud "sostenuto": almeno BzSouthMinutes minuti sotto BzSouthThreshold,
+// ignorando brevi risalite o buchi nei dati fino a bzSouthGap (oltre si apre
+// un nuovo intervallo). Ogni campione SWPC copre bzSampleStep.
+const (
+	BzSouthThreshold = -5.0 // nT
+	BzSouthMinutes   = 30
+	bzSouthGap       = 5 * time.Minute
+	bzSampleStep     = time.Minute
+)
+
+func init() {
+	for _, f := range solarWindFeeds {
+		SetCacheTTL(solarWindBaseURL+"mag-"+f.suffix+".json", f.ttl)
+		SetCacheTTL(solarWindBaseURL+"plasma-"+f.suffix+".json", f.ttl)
+	}
+}
+
+// SolarWindHours sono le finestre disponibili (ore).
+func SolarWindHours() []int {
+	out := make([]int, len(solarWindFeeds))
+	for i, f := range solarWindFeeds {
+		out[i] = f.hours
+	}
+	return out
+}
+
+// FetchSolarWind scarica campo magnetico e plasma per la finestra più
+// piccola che copre le ore richieste. Se uno dei due feed manca restituisce
+// comunque l'altro, con i valori mancanti a NaN.
+// Va chiamata fuori dal goroutine UI.
+func FetchSolarWind(hours int) ([]models.SolarWindSample, error) {
+	feed := solarWindFeeds[len(solarWindFeeds)-1]
+	for _, f := range solarWindFeeds {
+		if hours <= f.hours {
+			feed = f
+			break
+		}
+	}
+
+	mag, magErr := FetchCached(solarWindBaseURL + "mag-" + feed.suffix + ".json")
+	plasma, plasmaErr := FetchCached(solarWindBaseURL + "plasma-" + feed.suffix + ".json")
+	if magErr != nil && plasmaErr != nil {
+		return nil, errors.Join(magErr, plasmaErr)
+	}
+	var magData, plasmaData []byte
+	if magErr == nil {
+		magData = mag.Data
+	}
+	if plasmaErr == nil {
+		plasmaData = plasma.Data
+	}
+	return parseSolarWind(magData, plasmaData)
+}
+
+// parseSolarWind unisce per minuto i feed mag (bt, bz_gsm) e plasma
+// (density, speed, temperature); uno dei due può essere nil.
+func parseSolarWind(mag, plasma []byte) ([]models.SolarWindSample, error) {
+	byTime := map[time.Time]*models.SolarWindSample{}
+	sample := func(t time.Time) *models.SolarWindSample {
+		s := byTime[t]
+		if s == nil {
+			nan := math.NaN()
+			s = &models.SolarWindSample{Time: t, Bt: nan, Bz: nan, Speed: nan, Density: nan, Temperature: nan}
+			byTime[t] = s
+		}
+		return s
+	}
+	value := func(rec map[string]any, key string) float64 {
+		if v, ok := swpcFloat(rec[key]); ok {
+			return v
+		}
+		return math.NaN() // null nei feed: dato non valido
+	}
+
+	if mag != nil {
+		records, err := parseSWPCRecords(mag)
+		if err != nil {
+			return nil, fmt.Errorf("formato del campo magnetico non riconosciuto: %w", err)
+		}
+		for _, rec := range records {
+			if t, ok := swpcTime(rec["time_tag"]); ok {
+				s := sample(t)
+				s.Bt, s.Bz = value(rec, "bt"), value(rec, "bz_gsm")
+			}
+		}
+	}
+	if plasma != nil {
+		records, err := parseSWPCRecords(plasma)
+		if err != nil {
+			return nil, fmt.Errorf("formato del plasma non riconosciuto: %w", err)
+		}
+		for _, rec := range records {
+			if t, ok := swpcTime(rec["time_tag"]); ok {
+				s := sample(t)
+				s.Density, s.Speed, s.Temperature = value(rec, "density"), value(rec, "speed"), value(rec, "temperature")
+			}
+		}
+	}
+	if len(byTime) == 0 {
+		return nil, errors.New("nessun dato di vento solare leggibile")
+	}
+
+	out := make([]models.SolarWindSample, 0, len(byTime))
+	for _, s := range byTime {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out, nil
+}
+
+// SouthwardBz trova gli intervalli con Bz stabilmente verso sud (sotto
+// BzSouthThreshold per almeno BzSouthMinutes minuti), i più favorevoli
+// all'aurora. I campioni devono essere in ordine di tempo.
+func SouthwardBz(samples []models.SolarWindSample) []models.BzSouthPeriod {
+	var out []models.BzSouthPeriod
+	var cur *models.BzSouthPeriod
+	closePeriod := func() {
+		// l'ultimo campione copre un intero passo: 30 campioni da un minuto fanno 30 minuti
+		if cur != nil && cur.End.Sub(cur.Start)+bzSampleStep >= BzSouthMinutes*time.Minute {
+			out = append(out, *cur)
+		}
+		cur = nil
+	}
+
+	for _, s := range samples {
+		if math.IsNaN(s.Bz) {
+			continue // dato mancante: tollerato come una risalita, fino a bzSouthGap
+		}
+		if cur != nil && s.Time.Sub(cur.End) > bzSouthGap {
+			closePeriod()
+		}
+		if s.Bz >= BzSouthThreshold {
+			continue
+		}
+		if cur == nil {
+			cur = &models.BzSouthPeriod{Start: s.Time, MinBz: s.Bz}
+		}
+		cur.End = s.Time
+		cur.MinBz = math.Min(cur.MinBz, s.Bz)
+	}
+	closePeriod()
+	return out
+}
+
+// LatestSolarWind restituisce per ogni grandezza l'ultimo valore valido
+// (Time è quello del campione più recente con un campo magnetico o una velocità).
+func LatestSolarWind(samples []models.SolarWindSample) (models.SolarWindSample, bool) {
+	nan := math.NaN()
+	last := models.SolarWindSample{Bt: nan, Bz: nan, Speed: nan, Density: nan, Temperature: nan}
+	found := false
+	pick := func(dst *float64, v float64) {
+		if math.IsNaN(*dst) && !math.IsNaN(v) {
+			*dst = v
+		}
+	}
+	for i := len(samples) - 1; i >= 0; i-- {
+		s := samples[i]
+		if !found && (!math.IsNaN(s.Bz) || !math.IsNaN(s.Speed)) {
+			last.Time, found = s.Time, true
+		}
+		pick(&last.Bt, s.Bt)
+		pick(&last.Bz, s.Bz)
+		pick(&last.Speed, s.Speed)
+		pick(&last.Density, s.Density)
+		pick(&last.Temperature, s.Temperature)
+	}
+	return last, found
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+func TestSouthwardBz(t *testing.T) {
+	t0 := time.Date(2024, 5, 10, 18, 0, 0, 0, time.UTC)
+	// series costruisce campioni al minuto con Bz = -8 nT, tranne quelli in
+	// missing (dato mancante)
+	series := func(n int, missing ...int) []models.SolarWindSample {
+		out := make([]models.SolarWindSample, n)
+		for i := range out {
+			out[i] = models.SolarWindSample{Time: t0.Add(time.Duration(i) * time.Minute), Bz: -8}
+		}
+		for _, i := range missing {
+			out[i].Bz = math.NaN()
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		samples []models.SolarWindSample
+		want    int
+	}{
+		{"30 minuti esatti", series(30), 1},
+		{"29 minuti", series(29), 0},
+		{"buco breve", series(40, 10, 11, 12), 1},
+		{"buco lungo", series(40, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20), 0},
+	}
+	for _, tt := range tests {
+		if got := SouthwardBz(tt.samples); len(got) != tt.want {
+			t.Errorf("%s: %d intervalli, attesi %d", tt.name, len(got), tt.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Sole: posizione e crepuscoli
+// =======================
+
+// Altezze del centro del Sole che definiscono alba/tramonto e crepuscoli.
+// SunriseAlt include rifrazione media e semidiametro.
+const (
+	SunriseAlt              = -0.8333
+	CivilTwilightAlt        = -6.0
+	NauticalTwilightAlt     = -12.0
+	AstronomicalTwilightAlt = -18.0
+)
+
+// sunSampleStep è il passo con cui si cercano i passaggi di quota del Sole
+const sunSampleStep = 10 * time.Minute
+
+// sunEcliptic calcola longitudine eclittica apparente (gradi) e distanza (UA)
+// del Sole con l'algoritmo di Meeus, cap. 25 (precisione ~0.01°, più che
+// sufficiente per alba, tramonto, crepuscoli e fasi lunari).
+func sunEcliptic(t time.Time) (lambdaDeg, distAU float64) {
+	T := julianCenturies(JulianDay(t))
+
+	L0 := normDeg(280.46646 + 36000.76983*T + 0.0003032*T*T)
+	M := normDeg(357.52911 + 35999.05029*T - 0.0001537*T*T)
+	e := 0.016708634 - 0.000042037*T - 0.0000001267*T*T
+	Mr := M * deg2rad
+	C := (1.914602-0.004817*T-0.000014*T*T)*math.Sin(Mr) +
+		(0.019993-0.000101*T)*math.Sin(2*Mr) +
+		0.000289*math.Sin(3*Mr)
+	trueLon := L0 + C
+	v := (M + C) * deg2rad
+	distAU = 1.000001018 * (1 - e*e) / (1 + e*math.Cos(v))
+
+	omega := 125.04 - 1934.136*T
+	lambdaDeg = normDeg(trueLon - 0.00569 - 0.00478*math.Sin(omega*deg2rad))
+	return lambdaDeg, distAU
+}
+
+// sunApparent calcola RA/Dec apparenti (gradi) e distanza (UA) del Sole.
+func sunApparent(t time.Time) (raDeg, decDeg, distAU float64) {
+	lambdaDeg, distAU := sunEcliptic(t)
+	T := julianCenturies(JulianDay(t))
+	omega := 125.04 - 1934.136*T
+	lambda := lambdaDeg * deg2rad
+	eps := (meanObliquity(T) + 0.00256*math.Cos(omega*deg2rad)) * deg2rad
+
+	raDeg = normDeg(math.Atan2(math.Cos(eps)*math.Sin(lambda), math.Cos(lambda)) * rad2deg)
+	decDeg = math.Asin(math.Sin(eps)*math.Sin(lambda)) * rad2deg
+	return raDeg, decDeg, distAU
+}
+
+// SunEquatorial restituisce RA/Dec apparenti del Sole (gradi).
+func SunEquatorial(t time.Time) (raDeg, decDeg float64) {
+	raDeg, decDeg, _ = sunApparent(t)
+	return raDeg, decDeg
+}
+
+// SunAltitude restituisce l'altezza geometrica del Sole (gradi) per il sito.
+func SunAltitude(t time.Time, latDeg, lonDeg float64) float64 {
+	ra, dec := SunEquatorial(t)
+	alt, _, _ := EquatorialToHorizontal(ra, dec, latDeg, lonDeg, t)
+	return alt
+}
+
+// SunPosition restituisce coordinate equatoriali e orizzontali del Sole.
+func SunPosition(t time.Time, latDeg, lonDeg float64) models.SunPosition {
+	ra, dec, dist := sunApparent(t)
+	alt, az, _ := EquatorialToHorizontal(ra, dec, latDeg, lonDeg, t)
+	return models.SunPosition{
+		RADeg:      ra,
+		DecDeg:     dec,
+		AltDeg:     alt,
+		AzDeg:      az,
+		DistanceAU: dist,
+	}
+}
+
+// sunTrack campiona l'altezza del Sole in [from, to] con passo sunSampleStep.
+type sunTrack struct {
+	lat, lon float64
+	times    []time.Time
+	alts     []float64
+}
+
+func newSunTrack(from, to time.Time, latDeg, lonDeg float64) *sunTrack {
+	st := &sunTrack{lat: latDeg, lon: lonDeg}
+	for t := from; t.Before(to); t = t.Add(sunSampleStep) {
+		st.times = append(st.times, t)
+		st.alts = append(st.alts, SunAltitude(t, latDeg, lonDeg))
+	}
+	st.times = append(st.times, to)
+	st.alts = append(st.alts, SunAltitude(to, latDeg, lonDeg))
+	return st
+}
+
+// crossings restituisce il primo passaggio in salita e l'ultimo in discesa
+// della quota h0 (nil se non avvengono nell'intervallo).
+func (st *sunTrack) crossings(h0 float64) (rising, setting *time.Time) {
+	below := func(t time.Time) bool {
+		return SunAltitude(t, st.lat, st.lon) < h0
+	}
+	for i := 1; i < len(st.alts); i++ {
+		a, b := st.alts[i-1] < h0, st.alts[i] < h0
+		if a == b {
+			continue
+		}
+		edge := bisectTime(st.times[i-1], st.times[i], below)
+		if a && rising == nil {
+			rising = &edge
+		}
+		if !a {
+			setting = &edge
+		}
+	}
+	return rising, setting
+}
+
+// always indica se il Sole resta sempre sopra (o sempre sotto) h0.
+func (st *sunTrack) always(h0 float64) (above, below bool) {
+	above, below = true, true
+	for _, a := range st.alts {
+		if a < h0 {
+			above = false
+		} else {
+			below = false
+		}
+	}
+	return above, below
+}
+
+// culmination restituisce l'istante e l'altezza massima del Sole, rifiniti
+// con una parabola sui tre campioni attorno al massimo.
+func (st *sunTrack) culmination() (time.Time, float64) {
+	best := 0
+	for i, a := range st.alts {
+		if a > st.alts[best] {
+			best = i
+		}
+	}
+	if best == 0 || best == len(st.alts)-1 {
+		return st.times[best], st.alts[best]
+	}
+
+	y0, y1, y2 := st.alts[best-1], st.alts[best], st.alts[best+1]
+	den := y0 - 2*y1 + y2
+	if den == 0 {
+		return st.times[best], y1
+	}
+	x := 0.5 * (y0 - y2) / den // in passi, rispetto al campione centrale
+	t := st.times[best].Add(time.Duration(x * float64(sunSampleStep)))
+	return t, SunAltitude(t, st.lat, st.lon)
+}
+
+// ComputeSunEvents calcola alba, tramonto, transito e crepuscoli civile,
+// nautico e astronomico del giorno civile di day (nel fuso di day).
+func ComputeSunEvents(day time.Time, latDeg, lonDeg float64) models.SunEvents {
+	y, m, d := day.Date()
+	from := time.Date(y, m, d, 0, 0, 0, 0, day.Location())
+	to := time.Date(y, m, d+1, 0, 0, 0, 0, day.Location())
+	st := newSunTrack(from, to, latDeg, lonDeg)
+
+	ev := models.SunEvents{Date: from}
+	ev.Transit, ev.TransitAltDeg = st.culmination()
+	ev.PolarDay, ev.PolarNight = st.always(SunriseAlt)
+
+	ev.Sunrise, ev.Sunset = st.crossings(SunriseAlt)
+	ev.CivilDawn, ev.CivilDusk = st.crossings(CivilTwilightAlt)
+	ev.NauticalDawn, ev.NauticalDusk = st.crossings(NauticalTwilightAlt)
+	ev.AstronomicalDawn, ev.AstronomicalDusk = st.crossings(AstronomicalTwilightAlt)
+	return ev
+}
+
+// DarkWindow restituisce la finestra in cui il Sole è sotto h0 nella notte
+// che comincia la sera di day (da mezzogiorno a mezzogiorno, ora locale di day).
+// ok è false se il Sole non scende mai sotto h0; se non sale mai sopra h0
+// (notte polare) la finestra è l'intero intervallo.
+func DarkWindow(day time.Time, latDeg, lonDeg, h0 float64) (start, end time.Time, ok bool) {
+	y, m, d := day.Date()
+	noon := time.Date(y, m, d, 12, 0, 0, 0, day.Location())
+	nextNoon := time.Date(y, m, d+1, 12, 0, 0, 0, day.Location())
+	st := newSunTrack(noon, nextNoon, latDeg, lonDeg)
+
+	above, below := st.always(h0)
+	switch {
+	case above:
+		return time.Time{}, time.Time{}, false
+	case below:
+		return noon, nextNoon, true
+	}
+
+	// il primo tramonto sotto h0 apre la notte, la risalita successiva la chiude
+	start, end = noon, nextNoon
+	if st.alts[0] >= h0 {
+		start = firstSetting(st, h0)
+	}
+	if rising := firstRisingAfter(st, h0, start); rising != nil {
+		end = *rising
+	}
+	return start, end, true
+}
+
+// firstSetting restituisce il primo passaggio in discesa sotto h0 (o l'inizio
+// dell'intervallo se non avviene).
+func firstSetting(st *sunTrack, h0 float64) time.Time {
+	below := func(t time.Time) bool {
+		return SunAltitude(t, st.lat, st.lon) < h0
+	}
+	for i := 1; i < len(st.alts); i++ {
+		if st.alts[i-1] >= h0 && st.alts[i] < h0 {
+			return bisectTime(st.times[i-1], st.times[i], below)
+		}
+	}
+	return st.times[0]
+}
+
+// firstRisingAfter restituisce il primo passaggio in salita sopra h0 dopo after.
+func firstRisingAfter(st *sunTrack, h0 float64, after time.Time) *time.Time {
+	below := func(t time.Time) bool {
+		return SunAltitude(t, st.lat, st.lon) < h0
+	}
+	for i := 1; i < len(st.alts); i++ {
+		if st.times[i].Before(after) {
+			continue
+		}
+		if st.alts[i-1] < h0 && st.alts[i] >= h0 {
+			edge := bisectTime(st.times[i-1], st.times[i], below)
+			return &edge
+		}
+	}
+	return nil
+}
+
+// AstronomicalNight restituisce inizio e fine del buio astronomico della notte
+// che comincia la sera di day; vedi DarkWindow.
+func AstronomicalNight(day time.Time, latDeg, lonDeg float64) (start, end time.Time, ok bool) {
+	return DarkWindow(day, latDeg, lonDeg, AstronomicalTwilightAlt)
+}
+
+// bisectTime trova (al secondo) l'istante in [a, b] in cui cond cambia valore.
+func bisectTime(a, b time.Time, cond func(time.Time) bool) time.Time {
+	ca := cond(a)
+	for b.Sub(a) > time.Second {
+		mid := a.Add(b.Sub(a) / 2)
+		if cond(mid) == ca {
+			a = mid
+		} else {
+			b = mid
+		}
+	}
+	return b.Truncate(time.Second)
+}
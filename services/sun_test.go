@@ -0,0 +1,81 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSunMeeus25a(t *testing.T) {
+	// Meeus, esempio 25.a: 1992 ottobre 13 a 0h TD (JDE 2448908.5)
+	at := TimeFromJulianDay(2448908.5)
+
+	lambda, dist := sunEcliptic(at)
+	if math.Abs(lambda-199.90895) > 0.0002 {
+		t.Errorf("λ = %.5f°, atteso 199.90895°", lambda)
+	}
+	if math.Abs(dist-0.99766) > 0.00001 {
+		t.Errorf("R = %.5f UA, atteso 0.99766", dist)
+	}
+
+	ra, dec, _ := sunApparent(at)
+	if math.Abs(ra-198.38083) > 0.0002 {
+		t.Errorf("α = %.5f°, atteso 198.38083°", ra)
+	}
+	if math.Abs(dec-(-7.78507)) > 0.0002 {
+		t.Errorf("δ = %.5f°, atteso -7.78507°", dec)
+	}
+}
+
+func TestSunPolarCases(t *testing.T) {
+	oslo := time.FixedZone("CET", 3600)
+	berlin := time.FixedZone("CEST", 2*3600)
+	const tromsoLat, tromsoLon = 69.65, 18.96
+
+	t.Run("Tromsø a mezza estate", func(t *testing.T) {
+		day := time.Date(2024, 6, 21, 12, 0, 0, 0, oslo)
+		ev := ComputeSunEvents(day, tromsoLat, tromsoLon)
+		if !ev.PolarDay || ev.PolarNight {
+			t.Errorf("PolarDay = %v, PolarNight = %v; atteso giorno polare", ev.PolarDay, ev.PolarNight)
+		}
+		if ev.Sunrise != nil || ev.Sunset != nil {
+			t.Errorf("alba %v e tramonto %v durante il giorno polare", ev.Sunrise, ev.Sunset)
+		}
+		if _, _, ok := AstronomicalNight(day, tromsoLat, tromsoLon); ok {
+			t.Error("buio astronomico durante il giorno polare")
+		}
+	})
+
+	t.Run("Tromsø a dicembre", func(t *testing.T) {
+		day := time.Date(2024, 12, 21, 12, 0, 0, 0, oslo)
+		ev := ComputeSunEvents(day, tromsoLat, tromsoLon)
+		if ev.PolarDay || !ev.PolarNight {
+			t.Errorf("PolarDay = %v, PolarNight = %v; attesa notte polare", ev.PolarDay, ev.PolarNight)
+		}
+		if ev.TransitAltDeg >= SunriseAlt {
+			t.Errorf("altezza al transito %.2f°, attesa sotto l'orizzonte", ev.TransitAltDeg)
+		}
+		// a mezzogiorno il Sole è sopra -18°: il buio astronomico è solo notturno
+		start, end, ok := AstronomicalNight(day, tromsoLat, tromsoLon)
+		if !ok || !start.After(day) || end.Sub(start) >= 24*time.Hour {
+			t.Errorf("buio astronomico %v - %v (ok %v), atteso una parte della notte", start, end, ok)
+		}
+	})
+
+	t.Run("Berlino a giugno", func(t *testing.T) {
+		day := time.Date(2024, 6, 21, 12, 0, 0, 0, berlin)
+		ev := ComputeSunEvents(day, 52.52, 13.40)
+		if ev.Sunrise == nil || ev.Sunset == nil || ev.PolarDay || ev.PolarNight {
+			t.Errorf("alba %v, tramonto %v: attesi entrambi", ev.Sunrise, ev.Sunset)
+		}
+		if ev.AstronomicalDusk != nil || ev.AstronomicalDawn != nil {
+			t.Errorf("crepuscolo astronomico %v / %v, atteso nessuno", ev.AstronomicalDusk, ev.AstronomicalDawn)
+		}
+		if _, _, ok := AstronomicalNight(day, 52.52, 13.40); ok {
+			t.Error("buio astronomico a Berlino il 21 giugno")
+		}
+		if _, _, ok := DarkWindow(day, 52.52, 13.40, NauticalTwilightAlt); !ok {
+			t.Error("manca il buio nautico a Berlino il 21 giugno")
+		}
+	})
+}
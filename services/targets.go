@@ -0,0 +1,153 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Helpers RA/Dec
+// =======================
+
+// FormatRAFromDeg formatta un'ascensione retta in gradi come "HHh MMm SS.Ss".
+func FormatRAFromDeg(raDeg float64) string {
+	// RA in ore: gradi / 15
+	totalSeconds := raDeg / 15.0 * 3600.0
+	if totalSeconds < 0 {
+		totalSeconds = math.Mod(totalSeconds, 24*3600)
+		if totalSeconds < 0 {
+			totalSeconds += 24 * 3600
+		}
+	}
+	h := int(totalSeconds / 3600)
+	m := int(totalSeconds/60) % 60
+	s := totalSeconds - float64(h*3600+m*60)
+	return fmt.Sprintf("%02dh %02dm %04.1fs", h, m, s)
+}
+
+// FormatDecFromDeg formatta una declinazione in gradi come "±DD° MM' SS.S\"".
+func FormatDecFromDeg(decDeg float64) string {
+	sign := '+'
+	if decDeg < 0 {
+		sign = '-'
+	}
+	absDeg := math.Abs(decDeg)
+	totalArcsec := absDeg * 3600.0
+	d := int(totalArcsec / 3600)
+	m := int(totalArcsec/60) % 60
+	s := totalArcsec - float64(d*3600+m*60)
+	return fmt.Sprintf("%c%02d° %02d' %04.1f\"", sign, d, m, s)
+}
+
+// =======================
+//  Catalog loading
+// =======================
+
+// LoadTargetsCatalog legge il catalogo DSO locale (DSOCatalogLocalPath) e lo
+// raggruppa per catalogo. Se il file manca o non è valido usa il catalogo hardcoded.
+func LoadTargetsCatalog() map[string][]models.TargetObject {
+	data, err := os.ReadFile(DSOCatalogLocalPath)
+	if err == nil {
+		m, err := ParseDsoTargetsJSON(data)
+		if err == nil && len(m) > 0 {
+			log.Printf("[Targets] Caricato catalogo dinamico (%d cataloghi)\n", len(m))
+			return m
+		}
+		log.Printf("[Targets] Catalogo JSON non valido o vuoto, uso fallback. err=%v len=%d\n", err, len(m))
+	} else {
+		log.Printf("[Targets] Nessun catalogo locale (%s): %v. Uso fallback.\n", DSOCatalogLocalPath, err)
+	}
+
+	// fallback: catalogo hardcoded
+	return models.TargetsByaCatalog
+}
+
+// ParseDsoTargetsJSON converte un dso_catalog.json nei TargetObject raggruppati per catalogo.
+func ParseDsoTargetsJSON(data []byte) (map[string][]models.TargetObject, error) {
+	var cat models.DsoCatalog
+	if err := json.Unmarshal(data, &cat); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]models.TargetObject)
+
+	for _, o := range cat.Objects {
+		if strings.TrimSpace(o.Catalog) == "" {
+			continue
+		}
+
+		code := o.Code
+		if code == "" && o.Number != nil {
+			code = fmt.Sprintf("%d", *o.Number)
+		}
+		if code == "" {
+			code = o.ID
+		}
+
+		raStr := ""
+		if o.RADeg != nil {
+			raStr = FormatRAFromDeg(*o.RADeg)
+		}
+		decStr := ""
+		if o.DecDeg != nil {
+			decStr = FormatDecFromDeg(*o.DecDeg)
+		}
+
+		t := models.TargetObject{
+			Catalog:       o.Catalog,
+			Code:          code,
+			Name:          o.Name,
+			Type:          o.Type,
+			Magnitude:     o.Mag,
+			SurfaceBright: o.SurfaceBrightness,
+			RA:            raStr,
+			Dec:           decStr,
+			RADeg:         o.RADeg,
+			DecDeg:        o.DecDeg,
+			SizeMajor:     o.SizeMajor,
+			SizeMinor:     o.SizeMinor,
+			Constellation: o.Constellation,
+		}
+
+		out[o.Catalog] = append(out[o.Catalog], t)
+	}
+
+	for k, list := range out {
+		SortTargetsByNumericCode(list)
+		out[k] = list
+	}
+
+	return out, nil
+}
+
+// SortTargetsByNumericCode ordina i target in base alla parte numerica del codice
+func SortTargetsByNumericCode(list []models.TargetObject) {
+	sort.Slice(list, func(i, j int) bool {
+		return extractNumericCode(list[i].Code) < extractNumericCode(list[j].Code)
+	})
+}
+
+func extractNumericCode(code string) int {
+	var digits []rune
+	for _, ch := range code {
+		if ch >= '0' && ch <= '9' {
+			digits = append(digits, ch)
+		}
+	}
+	if len(digits) == 0 {
+		return math.MaxInt32
+	}
+	v, err := strconv.Atoi(string(digits))
+	if err != nil {
+		return math.MaxInt32
+	}
+	return v
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+// =======================
+//  Riepilogo "Stanotte"
+// =======================
+
+// Criteri dei target consigliati nel riepilogo
+const (
+	tonightTargetMinAlt = 30.0
+	tonightTargetMaxMag = 10.0
+)
+
+// TonightInputs sono i dati scaricati che alimentano il riepilogo: ognuno
+// può mancare (rete assente), il riepilogo usa quello che c'è.
+type TonightInputs struct {
+	Forecast   *models.WeatherForecast
+	Kp         *models.KpReading
+	Targets    []models.TargetObject
+	MaxTargets int
+	Horizon    []models.HorizonPoint // orizzonte locale del sito
+}
+
+// PlanTonight compone il riepilogo della notte che contiene now (o che
+// comincia la sera di now) per il sito lat/lon.
+func PlanTonight(now time.Time, lat, lon float64, in TonightInputs) models.TonightSummary {
+	day := NightDay(now)
+	y, m, d := day.Date()
+	s := models.TonightSummary{
+		Date: time.Date(y, m, d, 0, 0, 0, 0, day.Location()),
+		Lat:  lat,
+		Lon:  lon,
+		Sun:  ComputeSunEvents(day, lat, lon),
+		Kp:   in.Kp,
+	}
+
+	// notte: buio astronomico, altrimenti tramonto-alba
+	if start, end, ok := AstronomicalNight(day, lat, lon); ok {
+		s.Dark = &models.NightWindow{Start: start, End: end}
+		s.Night = *s.Dark
+	} else if start, end, ok := DarkWindow(day, lat, lon, SunriseAlt); ok {
+		s.Night = models.NightWindow{Start: start, End: end}
+	}
+
+	// Luna: fase a inizio notte, sorgere/tramonto da mezzogiorno a mezzogiorno
+	ref := s.Night.Start
+	if ref.IsZero() || now.After(ref) {
+		ref = now
+	}
+	s.Moon = ComputeMoonPosition(ref, lat, lon)
+	noon := time.Date(y, m, d, 12, 0, 0, 0, day.Location())
+	s.MoonRise, s.MoonSet = MoonRiseSet(noon, noon.AddDate(0, 0, 1), lat, lon)
+	if s.Dark != nil {
+		s.MoonlessWindows = moonlessWindows(s.Dark.Start, s.Dark.End, lat, lon)
+		for _, w := range s.MoonlessWindows {
+			s.MoonlessHours += w.End.Sub(w.Start).Hours()
+		}
+	}
+
+	if in.Forecast != nil && !s.Night.Start.IsZero() {
+		for _, n := range NightForecasts(*in.Forecast, lat, lon, now) {
+			if n.Date.Year() == y && n.Date.Month() == m && n.Date.Day() == d {
+				s.Weather = &n
+				break
+			}
+		}
+		for _, h := range in.Forecast.Hours {
+			if h.Time.Add(time.Hour).After(s.Night.Start) && h.Time.Before(s.Night.End) {
+				s.Hours = append(s.Hours, h)
+			}
+		}
+	}
+
+	if s.Dark != nil && len(in.Targets) > 0 {
+		if np, ok := NewNightPlanner(day, lat, lon); ok {
+			np.SetHorizon(in.Horizon)
+			s.Targets = bestTonightTargets(np, in.Targets, in.MaxTargets)
+		}
+	}
+
+	scoreTonight(&s)
+	return s
+}
+
+// bestTonightTargets sceglie i target meglio piazzati: ore sopra 30°,
+// altezza massima e luminosità pesate insieme, così i soliti oggetti
+// circumpolari deboli non riempiono la lista.
+func bestTonightTargets(np *NightPlanner, targets []models.TargetObject, n int) []models.TonightTarget {
+	list := np.FilterTonight(targets, models.TonightFilter{
+		MinAltDeg:    tonightTargetMinAlt,
+		MaxMagnitude: tonightTargetMaxMag,
+	})
+	merit := func(t models.TonightTarget) float64 {
+		// FilterTonight con MaxMagnitude scarta i target senza magnitudine
+		return t.HoursAbove + t.MaxAltDeg/30 + (tonightTargetMaxMag-*t.Target.Magnitude)/2
+	}
+	sort.SliceStable(list, func(i, j int) bool { return merit(list[i]) > merit(list[j]) })
+	if n > 0 && len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// scoreTonight assegna il punteggio 0-100 e il verdetto GO / FORSE / NO-GO.
+// Pesi: nuvole e pioggia 50, buio senza Luna 30, vento 10, trasparenza 10;
+// senza previsione meteo il punteggio si basa sul solo buio e non arriva a GO.
+func scoreTonight(s *models.TonightSummary) {
+	var got, total float64
+	add := func(weight, frac float64) {
+		got += weight * clampUnit(frac)
+		total += weight
+	}
+
+	// buio: 4 ore senza Luna valgono il massimo
+	if s.Dark == nil {
+		add(30, 0)
+		s.Reasons = append(s.Reasons, "niente buio astronomico")
+	} else {
+		add(30, s.MoonlessHours/4)
+		if s.MoonlessHours < 1 {
+			s.Reasons = append(s.Reasons, fmt.Sprintf("Luna alta quasi tutta la notte (%.0f%% illuminata)", s.Moon.Illumination*100))
+		}
+	}
+
+	// senza nuvolosità il punteggio non può promettere un GO
+	cloudKnown := false
+	if w := s.Weather; w != nil {
+		if !math.IsNaN(w.AvgCloudPct) {
+			cloudKnown = true
+			frac := 1 - w.AvgCloudPct/100
+			if !math.IsNaN(w.MaxPrecipProbPct) && w.MaxPrecipProbPct >= 50 {
+				frac *= 0.5
+				s.Reasons = append(s.Reasons, fmt.Sprintf("pioggia probabile (%.0f%%)", w.MaxPrecipProbPct))
+			}
+			add(50, frac)
+			if w.AvgCloudPct >= 50 {
+				s.Reasons = append(s.Reasons, fmt.Sprintf("nuvolosità media %.0f%%", w.AvgCloudPct))
+			}
+		} else {
+			s.Reasons = append(s.Reasons, "nuvolosità non disponibile")
+		}
+		if !math.IsNaN(w.MaxWindMS) {
+			add(10, (10-w.MaxWindMS)/7) // pieno fino a 3 m/s, zero a 10 m/s
+			if w.MaxWindMS >= 8 {
+				s.Reasons = append(s.Reasons, fmt.Sprintf("vento fino a %.0f m/s", w.MaxWindMS))
+			}
+		}
+		if !math.IsNaN(w.AvgTransparencyPct) {
+			add(10, w.AvgTransparencyPct/100)
+		}
+		if w.MaxDewLevel >= 3 {
+			s.Reasons = append(s.Reasons, "rischio "+DewRiskLabel(w.MaxDewLevel, w.Frost)+" di rugiada: accendi le fasce")
+		}
+	} else {
+		s.Reasons = append(s.Reasons, "previsione meteo non disponibile")
+	}
+
+	if s.Kp != nil && s.Kp.Kp >= 5 {
+		s.Reasons = append(s.Reasons, fmt.Sprintf("Kp %.1f: %s, possibile aurora", s.Kp.Kp, KpLabel(s.Kp.Kp)))
+	}
+
+	if total > 0 {
+		s.Score = int(math.Round(100 * got / total))
+	}
+	switch {
+	case s.Score >= 65 && cloudKnown:
+		s.Verdict = "GO"
+	case s.Score >= 40:
+		s.Verdict = "FORSE"
+	default:
+		s.Verdict = "NO-GO"
+	}
+}
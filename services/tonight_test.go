@@ -0,0 +1,29 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+)
+
+func TestScoreTonightWithoutClouds(t *testing.T) {
+	// notte buia e senza vento ma nuvolosità mancante: niente GO
+	s := &models.TonightSummary{
+		Dark:          &models.NightWindow{},
+		MoonlessHours: 6,
+		Weather: &models.NightForecast{
+			AvgCloudPct:        math.NaN(),
+			MaxPrecipProbPct:   math.NaN(),
+			MaxWindMS:          2,
+			AvgTransparencyPct: 100,
+		},
+	}
+	scoreTonight(s)
+	if s.Score != 100 {
+		t.Errorf("punteggio = %d, atteso 100", s.Score)
+	}
+	if s.Verdict == "GO" {
+		t.Errorf("verdetto GO senza nuvolosità, motivi: %v", s.Reasons)
+	}
+}
@@ -4,97 +4,308 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"math"
 	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
 )
 
-// DeviceLocation rappresenta la posizione geografica del dispositivo
-type DeviceLocation struct {
-	Lat float64
-	Lon float64
-}
+// forecastMaxDays è l'orizzonte massimo delle previsioni orarie
+const forecastMaxDays = 7
+
+// openMeteoHourly sono le variabili orarie richieste a Open-Meteo
+const openMeteoHourly = "temperature_2m,dew_point_2m,relative_humidity_2m," +
+	"cloud_cover,cloud_cover_low,cloud_cover_mid,cloud_cover_high," +
+	"visibility,wind_speed_10m,wind_gusts_10m,precipitation_probability," +
+	"wind_speed_200hPa,wind_speed_250hPa," +
+	"temperature_850hPa,temperature_700hPa,temperature_500hPa," +
+	"relative_humidity_850hPa,relative_humidity_700hPa,relative_humidity_500hPa"
 
-// ipAPIResponse è la risposta dal servizio ip-api.com
-type ipAPIResponse struct {
-	Status  string  `json:"status"`
-	Message string  `json:"message"`
-	Lat     float64 `json:"lat"`
-	Lon     float64 `json:"lon"`
+// WeatherResponse è la risposta dal servizio Open-Meteo.
+// Gli orari sono Unix (nessuna ambiguità ai cambi d'ora), il fuso del sito
+// arriva a parte (timezone=auto); il vento è in m/s.
+type WeatherResponse struct {
+	Timezone         string `json:"timezone"`
+	UTCOffsetSeconds int    `json:"utc_offset_seconds"`
+	Hourly           struct {
+		Time        []int64    `json:"time"`
+		Temperature []*float64 `json:"temperature_2m"`
+		DewPoint    []*float64 `json:"dew_point_2m"`
+		Humidity    []*float64 `json:"relative_humidity_2m"`
+		CloudCover  []*float64 `json:"cloud_cover"`
+		CloudLow    []*float64 `json:"cloud_cover_low"`
+		CloudMid    []*float64 `json:"cloud_cover_mid"`
+		CloudHigh   []*float64 `json:"cloud_cover_high"`
+		Visibility  []*float64 `json:"visibility"`
+		WindSpeed   []*float64 `json:"wind_speed_10m"`
+		WindGusts   []*float64 `json:"wind_gusts_10m"`
+		PrecipProb  []*float64 `json:"precipitation_probability"`
+		Wind200     []*float64 `json:"wind_speed_200hPa"`
+		Wind250     []*float64 `json:"wind_speed_250hPa"`
+		Temp850     []*float64 `json:"temperature_850hPa"`
+		Temp700     []*float64 `json:"temperature_700hPa"`
+		Temp500     []*float64 `json:"temperature_500hPa"`
+		RH850       []*float64 `json:"relative_humidity_850hPa"`
+		RH700       []*float64 `json:"relative_humidity_700hPa"`
+		RH500       []*float64 `json:"relative_humidity_500hPa"`
+	} `json:"hourly"`
 }
 
-// AutoLocateDevice prova a stimare la posizione dal IP pubblico
-// usando il servizio ip-api.com.
-// NON è GPS, ma su Android di solito ti dà una posizione abbastanza vicina.
-func AutoLocateDevice() (*DeviceLocation, error) {
-	const url = "http://ip-api.com/json/"
+// FetchWeather scarica le previsioni orarie di Open-Meteo per i prossimi
+// days giorni (1..7).
+func FetchWeather(lat, lon float64, days int) (*WeatherResponse, error) {
+	days = max(1, min(days, forecastMaxDays))
+	url := fmt.Sprintf(
+		"https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&hourly=%s&wind_speed_unit=ms&forecast_days=%d&timezone=auto&timeformat=unixtime",
+		lat, lon, openMeteoHourly, days,
+	)
 
-	resp, err := http.Get(url)
+	resp, err := httpClient.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("errore richiesta IP-geo: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Open-Meteo: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var wr WeatherResponse
+	if err := json.Unmarshal(data, &wr); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Weather] Meteo ricevuto: %d ore, fuso %s\n", len(wr.Hourly.Time), wr.Timezone)
+
+	return &wr, nil
+}
+
+// FetchForecast scarica e converte le previsioni orarie per il sito.
+func FetchForecast(lat, lon float64, days int) (*models.WeatherForecast, error) {
+	wr, err := FetchWeather(lat, lon, days)
 	if err != nil {
-		return nil, fmt.Errorf("errore lettura risposta IP-geo: %w", err)
+		return nil, err
 	}
+	fc, err := wr.Forecast(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	return &fc, nil
+}
 
-	var data ipAPIResponse
-	if err := json.Unmarshal(body, &data); err != nil {
-		return nil, fmt.Errorf("errore decode JSON IP-geo: %w", err)
+// siteLocation restituisce il fuso del sito: quello IANA se disponibile
+// (gestisce i cambi d'ora), altrimenti l'offset fisso della risposta.
+func (wr *WeatherResponse) siteLocation() *time.Location {
+	if wr.Timezone != "" {
+		if loc, err := time.LoadLocation(wr.Timezone); err == nil {
+			return loc
+		}
 	}
+	return time.FixedZone(wr.Timezone, wr.UTCOffsetSeconds)
+}
+
+// Forecast converte la risposta grezza in ore espresse nel fuso del sito.
+// I valori mancanti (null) diventano NaN.
+func (wr *WeatherResponse) Forecast(lat, lon float64) (models.WeatherForecast, error) {
+	loc := wr.siteLocation()
+	fc := models.WeatherForecast{Lat: lat, Lon: lon, Timezone: wr.Timezone}
 
-	if data.Status != "success" {
-		if data.Message != "" {
-			return nil, fmt.Errorf("IP-geo fallita: %s", data.Message)
+	h := wr.Hourly
+	at := func(vals []*float64, i int) float64 {
+		if i >= len(vals) || vals[i] == nil {
+			return math.NaN()
 		}
-		return nil, fmt.Errorf("IP-geo fallita con stato: %s", data.Status)
+		return *vals[i]
 	}
 
-	return &DeviceLocation{
-		Lat: data.Lat,
-		Lon: data.Lon,
-	}, nil
+	for i, ts := range h.Time {
+		t := time.Unix(ts, 0).In(loc)
+		hour := models.WeatherHour{
+			Time:          t,
+			TemperatureC:  at(h.Temperature, i),
+			DewPointC:     at(h.DewPoint, i),
+			HumidityPct:   at(h.Humidity, i),
+			CloudCoverPct: at(h.CloudCover, i),
+			CloudLowPct:   at(h.CloudLow, i),
+			CloudMidPct:   at(h.CloudMid, i),
+			CloudHighPct:  at(h.CloudHigh, i),
+			VisibilityM:   at(h.Visibility, i),
+			WindSpeedMS:   at(h.WindSpeed, i),
+			WindGustsMS:   at(h.WindGusts, i),
+			PrecipProbPct: at(h.PrecipProb, i),
+			Wind200MS:     at(h.Wind200, i),
+			Wind250MS:     at(h.Wind250, i),
+			Temp850C:      at(h.Temp850, i),
+			Temp700C:      at(h.Temp700, i),
+			Temp500C:      at(h.Temp500, i),
+			RH850Pct:      at(h.RH850, i),
+			RH700Pct:      at(h.RH700, i),
+			RH500Pct:      at(h.RH500, i),
+		}
+		hour.SeeingArcsec = EstimateSeeing(hour)
+		hour.TransparencyPct = EstimateTransparency(hour)
+		fc.Hours = append(fc.Hours, hour)
+	}
+	if len(fc.Hours) == 0 {
+		return fc, fmt.Errorf("dati meteo non disponibili")
+	}
+	return fc, nil
 }
 
-// WeatherResponse è la risposta dal servizio Open-Meteo
-type WeatherResponse struct {
-	Hourly struct {
-		Time       []string  `json:"time"`
-		CloudCover []float64 `json:"cloud_cover"`
-		Humidity   []float64 `json:"relative_humidity_2m"`
-		WindSpeed  []float64 `json:"wind_speed_10m"`
-	} `json:"hourly"`
+// CurrentHourIndex restituisce l'indice dell'ora di previsione che contiene
+// t (l'ultima che inizia non dopo t), o -1 se t è fuori dalla previsione.
+func CurrentHourIndex(fc models.WeatherForecast, t time.Time) int {
+	idx := sort.Search(len(fc.Hours), func(i int) bool { return fc.Hours[i].Time.After(t) }) - 1
+	if idx < 0 || t.Sub(fc.Hours[idx].Time) >= time.Hour {
+		return -1
+	}
+	return idx
 }
 
-// FetchWeather scarica le previsioni meteo da Open-Meteo
-func FetchWeather(lat, lon float64) (*WeatherResponse, error) {
-	url := fmt.Sprintf(
-		"https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&hourly=cloud_cover,relative_humidity_2m,wind_speed_10m&forecast_days=1&timezone=auto",
-		lat, lon,
-	)
+// NightForecasts riassume la previsione notte per notte. La notte è il buio
+// astronomico del sito (o tramonto-alba dove non fa buio); si considerano
+// solo le notti coperte almeno in parte dalla previsione e le ore dalla
+// notte in corso in poi.
+func NightForecasts(fc models.WeatherForecast, lat, lon float64, now time.Time) []models.NightForecast {
+	if len(fc.Hours) == 0 {
+		return nil
+	}
+	loc := fc.Hours[0].Time.Location()
+	first := NightDay(now.In(loc))
+	last := fc.Hours[len(fc.Hours)-1].Time
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
+	var out []models.NightForecast
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		start, end, ok := AstronomicalNight(day, lat, lon)
+		dark := true
+		if !ok {
+			start, end, ok = DarkWindow(day, lat, lon, SunriseAlt)
+			dark = false
+		}
+		if !ok {
+			continue // giorno polare
+		}
+
+		n := summarizeNight(fc.Hours, start, end)
+		if n.Hours == 0 {
+			continue
+		}
+		n.Date = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+		n.Astronomical = dark
+		out = append(out, n)
 	}
-	defer resp.Body.Close()
+	return out
+}
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// summarizeNight aggrega le ore che cadono in [start, end).
+func summarizeNight(hours []models.WeatherHour, start, end time.Time) models.NightForecast {
+	n := models.NightForecast{
+		Start:            start,
+		End:              end,
+		MinTemperatureC:  math.Inf(1),
+		MinDewSpreadC:    math.Inf(1),
+		MinVisibilityKm:  math.Inf(1),
+		MaxGustMS:        math.Inf(-1),
+		MaxPrecipProbPct: math.Inf(-1),
 	}
 
-	var wr WeatherResponse
-	if err := json.Unmarshal(data, &wr); err != nil {
-		return nil, err
+	var cloudSum, seeingSum, transpSum float64
+	var cloudN, seeingN, transpN int
+	for _, h := range hours {
+		// un'ora conta se il suo intervallo [t, t+1h) interseca la notte
+		if !h.Time.Add(time.Hour).After(start) || !h.Time.Before(end) {
+			continue
+		}
+		n.Hours++
+		if !math.IsNaN(h.CloudCoverPct) {
+			cloudSum += h.CloudCoverPct
+			cloudN++
+			if h.CloudCoverPct < clearSkyCloudPct && !(h.PrecipProbPct >= clearSkyPrecipPct) {
+				n.ClearHours++
+			}
+		}
+		n.MinTemperatureC = minNaN(n.MinTemperatureC, h.TemperatureC)
+		n.MinDewSpreadC = minNaN(n.MinDewSpreadC, h.TemperatureC-h.DewPointC)
+		n.MinVisibilityKm = minNaN(n.MinVisibilityKm, h.VisibilityM/1000)
+		n.MaxGustMS = maxNaN(n.MaxGustMS, h.WindGustsMS)
+		n.MaxPrecipProbPct = maxNaN(n.MaxPrecipProbPct, h.PrecipProbPct)
+		n.MaxWindMS = maxNaN(n.MaxWindMS, h.WindSpeedMS)
+		if !math.IsNaN(h.SeeingArcsec) {
+			seeingSum += h.SeeingArcsec
+			seeingN++
+		}
+		if !math.IsNaN(h.TransparencyPct) {
+			transpSum += h.TransparencyPct
+			transpN++
+		}
+		dew := AssessDew(h)
+		n.MaxDewLevel = max(n.MaxDewLevel, dew.Level)
+		n.Frost = n.Frost || dew.Frost
 	}
+	n.DewWarnings = DewWarnings(hours, start, end)
 
-	fmt.Printf("Meteo ricevuto: time=%d cloud=%d hum=%d wind=%d\n",
-		len(wr.Hourly.Time),
-		len(wr.Hourly.CloudCover),
-		len(wr.Hourly.Humidity),
-		len(wr.Hourly.WindSpeed),
-	)
+	n.AvgCloudPct = meanOrNaN(cloudSum, cloudN)
+	n.AvgSeeingArcsec = meanOrNaN(seeingSum, seeingN)
+	n.AvgTransparencyPct = meanOrNaN(transpSum, transpN)
+	for _, v := range []*float64{&n.MinTemperatureC, &n.MinDewSpreadC, &n.MinVisibilityKm, &n.MaxGustMS, &n.MaxPrecipProbPct} {
+		if math.IsInf(*v, 0) {
+			*v = math.NaN()
+		}
+	}
+	n.Quality = SkyQuality(n.AvgCloudPct, n.MaxWindMS)
+	return n
+}
 
-	return &wr, nil
+// Soglie per le ore "serene" del riassunto notturno
+const (
+	clearSkyCloudPct  = 20.0
+	clearSkyPrecipPct = 20.0
+)
+
+// meanOrNaN è la media di n valori, NaN se non ce ne sono.
+func meanOrNaN(sum float64, n int) float64 {
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
+
+// minNaN/maxNaN ignorano i valori mancanti.
+func minNaN(acc, v float64) float64 {
+	if math.IsNaN(v) {
+		return acc
+	}
+	return math.Min(acc, v)
+}
+
+func maxNaN(acc, v float64) float64 {
+	if math.IsNaN(v) {
+		return acc
+	}
+	return math.Max(acc, v)
+}
+
+// SkyQuality classifica il cielo per l'osservazione in base a copertura
+// nuvolosa (%) e vento (m/s).
+func SkyQuality(cloudCover, windSpeed float64) string {
+	switch {
+	case math.IsNaN(cloudCover):
+		return "⚪ Dati non disponibili"
+	case cloudCover < 20 && windSpeed < 4:
+		return "🔵 Cielo ottimo per foto"
+	case cloudCover < 40 && windSpeed < 6:
+		return "🟢 Cielo buono"
+	case cloudCover < 70:
+		return "🟡 Cielo mediocre"
+	default:
+		return "🔴 Cielo poco adatto"
+	}
 }
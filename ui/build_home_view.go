@@ -1,22 +1,268 @@
 package ui
 
 import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 )
 
-// BuildHomeView restituisce la vista Home.
-func BuildHomeView() fyne.CanvasObject {
-	title := widget.NewLabelWithStyle("Astro-Lair Go Edition", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
-	sub := widget.NewLabel("Versione di prova in Go + Fyne.\nQui potrai avere riepiloghi rapidi della serata, setup strumenti, ecc.")
-	sub.Wrapping = fyne.TextWrapWord
-
-	return container.NewVBox(
-		layout.NewSpacer(),
-		title,
-		sub,
-		layout.NewSpacer(),
+// =======================
+//  Home: riepilogo "Stanotte"
+// =======================
+
+// homeMaxTargets è il numero di target consigliati mostrati in Home
+const homeMaxTargets = 8
+
+// homeStaleAfter: entrando nella tab il riepilogo si ricalcola solo se è più
+// vecchio di così (o se il sito è cambiato); "Aggiorna" forza sempre.
+const homeStaleAfter = 15 * time.Minute
+
+// BuildHomeView restituisce la vista Home con il riepilogo della notte al
+// sito salvato e la funzione da chiamare quando la tab diventa visibile.
+// targets fornisce il catalogo caricato, navigate apre la tab indicata.
+func BuildHomeView(targets func() []models.TargetObject, navigate func(tab string)) (fyne.CanvasObject, func()) {
+	return buildHomeView(targets, navigate)
+}
+
+func buildHomeView(targets func() []models.TargetObject, navigate func(tab string)) (fyne.CanvasObject, func()) {
+	headerLabel := widget.NewLabel("")
+	headerLabel.Wrapping = fyne.TextWrapWord
+
+	loading := widget.NewProgressBarInfinite()
+	loading.Hide()
+
+	verdictText := canvas.NewText("…", color.White)
+	verdictText.TextSize = 28
+	verdictText.TextStyle = fyne.TextStyle{Bold: true}
+	verdictBg := canvas.NewRectangle(gridMissing)
+	verdictBg.CornerRadius = 6
+	reasonsLabel := widget.NewLabel("")
+	reasonsLabel.Wrapping = fyne.TextWrapWord
+
+	sunLabel := widget.NewLabel("")
+	moonLabel := widget.NewLabel("")
+	moonLabel.Wrapping = fyne.TextWrapWord
+	weatherLabel := widget.NewLabel("")
+	weatherLabel.Wrapping = fyne.TextWrapWord
+	weatherGrid := container.NewStack()
+	kpLabel := widget.NewLabel("")
+	kpLabel.Wrapping = fyne.TextWrapWord
+	targetsLabel := widget.NewLabel("")
+	targetsLabel.Wrapping = fyne.TextWrapWord
+
+	// card con il pulsante che porta alla tab di dettaglio
+	card := func(title, tab string, content ...fyne.CanvasObject) fyne.CanvasObject {
+		open := widget.NewButton("Apri "+tab+" ›", func() { navigate(tab) })
+		content = append(content, container.NewHBox(open))
+		return widget.NewCard(title, "", container.NewVBox(content...))
+	}
+
+	var (
+		mu       sync.Mutex
+		running  bool
+		again    bool // richiesta forzata arrivata durante un calcolo
+		lastAt   time.Time
+		lastLat  float64
+		lastLon  float64
+		hasValue bool
+	)
+
+	show := func(s models.TonightSummary) {
+		loc := siteLocation()
+		site := currentSite()
+		headerLabel.SetText(fmt.Sprintf("Notte del %s — %s\n%s",
+			s.Date.Format("Mon 02/01"), site.Name, formatSiteSummary(site)))
+
+		verdictText.Text = fmt.Sprintf("%s  %d/100", s.Verdict, s.Score)
+		verdictBg.FillColor = verdictColor(s.Verdict)
+		verdictText.Refresh()
+		verdictBg.Refresh()
+		reasonsLabel.SetText(strings.Join(s.Reasons, "\n"))
+
+		sunLabel.SetText(formatSunEvents(s.Sun, loc))
+		moonLabel.SetText(formatTonightMoon(s, loc))
+		weatherLabel.SetText(formatTonightWeather(s))
+		if len(s.Hours) > 0 {
+			weatherGrid.Objects = []fyne.CanvasObject{buildConditionsGrid(s.Hours)}
+		} else {
+			weatherGrid.Objects = nil
+		}
+		weatherGrid.Refresh()
+		kpLabel.SetText(formatTonightKp(s.Kp, loc))
+		targetsLabel.SetText(formatTonightTargets(s, loc))
+	}
+
+	var refresh func(force bool)
+	refresh = func(force bool) {
+		site := currentSite()
+		lat, lon := site.Lat, site.Lon
+		mu.Lock()
+		if running {
+			again = again || force
+			mu.Unlock()
+			return
+		}
+		if !force && hasValue && time.Since(lastAt) < homeStaleAfter && lat == lastLat && lon == lastLon {
+			mu.Unlock()
+			return
+		}
+		running = true
+		mu.Unlock()
+
+		loading.Show()
+		loc := siteLocation()
+		var catalog []models.TargetObject
+		if targets != nil {
+			catalog = targets()
+		}
+
+		go func() {
+			in := services.TonightInputs{Targets: catalog, MaxTargets: homeMaxTargets, Horizon: site.Horizon}
+
+			// meteo e Kp in parallelo: ognuno può mancare
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				if fc, err := services.FetchForecast(lat, lon, 2); err == nil {
+					in.Forecast = fc
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				if kp, err := services.FetchLatestKp(); err == nil {
+					in.Kp = &kp
+				}
+			}()
+			wg.Wait()
+
+			s := services.PlanTonight(time.Now().In(loc), lat, lon, in)
+
+			fyne.Do(func() {
+				mu.Lock()
+				running = false
+				lastAt, lastLat, lastLon = time.Now(), lat, lon
+				hasValue = true
+				rerun := again
+				again = false
+				mu.Unlock()
+
+				loading.Hide()
+				show(s)
+				if rerun {
+					refresh(true)
+				}
+			})
+		}()
+	}
+
+	refreshBtn := widget.NewButton("Aggiorna", func() { refresh(true) })
+
+	verdictBox := container.NewStack(verdictBg, container.NewPadded(container.NewCenter(verdictText)))
+
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Stanotte", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		container.NewBorder(nil, nil, nil, refreshBtn, headerLabel),
+		loading,
+		verdictBox,
+		reasonsLabel,
+		card("🌅 Sole e crepuscoli", "Moon", sunLabel),
+		card("🌙 Luna", "Moon", moonLabel),
+		card("☁️ Meteo della notte", "Weather", weatherLabel, weatherGrid),
+		card("🧲 Attività geomagnetica", "SpaceWeather", kpLabel),
+		card("🔭 Target consigliati", "Targets", targetsLabel),
 	)
+
+	refresh(true)
+	onSiteChanged(func() { refresh(true) })
+	return container.NewVScroll(content), func() { refresh(false) }
+}
+
+// verdictColor colora il verdetto come la griglia meteo (blu ottimo, rosso pessimo).
+func verdictColor(verdict string) color.Color {
+	switch verdict {
+	case "GO":
+		return gridPalette[0]
+	case "FORSE":
+		return gridPalette[3]
+	default:
+		return gridPalette[4]
+	}
+}
+
+// formatTonightMoon descrive fase, sorgere/tramonto e buio senza Luna.
+func formatTonightMoon(s models.TonightSummary, loc *time.Location) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "%s, illuminata al %.0f%%\n", s.Moon.PhaseName, s.Moon.Illumination*100)
+	fmt.Fprintf(sb, "Sorge %s • Tramonta %s\n",
+		formatOptionalEvent(s.MoonRise, s.Date.In(loc)), formatOptionalEvent(s.MoonSet, s.Date.In(loc)))
+
+	switch {
+	case s.Dark == nil:
+		sb.WriteString("Niente buio astronomico stanotte.")
+	case len(s.MoonlessWindows) == 0:
+		sb.WriteString("Nessuna finestra di buio senza Luna.")
+	default:
+		spans := make([]string, len(s.MoonlessWindows))
+		for i, w := range s.MoonlessWindows {
+			spans[i] = w.Start.In(loc).Format("15:04") + "–" + w.End.In(loc).Format("15:04")
+		}
+		fmt.Fprintf(sb, "Buio senza Luna: %s (%.1f h)", strings.Join(spans, ", "), s.MoonlessHours)
+	}
+	return sb.String()
+}
+
+// formatTonightWeather riassume la previsione della notte.
+func formatTonightWeather(s models.TonightSummary) string {
+	w := s.Weather
+	if w == nil {
+		return "Previsione non disponibile: controlla la connessione o aggiorna dalla tab Weather."
+	}
+	seeing := "—"
+	if !math.IsNaN(w.AvgSeeingArcsec) {
+		seeing = fmt.Sprintf("%.1f″", w.AvgSeeingArcsec)
+	}
+	return fmt.Sprintf("Nuvole %s in media, %d/%d ore serene • vento max %s, raffiche %s\nSeeing %s • trasparenza %s • T min %s • rugiada: %s\nCielo: %s",
+		fmtPct(w.AvgCloudPct), w.ClearHours, w.Hours, fmtWind(w.MaxWindMS), fmtWind(w.MaxGustMS),
+		seeing, fmtPct(w.AvgTransparencyPct), fmtTemp(w.MinTemperatureC),
+		services.DewRiskLabel(w.MaxDewLevel, w.Frost), w.Quality)
+}
+
+// formatTonightKp descrive l'ultimo indice Kp.
+func formatTonightKp(kp *models.KpReading, loc *time.Location) string {
+	if kp == nil {
+		return "Indice Kp non disponibile."
+	}
+	return fmt.Sprintf("Kp %.1f (%s) — misura delle %s", kp.Kp, services.KpLabel(kp.Kp), kp.Time.In(loc).Format("15:04 02/01"))
+}
+
+// formatTonightTargets elenca i target consigliati.
+func formatTonightTargets(s models.TonightSummary, loc *time.Location) string {
+	if s.Dark == nil {
+		return "Niente buio astronomico: nessun target consigliato."
+	}
+	if len(s.Targets) == 0 {
+		return "Nessun target del catalogo caricato è ben posizionato stanotte."
+	}
+	lines := make([]string, len(s.Targets))
+	for i, t := range s.Targets {
+		name := t.Target.Code
+		if t.Target.Name != "" {
+			name += " " + t.Target.Name
+		}
+		lines[i] = fmt.Sprintf("%s (mag %s) — %.1f h sopra 30°, max %.0f° alle %s",
+			name, formatMagnitude(t.Target.Magnitude), t.HoursAbove, t.MaxAltDeg, t.MaxAltTime.In(loc).Format("15:04"))
+	}
+	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+)
+
+// =======================
+//  Avvisi rugiada e potenza delle fasce
+// =======================
+
+// formatDewWarnings descrive gli intervalli a rischio rugiada di una notte,
+// con la potenza consigliata per le fasce del primario.
+func formatDewWarnings(n models.NightForecast, heaters []DewHeater) string {
+	title := "Rugiada — notte del " + n.Date.Format("Mon 02/01") + ": "
+	if len(n.DewWarnings) == 0 {
+		if n.MaxDewLevel == 1 {
+			return title + "rischio basso, fasce al minimo (25%) per sicurezza."
+		}
+		return title + "nessun rischio rilevante."
+	}
+
+	loc := n.Date.Location()
+	lines := []string{title}
+	for _, w := range n.DewWarnings {
+		line := fmt.Sprintf("  ⚠ %s–%s rischio %s → fasce al %.0f%%",
+			w.Start.In(loc).Format("15:04"), w.End.In(loc).Format("15:04"),
+			services.DewRiskLabel(w.MaxLevel, w.Frost), w.MaxHeaterPct)
+		if len(heaters) > 0 {
+			parts := make([]string, len(heaters))
+			for i, h := range heaters {
+				parts[i] = fmt.Sprintf("%s %.1f W", h.Name, h.PowerW*w.MaxHeaterPct/100)
+			}
+			line += " (" + strings.Join(parts, ", ") + ")"
+		}
+		lines = append(lines, line)
+	}
+	if len(heaters) == 0 {
+		lines = append(lines, "  Aggiungi le fasce anticondensa del primario nelle impostazioni (⚙️) per la potenza in watt.")
+	}
+	return strings.Join(lines, "\n")
+}
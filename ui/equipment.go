@@ -1,13 +1,18 @@
 package ui
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 
+	"github.com/cr4sh87/astro-lair-go/services"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -19,11 +24,24 @@ type EquipmentConfig struct {
 	SecondaryName        string
 	ImagingCamera        string
 	GuideCamera          string
+	PrimaryHeaters       []DewHeater // fasce anticondensa montate sul primario
+}
+
+// DewHeater è una fascia anticondensa con la sua potenza nominale.
+type DewHeater struct {
+	Name   string
+	PowerW float64
 }
 
+// prefEquipment è la chiave delle Preferences con la configurazione in JSON
+const prefEquipment = "equipment.config"
+
 // Global reference to equipment config (set from main)
 var equipmentConfigRef *EquipmentConfig
 
+// equipmentListeners sono chiamati dopo il salvataggio del dialogo
+var equipmentListeners []func()
+
 // SetEquipmentConfig sets the global equipment config reference
 func SetEquipmentConfig(cfg *EquipmentConfig) {
 	equipmentConfigRef = cfg
@@ -49,6 +67,45 @@ func NewDefaultEquipmentConfig() *EquipmentConfig {
 	}
 }
 
+// LoadEquipmentConfig legge la configurazione salvata nelle Preferences; se
+// manca o non è valida restituisce quella predefinita.
+func LoadEquipmentConfig() *EquipmentConfig {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return NewDefaultEquipmentConfig()
+	}
+	raw := app.Preferences().String(prefEquipment)
+	if raw == "" {
+		return NewDefaultEquipmentConfig()
+	}
+	cfg := &EquipmentConfig{}
+	if err := json.Unmarshal([]byte(raw), cfg); err != nil {
+		log.Printf("[Equipment] configurazione non valida, uso il default: %v", err)
+		return NewDefaultEquipmentConfig()
+	}
+	return cfg
+}
+
+// saveEquipmentConfig scrive la configurazione nelle Preferences.
+func saveEquipmentConfig(cfg *EquipmentConfig) {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		log.Printf("[Equipment] impossibile salvare la configurazione: %v", err)
+		return
+	}
+	app.Preferences().SetString(prefEquipment, string(data))
+}
+
+// onEquipmentChanged registra fn, chiamata dal goroutine UI quando la
+// configurazione viene salvata.
+func onEquipmentChanged(fn func()) {
+	equipmentListeners = append(equipmentListeners, fn)
+}
+
 // WindowAccessor interface for testability
 type WindowAccessor interface {
 	GetWindow() fyne.Window
@@ -80,12 +137,22 @@ func showEquipmentDialog(win fyne.Window) {
 	guideCam := widget.NewEntry()
 	guideCam.SetText(eq.GuideCamera)
 
+	heaters := widget.NewMultiLineEntry()
+	heaters.SetText(formatHeaters(eq.PrimaryHeaters))
+	heaters.SetPlaceHolder("una per riga, es.\nFascia tubo: 12\nFascia cercatore: 5")
+	heaters.SetMinRowsVisible(3)
+	heaters.Validator = func(text string) error {
+		_, err := parseHeaters(text)
+		return err
+	}
+
 	form := container.NewVBox(
 		widget.NewLabelWithStyle("Strumento Primario", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		widget.NewForm(
 			widget.NewFormItem("Nome", primaryName),
 			widget.NewFormItem("Focale (mm)", primaryFocal),
 			widget.NewFormItem("Rapporto focale (f/)", primaryRatio),
+			widget.NewFormItem("Fasce anticondensa (W)", heaters),
 		),
 		widget.NewSeparator(),
 		widget.NewLabelWithStyle("Strumento Secondario", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
@@ -100,30 +167,72 @@ func showEquipmentDialog(win fyne.Window) {
 		),
 	)
 
-	dialog.NewCustomConfirm(
-		"Configurazione Strumentazione",
-		"Salva",
-		"Annulla",
-		form,
-		func(ok bool) {
-			if !ok {
-				return
-			}
-
-			eq.PrimaryName = primaryName.Text
-			eq.SecondaryName = secondaryName.Text
-			eq.ImagingCamera = imagingCam.Text
-			eq.GuideCamera = guideCam.Text
-
-			if v, err := strconv.ParseFloat(strings.ReplaceAll(primaryFocal.Text, ",", "."), 64); err == nil {
-				eq.PrimaryFocalLengthMm = v
-			}
-			if v, err := strconv.ParseFloat(strings.ReplaceAll(primaryRatio.Text, ",", "."), 64); err == nil {
-				eq.PrimaryFocalRatio = v
-			}
-		},
-		win,
-	).Show()
+	// il dialogo resta aperto finché le fasce non sono valide, per non
+	// perdere il testo inserito
+	d := dialog.NewCustomWithoutButtons("Configurazione Strumentazione", form, win)
+	saveBtn := widget.NewButtonWithIcon("Salva", theme.ConfirmIcon(), func() {
+		hs, err := parseHeaters(heaters.Text)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+
+		eq.PrimaryName = primaryName.Text
+		eq.SecondaryName = secondaryName.Text
+		eq.ImagingCamera = imagingCam.Text
+		eq.GuideCamera = guideCam.Text
+		eq.PrimaryHeaters = hs
+
+		if v, err := strconv.ParseFloat(strings.ReplaceAll(primaryFocal.Text, ",", "."), 64); err == nil {
+			eq.PrimaryFocalLengthMm = v
+		}
+		if v, err := strconv.ParseFloat(strings.ReplaceAll(primaryRatio.Text, ",", "."), 64); err == nil {
+			eq.PrimaryFocalRatio = v
+		}
+
+		d.Hide()
+		saveEquipmentConfig(eq)
+		for _, fn := range equipmentListeners {
+			fn()
+		}
+	})
+	saveBtn.Importance = widget.HighImportance
+	d.SetButtons([]fyne.CanvasObject{
+		widget.NewButtonWithIcon("Annulla", theme.CancelIcon(), d.Hide),
+		saveBtn,
+	})
+	d.Show()
+}
+
+// parseHeaters legge le fasce anticondensa, una per riga nel formato "nome: watt".
+func parseHeaters(text string) ([]DewHeater, error) {
+	var out []DewHeater
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		i := strings.LastIndex(line, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("fascia %q: usa il formato \"nome: watt\"", line)
+		}
+		watt := strings.TrimSuffix(strings.TrimSpace(line[i+1:]), "W")
+		w, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(watt), ",", "."), 64)
+		if err != nil || w <= 0 {
+			return nil, fmt.Errorf("fascia %q: potenza non valida", line)
+		}
+		out = append(out, DewHeater{Name: strings.TrimSpace(line[:i]), PowerW: w})
+	}
+	return out, nil
+}
+
+// formatHeaters è l'inverso di parseHeaters.
+func formatHeaters(hs []DewHeater) string {
+	lines := make([]string, len(hs))
+	for i, h := range hs {
+		lines[i] = fmt.Sprintf("%s: %g", h.Name, h.PowerW)
+	}
+	return strings.Join(lines, "\n")
 }
 
 // BuildToolsView — Tools UI
@@ -141,6 +250,22 @@ func buildToolsView() fyne.CanvasObject {
 	heightPxEntry := widget.NewEntry()
 	heightPxEntry.SetText("3520")
 
+	// seeing per il confronto con la scala: dalla previsione meteo se c'è
+	seeingEntry := widget.NewEntry()
+	seeingEntry.SetPlaceHolder("es. 2.0")
+	seeingHint := widget.NewLabel("")
+	seeingHint.Wrapping = fyne.TextWrapWord
+	useForecastSeeing := func() {
+		if v, night, ok := lastForecastSeeing(); ok {
+			seeingEntry.SetText(fmt.Sprintf("%.1f", v))
+			seeingHint.SetText(fmt.Sprintf("Seeing medio stimato per la notte del %s (tab Weather).", night.Format("02/01")))
+		} else {
+			seeingHint.SetText("Nessuna previsione: aggiorna il meteo nella tab Weather o inserisci il seeing a mano.")
+		}
+	}
+	useForecastSeeing()
+	seeingBtn := widget.NewButton("Usa previsione", useForecastSeeing)
+
 	resultLabel := widget.NewLabel("Inserisci i dati e premi \"Calcola\".")
 	resultLabel.Wrapping = fyne.TextWrapWord
 
@@ -176,18 +301,28 @@ func buildToolsView() fyne.CanvasObject {
 		fovWidthDeg := 57.2958 * sensorWidthMm / fLen
 		fovHeightDeg := 57.2958 * sensorHeightMm / fLen
 
-		resultLabel.SetText(fmt.Sprintf(
+		text := fmt.Sprintf(
 			"Scala di campionamento: %.2f\"/px\nFOV: %.2f° × %.2f° (≈ %.1f' × %.1f')",
 			scale,
 			fovWidthDeg, fovHeightDeg,
 			fovWidthDeg*60.0, fovHeightDeg*60.0,
-		))
+		)
+
+		// confronto con il seeing (campo facoltativo)
+		seeingStr := strings.ReplaceAll(strings.TrimSpace(seeingEntry.Text), ",", ".")
+		if seeing, err := strconv.ParseFloat(seeingStr, 64); err == nil && seeing > 0 {
+			px, advice := services.SamplingAdvice(seeing, scale)
+			text += fmt.Sprintf("\nSeeing %.1f\" → %.1f px per FWHM: %s", seeing, px, advice)
+		}
+
+		resultLabel.SetText(text)
 	})
 
 	form := widget.NewForm(
 		widget.NewFormItem("Pixel size (µm)", pixelSizeEntry),
 		widget.NewFormItem("Larghezza sensore (px)", widthPxEntry),
 		widget.NewFormItem("Altezza sensore (px)", heightPxEntry),
+		widget.NewFormItem("Seeing (\")", container.NewBorder(nil, nil, nil, seeingBtn, seeingEntry)),
 	)
 
 	return container.NewVBox(
@@ -195,6 +330,7 @@ func buildToolsView() fyne.CanvasObject {
 		widget.NewSeparator(),
 		widget.NewLabel("Calcolo Scala & FOV\n(usando la focale dello strumento primario configurato)"),
 		form,
+		seeingHint,
 		calcBtn,
 		widget.NewSeparator(),
 		resultLabel,
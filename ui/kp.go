@@ -0,0 +1,233 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// =======================
+//  Indice Kp e aurora
+// =======================
+
+// Dimensioni del grafico a barre del Kp (pixel)
+const (
+	kpBarW     = 14
+	kpBarGap   = 2
+	kpChartH   = 120
+	kpAxisW    = 22
+	kpLabelH   = 16
+	kpStormMin = 5 // soglia di tempesta geomagnetica (G1)
+)
+
+// buildKpCard restituisce il riquadro con Kp attuale, grafico delle ultime
+// 24 ore e della previsione a 3 giorni e visibilità dell'aurora dal sito.
+func buildKpCard() fyne.CanvasObject {
+	nowLabel := widget.NewLabel("Scarico l'indice Kp…")
+	nowLabel.Wrapping = fyne.TextWrapWord
+	auroraLabel := widget.NewLabel("")
+	auroraLabel.Wrapping = fyne.TextWrapWord
+	chart := container.NewStack()
+	loading := widget.NewProgressBarInfinite()
+
+	legend := widget.NewLabel("Barre piene: Kp osservato (ultime 24 h) • chiare: stimato/previsto • linea: soglia di tempesta (Kp 5). Orari del sito.")
+	legend.Wrapping = fyne.TextWrapWord
+
+	var outlook *models.KpOutlook
+
+	showAurora := func() {
+		if outlook == nil {
+			return
+		}
+		lat, lon := observerLocation()
+		c := services.AuroraTonight(*outlook, siteNow(), lat, lon)
+		auroraLabel.SetText(formatAuroraChance(currentSite().Name, c, siteLocation()))
+	}
+
+	refresh := func() {
+		loading.Show()
+		go func() {
+			o, err := services.FetchKpOutlook(time.Now())
+			fyne.Do(func() {
+				loading.Hide()
+				if err != nil {
+					nowLabel.SetText("Indice Kp non disponibile: " + err.Error())
+					return
+				}
+				outlook = &o
+				loc := siteLocation()
+				nowLabel.SetText(formatKpNow(o, loc))
+				chart.Objects = []fyne.CanvasObject{container.NewHScroll(buildKpChart(o, loc))}
+				chart.Refresh()
+				showAurora()
+			})
+		}()
+	}
+
+	onSiteChanged(func() {
+		if outlook != nil {
+			loc := siteLocation()
+			nowLabel.SetText(formatKpNow(*outlook, loc))
+			chart.Objects = []fyne.CanvasObject{container.NewHScroll(buildKpChart(*outlook, loc))}
+			chart.Refresh()
+		}
+		showAurora()
+	})
+
+	refreshBtn := widget.NewButton("Aggiorna Kp", refresh)
+	refresh()
+
+	return widget.NewCard("🧲 Indice Kp e aurora", "", container.NewVBox(
+		container.NewBorder(nil, nil, nil, refreshBtn, nowLabel),
+		loading,
+		chart,
+		legend,
+		widget.NewSeparator(),
+		auroraLabel,
+	))
+}
+
+// formatKpNow descrive il Kp attuale e il massimo previsto.
+func formatKpNow(o models.KpOutlook, loc *time.Location) string {
+	sb := &strings.Builder{}
+	if c := o.Current; c != nil {
+		fmt.Fprintf(sb, "Kp attuale %.1f (%s) — %s delle %s",
+			c.Kp, services.KpLabel(c.Kp), kpStatusName(c.Status), c.Time.In(loc).Format("15:04 02/01"))
+	} else {
+		sb.WriteString("Kp attuale non disponibile")
+	}
+	if peak, ok := services.MaxKp(o.Forecast); ok {
+		fmt.Fprintf(sb, "\nMassimo previsto nei prossimi giorni: Kp %.1f (%s) il %s",
+			peak.Kp, services.KpLabel(peak.Kp), peak.Time.In(loc).Format("02/01 alle 15:04"))
+	}
+	return sb.String()
+}
+
+func kpStatusName(status string) string {
+	switch status {
+	case models.KpObserved:
+		return "misura"
+	case models.KpPredicted:
+		return "previsione"
+	default:
+		return "stima"
+	}
+}
+
+// formatAuroraChance riassume la visibilità dell'aurora dal sito stanotte.
+func formatAuroraChance(site string, c models.AuroraChance, loc *time.Location) string {
+	hemi := "N"
+	if c.GeomagLat < 0 {
+		hemi = "S"
+	}
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "🌌 %s: latitudine geomagnetica %.1f° %s.\n", site, math.Abs(c.GeomagLat), hemi)
+	if !c.NoDark && !math.IsNaN(c.Kp) {
+		fmt.Fprintf(sb, "Stanotte Kp fino a %.1f", c.Kp)
+		if !c.PeakTime.IsZero() {
+			fmt.Fprintf(sb, " (dalle %s)", c.PeakTime.In(loc).Format("15:04"))
+		}
+		fmt.Fprintf(sb, ": bordo dell'ovale a %.0f°, visibile all'orizzonte da %.0f°.\n", c.OvalLat, c.ViewLat)
+	}
+	fmt.Fprintf(sb, "Esito: %s.", services.AuroraLabel(c))
+	if c.KpForHorizon <= 9 && !c.OnHorizon {
+		fmt.Fprintf(sb, " Serve Kp ≥ %.1f per vederla bassa all'orizzonte.", c.KpForHorizon)
+	}
+	return sb.String()
+}
+
+// kpColor colora una barra secondo l'intensità (verde quieto … viola G4-G5);
+// stime e previsioni sono più trasparenti.
+func kpColor(r models.KpReading) color.Color {
+	var c color.NRGBA
+	switch {
+	case r.Kp >= 8:
+		c = color.NRGBA{R: 170, G: 60, B: 200, A: 255}
+	case r.Kp >= 6:
+		c = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+	case r.Kp >= 5:
+		c = color.NRGBA{R: 240, G: 140, B: 40, A: 255}
+	case r.Kp >= 4:
+		c = color.NRGBA{R: 230, G: 210, B: 60, A: 255}
+	default:
+		c = color.NRGBA{R: 70, G: 180, B: 90, A: 255}
+	}
+	if r.Status != models.KpObserved {
+		c.A = 130
+	}
+	return c
+}
+
+// buildKpChart disegna storico e previsione come barre da 3 ore (scala 0-9).
+func buildKpChart(o models.KpOutlook, loc *time.Location) fyne.CanvasObject {
+	bars := append(append([]models.KpReading(nil), o.History...), o.Forecast...)
+	width := float32(kpAxisW + len(bars)*(kpBarW+kpBarGap) + kpBarGap)
+	height := float32(kpChartH + kpLabelH)
+	y := func(kp float64) float32 { return kpChartH * float32(1-kp/9) }
+
+	bg := canvas.NewRectangle(color.Transparent)
+	bg.SetMinSize(fyne.NewSize(width, height))
+	objs := []fyne.CanvasObject{bg}
+
+	// griglia: 0, 3, 6, 9 e soglia di tempesta
+	for _, kp := range []float64{0, 3, 6, 9} {
+		line := canvas.NewLine(color.NRGBA{R: 90, G: 90, B: 90, A: 255})
+		line.StrokeWidth = 0.5
+		line.Position1 = fyne.NewPos(kpAxisW, y(kp))
+		line.Position2 = fyne.NewPos(width, y(kp))
+		lbl := canvas.NewText(fmt.Sprintf("%.0f", kp), color.NRGBA{R: 160, G: 160, B: 160, A: 255})
+		lbl.TextSize = 10
+		lbl.Move(fyne.NewPos(4, y(kp)-7))
+		objs = append(objs, line, lbl)
+	}
+	storm := canvas.NewLine(color.NRGBA{R: 240, G: 140, B: 40, A: 200})
+	storm.StrokeWidth = 1
+	storm.Position1 = fyne.NewPos(kpAxisW, y(kpStormMin))
+	storm.Position2 = fyne.NewPos(width, y(kpStormMin))
+	objs = append(objs, storm)
+
+	for i, r := range bars {
+		x := float32(kpAxisW + kpBarGap + i*(kpBarW+kpBarGap))
+		top := y(math.Max(r.Kp, 0.1)) // anche Kp 0 resta visibile
+		bar := canvas.NewRectangle(kpColor(r))
+		bar.Resize(fyne.NewSize(kpBarW, kpChartH-top))
+		bar.Move(fyne.NewPos(x, top))
+		objs = append(objs, bar)
+
+		// data al primo intervallo di ogni giorno locale; sulla prima barra
+		// solo se il giorno dopo non comincia subito (le etichette si sovrapporrebbero)
+		t := r.Time.In(loc)
+		newDay := i > 0 && t.Day() != bars[i-1].Time.In(loc).Day()
+		if i == 0 {
+			newDay = len(bars) < 4 || bars[3].Time.In(loc).Day() == t.Day()
+		}
+		if newDay {
+			lbl := canvas.NewText(t.Format("02/01"), color.NRGBA{R: 200, G: 200, B: 200, A: 255})
+			lbl.TextSize = 10
+			lbl.Move(fyne.NewPos(x, kpChartH+2))
+			objs = append(objs, lbl)
+		}
+	}
+
+	// separatore "adesso" fra storico e previsione
+	if len(o.History) > 0 && len(o.Forecast) > 0 {
+		x := float32(kpAxisW + len(o.History)*(kpBarW+kpBarGap) + kpBarGap/2)
+		now := canvas.NewLine(color.White)
+		now.StrokeWidth = 1
+		now.Position1 = fyne.NewPos(x, 0)
+		now.Position2 = fyne.NewPos(x, kpChartH)
+		objs = append(objs, now)
+	}
+
+	return container.NewWithoutLayout(objs...)
+}
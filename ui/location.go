@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cr4sh87/astro-lair-go/services"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// =============================================================
+//  FONTI DELLA POSIZIONE (Preferences)
+// =============================================================
+
+const (
+	prefLocationOrder     = "location.order"      // fonti separate da virgole
+	prefLocationGpsd      = "location.gpsd"       // host:porta di gpsd
+	prefLocationManualLat = "location.manual.lat" // coordinate manuali (vuote = non impostate)
+	prefLocationManualLon = "location.manual.lon"
+)
+
+// locationSettings sono le fonti di posizione configurate dall'utente.
+type locationSettings struct {
+	order    []string
+	gpsdAddr string
+	manual   *services.DeviceLocation
+}
+
+// loadLocationSettings legge le fonti dalle Preferences; valori mancanti o non
+// validi tornano al default.
+func loadLocationSettings() locationSettings {
+	s := locationSettings{order: append([]string(nil), services.DefaultLocationOrder...)}
+	app := fyne.CurrentApp()
+	if app == nil {
+		return s
+	}
+	p := app.Preferences()
+
+	if order, err := services.ParseLocationOrder(p.String(prefLocationOrder)); err == nil {
+		s.order = order
+	}
+	s.gpsdAddr = p.String(prefLocationGpsd)
+	lat, err1 := strconv.ParseFloat(p.String(prefLocationManualLat), 64)
+	lon, err2 := strconv.ParseFloat(p.String(prefLocationManualLon), 64)
+	if err1 == nil && err2 == nil {
+		s.manual = &services.DeviceLocation{Lat: lat, Lon: lon, ElevationM: math.NaN()}
+	}
+	return s
+}
+
+func saveLocationSettings(s locationSettings) {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return
+	}
+	p := app.Preferences()
+	p.SetString(prefLocationOrder, strings.Join(s.order, ", "))
+	p.SetString(prefLocationGpsd, s.gpsdAddr)
+	if s.manual != nil {
+		p.SetString(prefLocationManualLat, strconv.FormatFloat(s.manual.Lat, 'f', 5, 64))
+		p.SetString(prefLocationManualLon, strconv.FormatFloat(s.manual.Lon, 'f', 5, 64))
+	} else {
+		p.RemoveValue(prefLocationManualLat)
+		p.RemoveValue(prefLocationManualLon)
+	}
+}
+
+// locateDevice prova le fonti configurate nell'ordine scelto. È bloccante:
+// va chiamata da una goroutine.
+func locateDevice() (*services.DeviceLocation, error) {
+	s := loadLocationSettings()
+	providers, err := services.LocationProviders(s.order, s.gpsdAddr, s.manual)
+	if err != nil {
+		return nil, err
+	}
+	return services.LocateDevice(providers)
+}
+
+// formatDeviceLocation indica la fonte che ha risposto e la sua precisione.
+func formatDeviceLocation(loc *services.DeviceLocation) string {
+	s := fmt.Sprintf("Posizione da %s (%s): %.4f, %.4f",
+		loc.Provider, services.FormatLocationAccuracy(loc.AccuracyM), loc.Lat, loc.Lon)
+	if !math.IsNaN(loc.ElevationM) {
+		s += fmt.Sprintf(", %.0f m", loc.ElevationM)
+	}
+	return s
+}
+
+// showLocationSettingsDialog permette di scegliere ordine e parametri delle fonti.
+func showLocationSettingsDialog() {
+	wins := fyne.CurrentApp().Driver().AllWindows()
+	if len(wins) == 0 {
+		return
+	}
+	win := wins[0]
+	s := loadLocationSettings()
+
+	order := widget.NewEntry()
+	order.SetText(strings.Join(s.order, ", "))
+	order.SetPlaceHolder(strings.Join(services.DefaultLocationOrder, ", "))
+	gpsd := widget.NewEntry()
+	gpsd.SetText(s.gpsdAddr)
+	gpsd.SetPlaceHolder(services.DefaultGpsdAddr)
+	lat := widget.NewEntry()
+	lon := widget.NewEntry()
+	if s.manual != nil {
+		lat.SetText(fmt.Sprintf("%.4f", s.manual.Lat))
+		lon.SetText(fmt.Sprintf("%.4f", s.manual.Lon))
+	}
+	help := widget.NewLabel(fmt.Sprintf("Fonti disponibili: %s (GPS via gpsd), %s e %s (da IP, precisione %s), %s (coordinate qui sotto). Si provano in ordine fino alla prima che risponde.",
+		services.LocationGpsd, services.LocationIPAPI, services.LocationIPWhois,
+		services.FormatLocationAccuracy(services.IPGeoAccuracyM), services.LocationManual))
+	help.Wrapping = fyne.TextWrapWord
+
+	d := dialog.NewForm(
+		"Fonti della posizione",
+		"Salva",
+		"Annulla",
+		[]*widget.FormItem{
+			widget.NewFormItem("Ordine", order),
+			widget.NewFormItem("gpsd", gpsd),
+			widget.NewFormItem("Lat. manuale", lat),
+			widget.NewFormItem("Lon. manuale", lon),
+			widget.NewFormItem("", help),
+		},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			next, err := readLocationForm(order.Text, gpsd.Text, lat.Text, lon.Text)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			saveLocationSettings(next)
+		},
+		win,
+	)
+	d.Resize(fyne.NewSize(460, 0))
+	d.Show()
+}
+
+// readLocationForm valida i campi del dialogo delle fonti.
+func readLocationForm(order, gpsd, lat, lon string) (locationSettings, error) {
+	var s locationSettings
+	var err error
+	if s.order, err = services.ParseLocationOrder(order); err != nil {
+		return s, err
+	}
+	s.gpsdAddr = strings.TrimSpace(gpsd)
+
+	lat = strings.ReplaceAll(strings.TrimSpace(lat), ",", ".")
+	lon = strings.ReplaceAll(strings.TrimSpace(lon), ",", ".")
+	if lat == "" && lon == "" {
+		return s, nil
+	}
+	la, err1 := strconv.ParseFloat(lat, 64)
+	lo, err2 := strconv.ParseFloat(lon, 64)
+	if err1 != nil || err2 != nil || math.Abs(la) > 90 || math.Abs(lo) > 180 {
+		return s, errors.New("coordinate manuali non valide")
+	}
+	s.manual = &services.DeviceLocation{Lat: la, Lon: lo, ElevationM: math.NaN()}
+	return s, nil
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"image/color"
 	"math"
+	"strings"
 	"time"
 
+	"github.com/cr4sh87/astro-lair-go/models"
 	"github.com/cr4sh87/astro-lair-go/services"
 
 	"fyne.io/fyne/v2"
@@ -47,70 +49,6 @@ func (m *moonSpriteProviderImpl) GetMoonSprite(idx int) fyne.Resource {
 	return services.GetMoonSpriteByIndex(idx)
 }
 
-// =======================
-//  MoonPhaseCalculator
-// =======================
-
-// Durata del mese sinodico in giorni (approssimazione standard)
-const synodicMonth = 29.53058867
-
-// Data di riferimento di una Luna Nuova (2000-01-06 18:14 UTC)
-var refNewMoon = time.Date(2000, 1, 6, 18, 14, 0, 0, time.UTC)
-
-// Restituisce:
-//   - phase: valore tra 0.0 e 1.0 (0 = luna nuova, 0.5 = piena, ecc.)
-//   - ageDays: età della Luna in giorni dall'ultima luna nuova
-func moonPhaseFraction(t time.Time) (phase float64, ageDays float64) {
-	days := t.Sub(refNewMoon).Hours() / 24.0
-
-	age := math.Mod(days, synodicMonth)
-	if age < 0 {
-		age += synodicMonth
-	}
-
-	phase = age / synodicMonth
-	return phase, age
-}
-
-func moonPhaseName(phase float64) string {
-	// normalizziamo nel range [0,1)
-	f := math.Mod(phase, 1.0)
-	if f < 0 {
-		f += 1.0
-	}
-
-	switch {
-	case isNearPhase(f, 0.0):
-		return "Luna Nuova"
-	case isNearPhase(f, 0.25):
-		return "Primo Quarto"
-	case isNearPhase(f, 0.5):
-		return "Luna Piena"
-	case isNearPhase(f, 0.75):
-		return "Ultimo Quarto"
-	case f >= 0.0 && f < 0.25:
-		return "Falce Crescente"
-	case f >= 0.25 && f < 0.5:
-		return "Gibbosa Crescente"
-	case f >= 0.5 && f < 0.75:
-		return "Gibbosa Calante"
-	default:
-		return "Falce Calante"
-	}
-}
-
-// approssimazione dell’illuminazione: 0..1
-func moonIllumination(phase float64) float64 {
-	// formula semplice: (1 - cos(2πphase)) / 2
-	return 0.5 * (1.0 - math.Cos(2.0*math.Pi*phase))
-}
-
-func isNearPhase(value, target float64) bool {
-	const tol = 0.03
-	diff := math.Abs(value - target)
-	return diff <= tol || diff >= 1.0-tol
-}
-
 // =======================
 //  Mapping fase → sprite immagine
 // =======================
@@ -154,65 +92,12 @@ func moonPhaseImageFile(phase float64) string {
 	}
 }
 
-// =======================
-//  Best nights per osservazione
-// =======================
-
-type BestNightEntry struct {
-	Date      time.Time // user locale, ora centrale considerata
-	Illum     float64   // 0..1
-	PhaseName string
-	Quality   string // "Eccellente", "Buona", ...
-}
-
-// qualità molto grezza basata solo su illuminazione
-func qualityFromIllum(illum float64) string {
-	switch {
-	case illum < 0.10:
-		return "Eccellente"
-	case illum < 0.25:
-		return "Buona"
-	case illum < 0.40:
-		return "Discreta"
-	default:
-		return "Scarsa"
-	}
-}
-
-// Calcola le "migliori notti" nei prossimi `days` giorni.
-// Consideriamo la Luna alle 22:00 locali di ogni giorno.
-func computeBestNights(start time.Time, days int) []BestNightEntry {
-	loc := start.Location()
-	var nights []BestNightEntry
-
-	for i := 0; i < days; i++ {
-		d := start.AddDate(0, 0, i)
-		night := time.Date(d.Year(), d.Month(), d.Day(), 22, 0, 0, 0, loc)
-
-		phase, _ := moonPhaseFraction(night.UTC())
-		illum := moonIllumination(phase)
-		name := moonPhaseName(phase)
-
-		// tieni solo notti con poca Luna (≤ 30% circa)
-		if illum <= 0.30 {
-			nights = append(nights, BestNightEntry{
-				Date:      night,
-				Illum:     illum,
-				PhaseName: name,
-				Quality:   qualityFromIllum(illum),
-			})
-		}
-	}
-
-	return nights
-}
-
 // =======================
 //  Moon tab UI
 // =======================
 
 func buildMoonView() fyne.CanvasObject {
-	now := time.Now()
+	now := siteNow()
 	loc := now.Location()
 
 	// stato corrente selezionato (data + ora)
@@ -232,24 +117,29 @@ func buildMoonView() fyne.CanvasObject {
 	illumLabel := widget.NewLabel("")
 	ageLabel := widget.NewLabel("")
 	timeLabel := widget.NewLabel("")
+	posLabel := widget.NewLabel("")
+	sunLabel := widget.NewLabel("")
+	phasesLabel := widget.NewLabel("")
 
-	infoModel := widget.NewLabel("Modello semplice basato su mese sinodico medio.\nVa benissimo per uso visuale / planning; non è un'efemeride ad alta precisione.")
+	infoModel := widget.NewLabel("Efemeride lunare da serie ELP-2000/82 troncata (Meeus, cap. 47): precisione\ndi qualche secondo d'arco sulla posizione e di circa un minuto sugli istanti delle fasi.")
 
 	// -----------------------
 	// Funzione che aggiorna le label + immagine
 	// -----------------------
 
 	updateForSelected := func() {
-		tUTC := selected.In(time.UTC)
-		phase, age := moonPhaseFraction(tUTC)
-		illum := moonIllumination(phase)
-		name := moonPhaseName(phase)
+		lat, lon := observerLocation()
+		mp := services.ComputeMoonPosition(selected, lat, lon)
+		phase, age, illum, name := mp.Phase, mp.AgeDays, mp.Illumination, mp.PhaseName
 
 		nameLabel.SetText(fmt.Sprintf("Fase: %s", name))
 		phaseLabel.SetText(fmt.Sprintf("Frazione lunare: %.3f (0 = nuova, 0.5 = piena)", phase))
 		illumLabel.SetText(fmt.Sprintf("Illuminazione: %.1f%%", illum*100.0))
 		ageLabel.SetText(fmt.Sprintf("Età della Luna: %.1f giorni", age))
 		timeLabel.SetText(fmt.Sprintf("Data/ora selezionata: %s", selected.In(loc).Format("2006-01-02 15:04")))
+		posLabel.SetText(formatMoonPosition(mp))
+		sunLabel.SetText(formatSunEvents(services.ComputeSunEvents(selected, lat, lon), loc))
+		phasesLabel.SetText(formatMoonPhases(services.NextMoonPhases(selected), loc))
 
 		// indice sprite 0..15 in base alla fase 0..1
 		idx := int(phase * 8.0)
@@ -301,7 +191,7 @@ func buildMoonView() fyne.CanvasObject {
 
 	// Pulsante "Ora attuale"
 	useNowBtn := widget.NewButton("Ora attuale", func() {
-		now := time.Now()
+		now := siteNow()
 		dateEntry.SetText(now.Format("2006-01-02"))
 		timeEntry.SetText(now.Format("15:04"))
 		selected = now
@@ -352,9 +242,10 @@ func buildMoonView() fyne.CanvasObject {
 	// Tabella "Prossime notti buone"
 	// -----------------------
 
-	bestNights := computeBestNights(now, 30) // prossimi 30 giorni
+	siteLat, siteLon := observerLocation()
+	bestNights := services.ComputeBestNights(now, 30, siteLat, siteLon) // prossimi 30 giorni
 
-	headers := []string{"Data", "Ora", "Illuminazione", "Qualità"}
+	headers := []string{"Notte", "Inizio", "Fine", "Ore buie", "Illuminazione", "Qualità"}
 
 	table := widget.NewTable(
 		func() (int, int) {
@@ -386,12 +277,16 @@ func buildMoonView() fyne.CanvasObject {
 
 			switch id.Col {
 			case 0:
-				lbl.SetText(e.Date.Format("02/01/2006"))
+				lbl.SetText(e.Date.Format("Mon 02/01"))
 			case 1:
-				lbl.SetText(e.Date.Format("15:04"))
+				lbl.SetText(e.Start.In(loc).Format("15:04"))
 			case 2:
-				lbl.SetText(fmt.Sprintf("%.1f%%", e.Illum*100.0))
+				lbl.SetText(e.End.In(loc).Format("15:04"))
 			case 3:
+				lbl.SetText(formatDarkHours(e))
+			case 4:
+				lbl.SetText(fmt.Sprintf("%.1f%%", e.Illumination*100.0))
+			case 5:
 				lbl.SetText(e.Quality)
 			default:
 				lbl.SetText("")
@@ -401,14 +296,42 @@ func buildMoonView() fyne.CanvasObject {
 
 	table.SetColumnWidth(0, 110)
 	table.SetColumnWidth(1, 70)
-	table.SetColumnWidth(2, 110)
-	table.SetColumnWidth(3, 100)
+	table.SetColumnWidth(2, 70)
+	table.SetColumnWidth(3, 110)
+	table.SetColumnWidth(4, 110)
+	table.SetColumnWidth(5, 100)
 
 	tableScroll := container.NewVScroll(table)
 	tableScroll.SetMinSize(fyne.NewSize(0, 200))
 
+	// cambio di sito: stesso istante nel nuovo fuso, notti ricalcolate in
+	// background (30 giorni di ricerche); vale solo l'ultimo cambio
+	nightsGen := 0
+	onSiteChanged(func() {
+		loc = siteLocation()
+		selected = selected.In(loc)
+		dateEntry.SetText(selected.Format("2006-01-02"))
+		timeEntry.SetText(selected.Format("15:04"))
+		updateForSelected()
+
+		nightsGen++
+		gen := nightsGen
+		lat, lon := observerLocation()
+		now := siteNow()
+		go func() {
+			nights := services.ComputeBestNights(now, 30, lat, lon)
+			fyne.Do(func() {
+				if gen != nightsGen {
+					return
+				}
+				bestNights = nights
+				table.Refresh()
+			})
+		}()
+	})
+
 	tableTitle := widget.NewLabelWithStyle(
-		"Prossime notti favorevoli (22:00 locali, poca Luna)",
+		"Notti migliori dei prossimi 30 giorni (buio astronomico senza Luna, per ore utili)",
 		fyne.TextAlignLeading,
 		fyne.TextStyle{Bold: true},
 	)
@@ -427,6 +350,13 @@ func buildMoonView() fyne.CanvasObject {
 			),
 			container.NewCenter(moonImg),
 		),
+		posLabel,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Prossime fasi", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		phasesLabel,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Sole", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		sunLabel,
 		widget.NewSeparator(),
 		infoModel,
 	)
@@ -448,6 +378,61 @@ func buildMoonView() fyne.CanvasObject {
 	return container.NewVScroll(content)
 }
 
+// formatDarkHours mostra le ore buie senza Luna; con più finestre indica che
+// Inizio/Fine si riferiscono alla più lunga.
+func formatDarkHours(e models.BestNightEntry) string {
+	s := fmt.Sprintf("%.1f h", e.DarkHours)
+	if len(e.Windows) > 1 {
+		s += fmt.Sprintf(" (%d finestre)", len(e.Windows))
+	}
+	return s
+}
+
+// formatMoonPosition riassume coordinate, distanza e lembo illuminato.
+func formatMoonPosition(mp models.MoonPosition) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "RA/Dec (topocentriche): %s  %s\n",
+		services.FormatRAFromDeg(mp.TopoRADeg), services.FormatDecFromDeg(mp.TopoDecDeg))
+	fmt.Fprintf(sb, "Altezza %.1f° • Azimut %.1f°\n", mp.AltDeg, mp.AzDeg)
+	fmt.Fprintf(sb, "Distanza %.0f km • Diametro %.1f′\n", mp.DistanceKm, mp.AngularDiamArcmin)
+	fmt.Fprintf(sb, "Elongazione %.1f° • Lembo illuminato PA %.0f°", mp.ElongationDeg, mp.BrightLimbDeg)
+	return sb.String()
+}
+
+// formatMoonPhases elenca le prossime fasi principali.
+func formatMoonPhases(phases []models.MoonPhaseEvent, loc *time.Location) string {
+	lines := make([]string, 0, len(phases))
+	for _, p := range phases {
+		lines = append(lines, fmt.Sprintf("%-14s %s", p.Name, p.Time.In(loc).Format("Mon 02/01/2006 15:04")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatSunEvents riassume alba, tramonto e crepuscoli del giorno selezionato.
+func formatSunEvents(ev models.SunEvents, loc *time.Location) string {
+	hm := func(t *time.Time) string {
+		if t == nil {
+			return "—"
+		}
+		return t.In(loc).Format("15:04")
+	}
+
+	sb := &strings.Builder{}
+	switch {
+	case ev.PolarDay:
+		fmt.Fprintf(sb, "Giorno polare: il Sole non tramonta.\n")
+	case ev.PolarNight:
+		fmt.Fprintf(sb, "Notte polare: il Sole non sorge.\n")
+	default:
+		fmt.Fprintf(sb, "Alba %s • Tramonto %s\n", hm(ev.Sunrise), hm(ev.Sunset))
+	}
+	fmt.Fprintf(sb, "Transito %s (alt %.1f°)\n", ev.Transit.In(loc).Format("15:04"), ev.TransitAltDeg)
+	fmt.Fprintf(sb, "Crepuscolo civile: %s / %s\n", hm(ev.CivilDawn), hm(ev.CivilDusk))
+	fmt.Fprintf(sb, "Crepuscolo nautico: %s / %s\n", hm(ev.NauticalDawn), hm(ev.NauticalDusk))
+	fmt.Fprintf(sb, "Crepuscolo astronomico: %s / %s", hm(ev.AstronomicalDawn), hm(ev.AstronomicalDusk))
+	return sb.String()
+}
+
 // showCalendarPopup mostra un piccolo calendario del mese corrente
 // e richiama onSelect con la data scelta.
 func showCalendarPopup(anchor fyne.CanvasObject, onSelect func(time.Time)) {
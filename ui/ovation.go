@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// =======================
+//  OVATION al sito
+// =======================
+
+// ovationMapPx è il lato in pixel della mappa OVATION disegnata
+const ovationMapPx = 360
+
+// ovationView raccoglie la mappa disegnata e le etichette da affiancare alle
+// immagini NOAA dei due emisferi.
+type ovationView struct {
+	card        fyne.CanvasObject
+	northLabel  *widget.Label
+	southLabel  *widget.Label
+	mapImg      *canvas.Image
+	detailLabel *widget.Label
+	loading     *widget.ProgressBarInfinite
+	grid        *services.OvationGrid
+}
+
+func newOvationView() *ovationView {
+	v := &ovationView{
+		northLabel:  widget.NewLabel(""),
+		southLabel:  widget.NewLabel(""),
+		mapImg:      canvas.NewImageFromImage(nil),
+		detailLabel: widget.NewLabel("Scarico la griglia OVATION…"),
+		loading:     widget.NewProgressBarInfinite(),
+	}
+	v.northLabel.Wrapping = fyne.TextWrapWord
+	v.southLabel.Wrapping = fyne.TextWrapWord
+	v.detailLabel.Wrapping = fyne.TextWrapWord
+	v.mapImg.FillMode = canvas.ImageFillContain
+	v.mapImg.SetMinSize(fyne.NewSize(260, 260))
+
+	legend := widget.NewLabel("Mappa centrata sul polo dell'emisfero del sito, con il sito in basso (il polo è \"in alto\" guardando dal sito). " +
+		"Verde → rosso: probabilità crescente; cerchi ogni 10° di latitudine, meridiani ogni 30°.")
+	legend.Wrapping = fyne.TextWrapWord
+
+	refreshBtn := widget.NewButton("Aggiorna OVATION", v.refresh)
+	v.card = widget.NewCard("🎯 Probabilità di aurora al sito (OVATION)", "", container.NewAdaptiveGrid(2,
+		v.mapImg,
+		container.NewVBox(v.detailLabel, v.loading, refreshBtn, legend),
+	))
+
+	onSiteChanged(v.show)
+	v.refresh()
+	return v
+}
+
+// refresh scarica la griglia e aggiorna mappa ed etichette.
+func (v *ovationView) refresh() {
+	v.loading.Show()
+	go func() {
+		g, err := services.FetchOvation()
+		fyne.Do(func() {
+			v.loading.Hide()
+			if err != nil {
+				v.detailLabel.SetText("Griglia OVATION non disponibile: " + err.Error())
+				return
+			}
+			v.grid = g
+			v.show()
+		})
+	}()
+}
+
+// show ricalcola la probabilità per il sito selezionato (senza riscaricare).
+func (v *ovationView) show() {
+	if v.grid == nil {
+		return
+	}
+	lat, lon := observerLocation()
+	p := services.OvationAtSite(v.grid, lat, lon)
+
+	v.mapImg.Image = renderOvationMap(v.grid, lat, lon, ovationMapPx)
+	v.mapImg.Refresh()
+	v.detailLabel.SetText(formatOvation(currentSite().Name, p, siteLocation()))
+
+	short := fmt.Sprintf("OVATION al sito: %.0f%% allo zenit, fino a %.0f%% in vista.", p.SiteProb, p.VisibleProb)
+	if lat >= 0 {
+		v.northLabel.SetText(short)
+		v.southLabel.SetText("")
+	} else {
+		v.southLabel.SetText(short)
+		v.northLabel.SetText("")
+	}
+}
+
+// formatOvation descrive probabilità allo zenit e miglior punto visibile.
+func formatOvation(site string, p models.AuroraProbability, loc *time.Location) string {
+	s := fmt.Sprintf("%s — previsione OVATION per le %s (dati delle %s)\n",
+		site, p.ForecastTime.In(loc).Format("15:04 02/01"), p.ObservationTime.In(loc).Format("15:04"))
+	s += fmt.Sprintf("Probabilità di aurora allo zenit: %.0f%%\n", p.SiteProb)
+	if p.VisibleDistKm == 0 {
+		return s + "Nessun punto più favorevole è abbastanza alto sull'orizzonte del sito."
+	}
+	return s + fmt.Sprintf("In vista: %.0f%% (pesata con l'altezza) a %.0f km (lat %.0f°, lon %.0f°), verso %s a %.0f° sull'orizzonte.",
+		p.VisibleProb, p.VisibleDistKm, p.VisibleLat, p.VisibleLon, compassPoint(p.VisibleAzDeg), p.VisibleElevDeg)
+}
+
+// renderOvationMap disegna la griglia in proiezione azimutale equidistante
+// centrata sul polo dell'emisfero del sito, ruotata in modo che il sito sia
+// sotto il polo; il sito è segnato da un anello bianco con centro rosso.
+func renderOvationMap(g *services.OvationGrid, siteLat, siteLon float64, size int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	south := siteLat < 0
+	// colatitudine massima: almeno fino a 40° di latitudine e sempre oltre il sito
+	maxColat := math.Min(90, math.Max(50, 90-math.Abs(siteLat)+10))
+	c := float64(size) / 2
+	radius := c - 2
+	degPerPx := maxColat / radius
+
+	background := color.NRGBA{R: 16, G: 24, B: 32, A: 255}
+	gridLine := color.NRGBA{R: 80, G: 90, B: 100, A: 255}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)+0.5-c, float64(y)+0.5-c
+			rho := math.Hypot(dx, dy)
+			if rho > radius {
+				continue
+			}
+			colat := rho * degPerPx
+			// angolo dal basso verso destra: a nord le longitudini crescono in
+			// senso antiorario visto dall'alto, a sud in senso orario
+			alpha := math.Atan2(dx, dy) * 180 / math.Pi
+			lon := siteLon + alpha
+			lat := 90 - colat
+			if south {
+				lon = siteLon - alpha
+				lat = -lat
+			}
+
+			px := background
+			if p := g.At(lat, lon); p >= 1 {
+				px = blendNRGBA(background, ovationColor(p), math.Min(1, p/10))
+			}
+			// cerchi di latitudine ogni 10° e meridiani ogni 30°
+			if d := math.Abs(colat - 10*math.Round(colat/10)); d < degPerPx*0.6 && colat > 1 {
+				px = blendNRGBA(px, gridLine, 0.7)
+			} else if m := math.Abs(math.Mod(lon+3600, 30)); (math.Min(m, 30-m)*math.Pi/180)*rho < 0.6 && rho > 4 {
+				px = blendNRGBA(px, gridLine, 0.7)
+			}
+			img.SetNRGBA(x, y, px)
+		}
+	}
+
+	// sito: in basso, alla distanza della sua colatitudine
+	sx := c
+	sy := c + (90-math.Abs(siteLat))/degPerPx
+	for y := int(sy) - 7; y <= int(sy)+7; y++ {
+		for x := int(sx) - 7; x <= int(sx)+7; x++ {
+			d := math.Hypot(float64(x)+0.5-sx, float64(y)+0.5-sy)
+			switch {
+			case d <= 3:
+				img.SetNRGBA(x, y, color.NRGBA{R: 230, G: 30, B: 30, A: 255})
+			case d >= 4.5 && d <= 6.5:
+				img.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// ovationColor: verde per le probabilità basse, giallo, rosso oltre il 60%.
+func ovationColor(p float64) color.NRGBA {
+	green := color.NRGBA{R: 40, G: 200, B: 80, A: 255}
+	yellow := color.NRGBA{R: 240, G: 220, B: 30, A: 255}
+	red := color.NRGBA{R: 235, G: 45, B: 35, A: 255}
+	t := math.Min(1, p/60)
+	if t < 0.5 {
+		return blendNRGBA(green, yellow, t*2)
+	}
+	return blendNRGBA(yellow, red, (t-0.5)*2)
+}
+
+// blendNRGBA mescola due colori opachi (t = 0 → a, t = 1 → b).
+func blendNRGBA(a, b color.NRGBA, t float64) color.NRGBA {
+	mix := func(x, y uint8) uint8 { return uint8(float64(x)*(1-t) + float64(y)*t + 0.5) }
+	return color.NRGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// =======================
+//  Ricerca località (offline)
+// =======================
+
+// placeSearchLimit è il numero massimo di risultati mostrati sotto il campo
+const placeSearchLimit = 6
+
+// newPlaceSearch restituisce un campo di ricerca nel gazetteer incorporato con
+// la lista dei risultati; scegliendone uno viene chiamato onPick.
+// Funziona senza rete.
+func newPlaceSearch(onPick func(models.Place)) fyne.CanvasObject {
+	query := widget.NewEntry()
+	query.SetPlaceHolder("Cerca località (es. Nicolosi, Roma, Santiago, CL)")
+	results := container.NewVBox()
+
+	query.OnChanged = func(text string) {
+		results.Objects = nil
+		if strings.TrimSpace(text) != "" {
+			places := services.SearchPlaces(text, placeSearchLimit)
+			if len(places) == 0 {
+				results.Add(widget.NewLabel("Nessuna località trovata."))
+			}
+			for _, p := range places {
+				results.Add(widget.NewButton(formatPlace(p), func() {
+					query.SetText("")
+					onPick(p)
+				}))
+			}
+		}
+		results.Refresh()
+	}
+
+	return container.NewVBox(query, results)
+}
+
+// formatPlace descrive una località per la lista dei risultati.
+func formatPlace(p models.Place) string {
+	s := fmt.Sprintf("%s (%s) — %.4f, %.4f", p.Name, p.Country, p.Lat, p.Lon)
+	if p.ElevationM != 0 {
+		s += fmt.Sprintf(", %.0f m", p.ElevationM)
+	}
+	return s
+}
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// planetMinAltDeg è l'altezza minima per contare le ore utili di un pianeta
+const planetMinAltDeg = 15.0
+
+// =======================
+//  Planets tab UI
+// =======================
+
+// buildPlanetsView costruisce la tab dei pianeti: tabella della notte al sito
+// salvato e dettaglio del pianeta selezionato. Restituisce anche la funzione
+// di refresh da chiamare quando la tab diventa visibile.
+func buildPlanetsView() (fyne.CanvasObject, func()) {
+	var (
+		rows     []models.PlanetTonight
+		selected = -1
+		ref      = siteNow()
+	)
+
+	headerLabel := widget.NewLabel("")
+	headerLabel.Wrapping = fyne.TextWrapWord
+	detailsLabel := widget.NewLabel("Seleziona un pianeta per i dettagli.")
+	detailsLabel.Wrapping = fyne.TextWrapWord
+
+	headers := []string{"Pianeta", "Mag", "Diam.", "Fase", "Elong.", "Sorge", "Transito", "Tramonta", "Ore buio", "Alt. max"}
+
+	table := widget.NewTable(
+		func() (int, int) {
+			return len(rows) + 1, len(headers)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			lbl := obj.(*widget.Label)
+
+			if id.Row == 0 {
+				lbl.TextStyle = fyne.TextStyle{Bold: true}
+				lbl.SetText(headers[id.Col])
+				return
+			}
+
+			lbl.TextStyle = fyne.TextStyle{}
+			if id.Row-1 >= len(rows) {
+				lbl.SetText("")
+				return
+			}
+			p := rows[id.Row-1]
+
+			switch id.Col {
+			case 0:
+				lbl.SetText(p.Name)
+			case 1:
+				lbl.SetText(fmt.Sprintf("%+.1f", p.Magnitude))
+			case 2:
+				lbl.SetText(fmt.Sprintf("%.1f\"", p.DiameterArcsec))
+			case 3:
+				lbl.SetText(fmt.Sprintf("%.0f%%", p.Illumination*100))
+			case 4:
+				lbl.SetText(fmt.Sprintf("%+.0f°", p.ElongationDeg))
+			case 5:
+				lbl.SetText(formatOptionalEvent(p.Rise, ref))
+			case 6:
+				lbl.SetText(formatOptionalEvent(p.Transit, ref))
+			case 7:
+				lbl.SetText(formatOptionalEvent(p.Set, ref))
+			case 8:
+				if p.HoursAbove > 0 {
+					lbl.SetText(fmt.Sprintf("%.1f h", p.HoursAbove))
+				} else {
+					lbl.SetText("—")
+				}
+			case 9:
+				if p.HoursAbove > 0 {
+					lbl.SetText(fmt.Sprintf("%.0f° alle %s", p.MaxAltDeg, p.MaxAltTime.In(ref.Location()).Format("15:04")))
+				} else {
+					lbl.SetText("—")
+				}
+			}
+		},
+	)
+	for col, w := range []float32{90, 60, 60, 55, 65, 105, 105, 105, 75, 110} {
+		table.SetColumnWidth(col, w)
+	}
+
+	showDetails := func() {
+		if selected < 0 || selected >= len(rows) {
+			return
+		}
+		lat, lon := observerLocation()
+		detailsLabel.SetText(formatPlanetDetails(rows[selected].PlanetPosition, lat, lon, time.Now()))
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row == 0 {
+			table.UnselectAll()
+			return
+		}
+		selected = id.Row - 1
+		showDetails()
+	}
+
+	refresh := func() {
+		now := siteNow()
+		ref = now
+		loc := now.Location()
+		lat, lon := observerLocation()
+
+		var window models.NightWindow
+		var dark bool
+		rows, window, dark = services.PlanetsTonight(now, lat, lon, planetMinAltDeg)
+
+		if dark {
+			headerLabel.SetText(fmt.Sprintf("Notte del %s al sito %.3f, %.3f — buio astronomico %s–%s; ore utili sopra %.0f°.",
+				services.NightDay(now).Format("02/01"), lat, lon,
+				window.Start.In(loc).Format("15:04"), window.End.In(loc).Format("15:04"), planetMinAltDeg))
+		} else {
+			headerLabel.SetText(fmt.Sprintf("Al sito %.3f, %.3f stanotte non c'è buio astronomico: sorgere e tramonto riferiti alla mezzanotte.", lat, lon))
+		}
+		table.Refresh()
+		showDetails()
+	}
+
+	refreshBtn := widget.NewButton("Aggiorna", refresh)
+
+	infoLabel := widget.NewLabel("Posizioni da VSOP87 troncata (Meeus, cap. 32-33):\nerrori di qualche secondo d'arco. Elongazione + = sera, − = mattino.")
+	infoLabel.Wrapping = fyne.TextWrapWord
+
+	tableScroll := container.NewScroll(table)
+	tableScroll.SetMinSize(fyne.NewSize(0, 280))
+
+	view := container.NewBorder(
+		container.NewVBox(
+			widget.NewLabelWithStyle("Pianeti stanotte", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			headerLabel,
+			container.NewHBox(refreshBtn),
+			widget.NewSeparator(),
+		),
+		container.NewVBox(
+			widget.NewSeparator(),
+			detailsLabel,
+			widget.NewSeparator(),
+			infoLabel,
+		),
+		nil,
+		nil,
+		tableScroll,
+	)
+
+	refresh()
+	onSiteChanged(refresh)
+	return view, refresh
+}
+
+// formatPlanetDetails descrive la posizione di un pianeta e la sua altezza
+// al sito nell'istante at.
+func formatPlanetDetails(p models.PlanetPosition, lat, lon float64, at time.Time) string {
+	alt, az, _ := services.EquatorialToHorizontal(p.RADeg, p.DecDeg, lat, lon, at)
+
+	side := "sera, a est del Sole"
+	if p.ElongationDeg < 0 {
+		side = "mattino, a ovest del Sole"
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "%s — RA/Dec: %s  %s\n", p.Name,
+		services.FormatRAFromDeg(p.RADeg), services.FormatDecFromDeg(p.DecDeg))
+	fmt.Fprintf(sb, "Al sito: altezza %.1f° • azimut %.1f° (%s)\n", alt, az, compassPoint(az))
+	fmt.Fprintf(sb, "Magnitudine %+.1f • diametro %.1f\" • illuminato %.0f%%\n",
+		p.Magnitude, p.DiameterArcsec, p.Illumination*100)
+	fmt.Fprintf(sb, "Distanza %.3f UA dalla Terra, %.3f UA dal Sole • elongazione %.0f° (%s)",
+		p.DistanceAU, p.SunDistanceAU, math.Abs(p.ElongationDeg), side)
+	return sb.String()
+}
+
+// formatOptionalEvent formatta un evento che può mancare (circumpolare, mai sorto).
+func formatOptionalEvent(t *time.Time, ref time.Time) string {
+	if t == nil {
+		return "—"
+	}
+	return formatEventTime(*t, ref)
+}
@@ -21,6 +21,11 @@ func BuildSatellitesViewPublic() (fyne.CanvasObject, func()) {
 	return buildSatellitesView()
 }
 
+// BuildPlanetsView ritorna la vista dei pianeti e la sua funzione di refresh
+func BuildPlanetsViewPublic() (fyne.CanvasObject, func()) {
+	return buildPlanetsView()
+}
+
 // BuildMoonView ritorna la vista della luna
 func BuildMoonViewPublic() fyne.CanvasObject {
 	return buildMoonView()
@@ -1,10 +1,16 @@
 package ui
 
 import (
+	"fmt"
+	"image"
 	"image/color"
 	"math"
+	"strings"
 	"time"
 
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
@@ -12,109 +18,41 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-// Rappresenta la posizione di un satellite lungo il piano equatoriale visto di taglio.
-// X è in "raggi planetari" (unità fittizie ma proporzionali).
-// Behind = true significa "dietro il pianeta" (semplificazione geometrica).
-type SatellitePos struct {
-	Name   string
-	X      float64
-	Behind bool
-}
-
-// =======================
-//  Modello semplificato – Giove
-// =======================
-
-var jRefEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
-
-// Dati semplificati per i 4 galileiani (periodi e distanze in raggi gioviani circa)
-type jSatParam struct {
-	Name        string
-	PeriodDays  float64 // periodo orbitale
-	RadiusRj    float64 // distanza media dal pianeta (raggi gioviani)
-	PhaseOffset float64 // fase iniziale [0..1), per sfalsare un minimo le posizioni
-}
+// SatellitePos: posizione di un satellite lungo il piano equatoriale visto di taglio.
+type SatellitePos = models.SatellitePos
 
-var jupiterSats = []jSatParam{
-	{"Io", 1.769, 5.9, 0.10},
-	{"Europa", 3.551, 9.4, 0.30},
-	{"Ganimede", 7.155, 15.0, 0.55},
-	{"Callisto", 16.689, 26.3, 0.80},
+// satSnapshot è ciò che serve allo schema per un istante: i satelliti e,
+// per Saturno, l'apertura degli anelli.
+type satSnapshot struct {
+	Sats        []SatellitePos
+	Rings       bool
+	RingTiltDeg float64 // B: + = vediamo la faccia nord
+	PolePADeg   float64 // P: angolo di posizione del polo nord
 }
 
-// Modello super-semplificato: orbite circolari sul piano, moto uniforme.
-// Interpretiamo:
-//
-//	x = r * sin(angle)  (posizione sul piano del cielo, sinistra/destra)
-//	z = r * cos(angle)  (profondità: z<0 = dietro il pianeta)
-func computeJupiterSatellites(t time.Time) []SatellitePos {
-	dtDays := t.Sub(jRefEpoch).Hours() / 24.0
-	var out []SatellitePos
-
-	for _, s := range jupiterSats {
-		angle := 2.0 * math.Pi * (dtDays/s.PeriodDays + s.PhaseOffset)
-		x := s.RadiusRj * math.Sin(angle)
-		z := s.RadiusRj * math.Cos(angle)
-		behind := z < 0 // metà orbita "dietro"
-
-		out = append(out, SatellitePos{
-			Name:   s.Name,
-			X:      x,
-			Behind: behind,
-		})
-	}
-	return out
+func jupiterSnapshot(t time.Time) satSnapshot {
+	return satSnapshot{Sats: services.ComputeJupiterSatellites(t)}
 }
 
-// =======================
-//  Modello semplificato – Saturno
-// =======================
-
-var sRefEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
-
-type sSatParam struct {
-	Name        string
-	PeriodDays  float64
-	RadiusRs    float64 // raggi saturniani
-	PhaseOffset float64
+func saturnSnapshot(t time.Time) satSnapshot {
+	sys := services.ComputeSaturnSystem(t)
+	return satSnapshot{Sats: sys.Moons, Rings: true, RingTiltDeg: sys.RingTiltDeg, PolePADeg: sys.PolePADeg}
 }
 
-var saturnSats = []sSatParam{
-	{"Mimas", 0.942, 3.1, 0.10},
-	{"Encelado", 1.370, 3.9, 0.25},
-	{"Teti", 1.888, 4.9, 0.40},
-	{"Dione", 2.737, 6.3, 0.55},
-	{"Rea", 4.518, 8.8, 0.70},
-	{"Titano", 15.945, 20.3, 0.20},
-	{"Giapeto", 79.321, 59.0, 0.85},
-}
-
-func computeSaturnSatellites(t time.Time) []SatellitePos {
-	dtDays := t.Sub(sRefEpoch).Hours() / 24.0
-	var out []SatellitePos
-
-	for _, s := range saturnSats {
-		angle := 2.0 * math.Pi * (dtDays/s.PeriodDays + s.PhaseOffset)
-		x := s.RadiusRs * math.Sin(angle)
-		z := s.RadiusRs * math.Cos(angle)
-		behind := z < 0
-
-		out = append(out, SatellitePos{
-			Name:   s.Name,
-			X:      x,
-			Behind: behind,
-		})
-	}
-	return out
-}
+// satZoomLevels sono gli ingrandimenti dello schema (i satelliti fuori
+// campo non vengono disegnati).
+var satZoomLevels = []string{"1×", "2×", "4×", "8×", "16×"}
 
 // =======================
 //  Rendering schema tipo TheSkyLive
 // =======================
 
-// Disegna un diagramma orizzontale: pianeta al centro, satelliti a sinistra/destra.
-// widthPx è la larghezza disponibile (prendiamo quella del contenitore).
-func buildSatDiagram(planetName string, sats []SatellitePos, widthPx float32) fyne.CanvasObject {
+// Disegna un diagramma orizzontale: pianeta al centro, satelliti a sinistra/destra
+// (ovest a destra, nord in alto, asse X lungo l'equatore del pianeta).
+// widthPx è la larghezza disponibile (prendiamo quella del contenitore),
+// zoom ingrandisce la scala rispetto a quella che contiene tutti i satelliti.
+func buildSatDiagram(planetName string, snap satSnapshot, widthPx float32, zoom float32) fyne.CanvasObject {
+	sats := snap.Sats
 	if widthPx < 200 {
 		widthPx = 400 // fallback la prima volta, prima che il layout dia una size reale
 	}
@@ -122,11 +60,16 @@ func buildSatDiagram(planetName string, sats []SatellitePos, widthPx float32) fy
 	centerX := widthPx / 2
 	centerY := height / 2
 
-	// Scala: quanti pixel per "raggio planetario"
-	scale := float32(4.0)
+	// Scala: quanti pixel per "raggio planetario", in modo che il satellite
+	// più lontano stia nello schema
+	maxX := 1.0
+	for _, s := range sats {
+		maxX = math.Max(maxX, math.Abs(s.X))
+	}
+	scale := (widthPx/2 - 40) / float32(maxX) * max(zoom, 1)
 
-	// Pianeta
-	planetRadiusPx := float32(22)
+	// Pianeta (in scala, ma mai più piccolo di qualche pixel)
+	planetRadiusPx := max(scale, 4)
 	var planetColor color.NRGBA
 	switch planetName {
 	case "Giove":
@@ -136,9 +79,19 @@ func buildSatDiagram(planetName string, sats []SatellitePos, widthPx float32) fy
 	default:
 		planetColor = color.NRGBA{R: 180, G: 180, B: 180, A: 255}
 	}
-	planet := canvas.NewCircle(planetColor)
-	planet.Resize(fyne.NewSize(planetRadiusPx*2, planetRadiusPx*2))
-	planet.Move(fyne.NewPos(centerX-planetRadiusPx, centerY-planetRadiusPx))
+	var planet fyne.CanvasObject
+	if snap.Rings {
+		// globo e anelli in un'unica immagine, con le occultazioni reciproche
+		half := planetRadiusPx * float32(services.SaturnRingOuter)
+		planet = ringedPlanetRaster(planetColor, snap.RingTiltDeg)
+		planet.Resize(fyne.NewSize(half*2, half*2))
+		planet.Move(fyne.NewPos(centerX-half, centerY-half))
+	} else {
+		disk := canvas.NewCircle(planetColor)
+		disk.Resize(fyne.NewSize(planetRadiusPx*2, planetRadiusPx*2))
+		disk.Move(fyne.NewPos(centerX-planetRadiusPx, centerY-planetRadiusPx))
+		planet = disk
+	}
 
 	// "Linea" del piano equatoriale
 	line := canvas.NewLine(color.NRGBA{R: 120, G: 120, B: 120, A: 255})
@@ -150,8 +103,20 @@ func buildSatDiagram(planetName string, sats []SatellitePos, widthPx float32) fy
 	var behindObjs []fyne.CanvasObject
 	var frontObjs []fyne.CanvasObject
 
+	var shadowObjs []fyne.CanvasObject
 	for i, s := range sats {
 		x := centerX + float32(s.X)*scale
+		y := centerY - float32(s.Y)*scale
+		if x < 5 || x > widthPx-5 || y < 5 || y > height-5 {
+			continue // fuori campo con lo zoom attuale
+		}
+
+		if s.ShadowOnDisk {
+			shadow := canvas.NewCircle(color.NRGBA{A: 255})
+			shadow.Resize(fyne.NewSize(6, 6))
+			shadow.Move(fyne.NewPos(centerX+float32(s.ShadowX)*scale-3, centerY-float32(s.ShadowY)*scale-3))
+			shadowObjs = append(shadowObjs, shadow)
+		}
 
 		// alterniamo leggermente sopra/sotto per non sovrapporre le label
 		offsetY := float32(0)
@@ -162,24 +127,28 @@ func buildSatDiagram(planetName string, sats []SatellitePos, widthPx float32) fy
 		}
 
 		// colore: chiaro davanti, grigio scuro dietro
+		// (eclissato: rosso scuro, perché non visibile pur essendo fuori dal disco)
 		var satColor color.NRGBA
-		if s.Behind {
+		switch {
+		case s.Eclipsed:
+			satColor = color.NRGBA{R: 110, G: 40, B: 40, A: 255}
+		case s.Behind:
 			satColor = color.NRGBA{R: 70, G: 70, B: 70, A: 255}
-		} else {
+		default:
 			satColor = color.NRGBA{R: 180, G: 220, B: 255, A: 255}
 		}
 
 		// pallino satellite
 		satCircle := canvas.NewCircle(satColor)
 		satCircle.Resize(fyne.NewSize(10, 10))
-		satCircle.Move(fyne.NewPos(x-5, centerY-5))
+		satCircle.Move(fyne.NewPos(x-5, y-5))
 
 		// label col nome
 		label := widget.NewLabel(s.Name)
 		label.Alignment = fyne.TextAlignCenter
 		labelContainer := container.NewWithoutLayout(label)
 		label.Resize(fyne.NewSize(70, 16))
-		label.Move(fyne.NewPos(x-35, centerY+offsetY))
+		label.Move(fyne.NewPos(x-35, y+offsetY))
 
 		if s.Behind {
 			behindObjs = append(behindObjs, satCircle, labelContainer)
@@ -190,12 +159,14 @@ func buildSatDiagram(planetName string, sats []SatellitePos, widthPx float32) fy
 
 	// Ordine di disegno:
 	// 1) linea
-	// 2) satelliti dietro
-	// 3) pianeta
-	// 4) satelliti davanti
+	// 2) satelliti dietro (nascosti da globo e anelli)
+	// 3) pianeta (con gli anelli)
+	// 4) ombre sul disco
+	// 5) satelliti davanti
 	objs := []fyne.CanvasObject{line}
 	objs = append(objs, behindObjs...)
 	objs = append(objs, planet)
+	objs = append(objs, shadowObjs...)
 	objs = append(objs, frontObjs...)
 	root := container.NewWithoutLayout(objs...)
 
@@ -203,6 +174,78 @@ func buildSatDiagram(planetName string, sats []SatellitePos, widthPx float32) fy
 	return container.New(layout.NewMaxLayout(), root)
 }
 
+// ringedPlanetRaster disegna globo schiacciato e anelli visti con apertura
+// tiltDeg. La metà degli anelli più vicina alla Terra copre il globo, quella
+// lontana è coperta dal globo. L'immagine è quadrata, di lato pari al
+// diametro esterno dell'anello A.
+func ringedPlanetRaster(globeColor color.NRGBA, tiltDeg float64) *canvas.Raster {
+	sinB := math.Sin(tiltDeg * math.Pi / 180)
+	cosB := math.Cos(tiltDeg * math.Pi / 180)
+	// semiasse polare apparente del globo (in raggi equatoriali)
+	polar := math.Hypot(services.SaturnPolarRatio*cosB, sinB)
+
+	ringColor := color.NRGBA{R: 200, G: 185, B: 140, A: 235}
+	ringFaint := color.NRGBA{R: 150, G: 140, B: 115, A: 150}
+
+	return canvas.NewRaster(func(w, h int) image.Image {
+		img := image.NewNRGBA(image.Rect(0, 0, w, h))
+		pxPerR := float64(w) / 2 / services.SaturnRingOuter
+
+		// con anelli quasi di taglio teniamo almeno un pixel di spessore
+		sb := sinB
+		minSin := 0.6 / (pxPerR * services.SaturnRingOuter)
+		if math.Abs(sb) < minSin {
+			sb = math.Copysign(minSin, sinB)
+		}
+
+		for py := 0; py < h; py++ {
+			Y := (float64(h)/2 - float64(py) - 0.5) / pxPerR
+			for px := 0; px < w; px++ {
+				X := (float64(px) + 0.5 - float64(w)/2) / pxPerR
+
+				inGlobe := X*X+(Y/polar)*(Y/polar) <= 1
+
+				// coordinate nel piano degli anelli: v < 0 = metà vicina
+				v := Y / sb
+				rho := math.Hypot(X, v)
+				inRing := rho >= services.SaturnRingInner && rho <= services.SaturnRingOuter &&
+					(rho < services.SaturnCassiniIn || rho > services.SaturnCassiniOut)
+
+				switch {
+				case inRing && (v < 0 || !inGlobe):
+					if rho < services.SaturnRingBInner {
+						img.SetNRGBA(px, py, ringFaint) // anello C
+					} else {
+						img.SetNRGBA(px, py, ringColor)
+					}
+				case inGlobe:
+					// leggero oscuramento al bordo
+					f := 0.65 + 0.35*math.Sqrt(math.Max(0, 1-X*X-(Y/polar)*(Y/polar)))
+					img.SetNRGBA(px, py, color.NRGBA{
+						R: uint8(float64(globeColor.R) * f),
+						G: uint8(float64(globeColor.G) * f),
+						B: uint8(float64(globeColor.B) * f),
+						A: 255,
+					})
+				}
+			}
+		}
+		return img
+	})
+}
+
+// formatRingInfo descrive l'apertura degli anelli.
+func formatRingInfo(snap satSnapshot) string {
+	face := "faccia nord"
+	if snap.RingTiltDeg < 0 {
+		face = "faccia sud"
+	}
+	if math.Abs(snap.RingTiltDeg) < 1 {
+		face = "quasi di taglio"
+	}
+	return fmt.Sprintf("Anelli: apertura B = %+.2f° (%s) · angolo di posizione del polo P = %.1f°", snap.RingTiltDeg, face, snap.PolePADeg)
+}
+
 // Costruisce la vista per un singolo pianeta, con controlli tempo + schema.
 // -----------------------
 // pagina per singolo pianeta
@@ -213,14 +256,32 @@ type planetSatPage struct {
 	refresh func()
 }
 
-func buildPlanetSatPage(planetName string, compute func(time.Time) []SatellitePos) planetSatPage {
-	current := time.Now()
+// satEventDays è l'orizzonte dell'elenco dei fenomeni sotto lo schema
+const satEventDays = 3
+
+// buildPlanetSatPage costruisce la pagina di un pianeta; info è la nota sul
+// modello usato, events (opzionale) elenca i fenomeni dei satelliti a partire
+// da un istante.
+func buildPlanetSatPage(planetName, info string, compute func(time.Time) satSnapshot, events func(time.Time, int) []models.SatelliteEvent) planetSatPage {
+	current := siteNow()
+	zoom := float32(1)
 
 	timeLabel := widget.NewLabel("")
 	timeLabel.Alignment = fyne.TextAlignCenter
 
 	diagramHolder := container.New(layout.NewMaxLayout())
 
+	eventsLabel := widget.NewLabel("")
+	eventsLabel.Wrapping = fyne.TextWrapWord
+
+	ringLabel := widget.NewLabel("")
+	ringLabel.Alignment = fyne.TextAlignCenter
+	ringLabel.Hide()
+
+	// dove si trova il pianeta in cielo, al sito salvato
+	planetLabel := widget.NewLabel("")
+	planetLabel.Wrapping = fyne.TextWrapWord
+
 	updateUI := func() {
 		timeLabel.SetText(current.Format("2006-01-02 15:04 MST"))
 
@@ -235,13 +296,28 @@ func buildPlanetSatPage(planetName string, compute func(time.Time) []SatellitePo
 			w = 400
 		}
 
-		diagram := buildSatDiagram(planetName, compute(current), w)
+		snap := compute(current)
+		diagram := buildSatDiagram(planetName, snap, w, zoom)
 		diagramHolder.Objects = []fyne.CanvasObject{diagram}
 		diagramHolder.Refresh()
+
+		if snap.Rings {
+			ringLabel.SetText(formatRingInfo(snap))
+			ringLabel.Show()
+		}
+
+		if p, ok := services.ComputePlanetPosition(planetName, current); ok {
+			lat, lon := observerLocation()
+			planetLabel.SetText(formatPlanetDetails(p, lat, lon, current))
+		}
+
+		if events != nil {
+			eventsLabel.SetText(formatSatEvents(events(current, satEventDays), current.Location()))
+		}
 	}
 
 	nowBtn := widget.NewButton("Adesso", func() {
-		current = time.Now()
+		current = siteNow()
 		updateUI()
 	})
 	minus1h := widget.NewButton("-1h", func() {
@@ -261,13 +337,23 @@ func buildPlanetSatPage(planetName string, compute func(time.Time) []SatellitePo
 		updateUI()
 	})
 
+	zoomSelect := widget.NewSelect(satZoomLevels, func(s string) {
+		var z float32
+		if _, err := fmt.Sscanf(s, "%g", &z); err == nil && z > 0 {
+			zoom = z
+			updateUI()
+		}
+	})
+	zoomSelect.Selected = satZoomLevels[0] // senza callback: il primo updateUI arriva da fuori
+
 	buttonRow := container.NewHBox(
 		layout.NewSpacer(),
 		minus1h, minus10m, nowBtn, plus10m, plus1h,
+		widget.NewLabel("Zoom"), zoomSelect,
 		layout.NewSpacer(),
 	)
 
-	infoLabel := widget.NewLabel("Schema semplificato tipo vista equatoriale: non è una efemeride precisa,\nma rende bene la disposizione generale dei satelliti attorno al pianeta.")
+	infoLabel := widget.NewLabel(info)
 	infoLabel.Wrapping = fyne.TextWrapWord
 
 	root := container.NewVBox(
@@ -276,9 +362,16 @@ func buildPlanetSatPage(planetName string, compute func(time.Time) []SatellitePo
 		buttonRow,
 		widget.NewSeparator(),
 		diagramHolder,
+		ringLabel,
+		widget.NewSeparator(),
+		planetLabel,
 		widget.NewSeparator(),
 		infoLabel,
 	)
+	if events != nil {
+		root.Add(widget.NewLabelWithStyle(fmt.Sprintf("Fenomeni dei prossimi %d giorni", satEventDays), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		root.Add(eventsLabel)
+	}
 
 	// NOTA: qui NON chiamiamo updateUI();
 	// verrà chiamato dall'esterno quando la tab è davvero visibile.
@@ -289,13 +382,29 @@ func buildPlanetSatPage(planetName string, compute func(time.Time) []SatellitePo
 	}
 }
 
+// formatSatEvents elenca i fenomeni, uno per riga, in ora locale.
+func formatSatEvents(events []models.SatelliteEvent, loc *time.Location) string {
+	if len(events) == 0 {
+		return "Nessun fenomeno nel periodo."
+	}
+	lines := make([]string, 0, len(events))
+	for _, e := range events {
+		lines = append(lines, e.Time.In(loc).Format("Mon 02/01 15:04")+"  "+services.FormatSatelliteEvent(e))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // -----------------------
 // tab Satellites (Giove + Saturno)
 // -----------------------
 
 func buildSatellitesView() (fyne.CanvasObject, func()) {
-	j := buildPlanetSatPage("Giove", computeJupiterSatellites)
-	s := buildPlanetSatPage("Saturno", computeSaturnSatellites)
+	j := buildPlanetSatPage("Giove",
+		"Posizioni e fenomeni dal metodo di Meeus (cap. 44): errori di pochi minuti sugli istanti.\nOvest a destra, nord in alto; i pallini neri sul disco sono le ombre.",
+		jupiterSnapshot, services.JupiterSatelliteEvents)
+	s := buildPlanetSatPage("Saturno",
+		"Posizioni dal metodo di Meeus (cap. 46).\nOvest a destra, nord in alto, equatore e anelli orizzontali; in grigio i satelliti dietro globo e anelli.",
+		saturnSnapshot, nil)
 
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Giove", j.root),
@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+)
+
+// =======================
+//  Griglia colorata nuvole / seeing / trasparenza
+// =======================
+
+const (
+	gridCellW = float32(42)
+	gridCellH = float32(24)
+	gridLabW  = float32(90)
+)
+
+// Scala di colori dal migliore (0) al peggiore (4), più il grigio per "n/d"
+var gridPalette = []color.NRGBA{
+	{R: 20, G: 70, B: 160, A: 255},
+	{R: 40, G: 140, B: 200, A: 255},
+	{R: 90, G: 170, B: 90, A: 255},
+	{R: 210, G: 170, B: 50, A: 255},
+	{R: 190, G: 70, B: 50, A: 255},
+}
+
+var gridMissing = color.NRGBA{R: 90, G: 90, B: 90, A: 255}
+
+// gridRow è una riga della griglia: come leggere il valore, come scriverlo
+// e in quale classe di colore cade.
+type gridRow struct {
+	name  string
+	value func(models.WeatherHour) float64
+	text  func(float64) string
+	class func(float64) int
+}
+
+var conditionRows = []gridRow{
+	{
+		name:  "Nuvole",
+		value: func(h models.WeatherHour) float64 { return h.CloudCoverPct },
+		text:  func(v float64) string { return fmt.Sprintf("%.0f", v) },
+		class: func(v float64) int { return thresholdClass(v, 10, 25, 50, 75) },
+	},
+	{
+		name:  "Seeing ″",
+		value: func(h models.WeatherHour) float64 { return h.SeeingArcsec },
+		text:  func(v float64) string { return fmt.Sprintf("%.1f", v) },
+		class: func(v float64) int { return thresholdClass(v, 1.5, 2.0, 2.5, 3.5) },
+	},
+	{
+		name:  "Traspar. %",
+		value: func(h models.WeatherHour) float64 { return h.TransparencyPct },
+		text:  func(v float64) string { return fmt.Sprintf("%.0f", v) },
+		class: func(v float64) int { return thresholdClass(-v, -80, -60, -40, -20) },
+	},
+	{
+		name:  "Ottica−rug.",
+		value: func(h models.WeatherHour) float64 { return services.AssessDew(h).MarginC },
+		text:  func(v float64) string { return fmt.Sprintf("%.1f", v) },
+		class: func(v float64) int { return thresholdClass(-v, -5, -3, -1.5, 0) },
+	},
+	{
+		name:  "Getto m/s",
+		value: func(h models.WeatherHour) float64 { return services.JetStreamMS(h) },
+		text:  func(v float64) string { return fmt.Sprintf("%.0f", v) },
+		class: func(v float64) int { return thresholdClass(v, 15, 25, 35, 50) },
+	},
+}
+
+// thresholdClass restituisce la classe 0..4 di v rispetto a soglie crescenti.
+func thresholdClass(v float64, limits ...float64) int {
+	for i, l := range limits {
+		if v < l {
+			return i
+		}
+	}
+	return len(limits)
+}
+
+// buildConditionsGrid disegna una colonna per ora e una riga per grandezza,
+// con celle colorate dal blu (ottimo) al rosso (pessimo).
+func buildConditionsGrid(hours []models.WeatherHour) fyne.CanvasObject {
+	cell := func(text string, bg color.Color, w float32) fyne.CanvasObject {
+		rect := canvas.NewRectangle(bg)
+		rect.SetMinSize(fyne.NewSize(w, gridCellH))
+		rect.StrokeColor = color.NRGBA{A: 255}
+		rect.StrokeWidth = 0.5
+		lbl := canvas.NewText(text, color.White)
+		lbl.TextSize = 11
+		lbl.Alignment = fyne.TextAlignCenter
+		return container.NewStack(rect, container.NewCenter(lbl))
+	}
+
+	cols := []fyne.CanvasObject{}
+
+	// colonna dei nomi
+	names := []fyne.CanvasObject{cell("", color.Transparent, gridLabW)}
+	for _, r := range conditionRows {
+		names = append(names, cell(r.name, color.Transparent, gridLabW))
+	}
+	cols = append(cols, container.NewVBox(names...))
+
+	for _, h := range hours {
+		col := []fyne.CanvasObject{cell(h.Time.Format("15h"), color.Transparent, gridCellW)}
+		if h.Time.Hour() == 0 {
+			col[0] = cell(h.Time.Format("02/01"), color.NRGBA{R: 60, G: 60, B: 60, A: 255}, gridCellW)
+		}
+		for _, r := range conditionRows {
+			v := r.value(h)
+			if math.IsNaN(v) {
+				col = append(col, cell("—", gridMissing, gridCellW))
+				continue
+			}
+			col = append(col, cell(r.text(v), gridPalette[r.class(v)], gridCellW))
+		}
+		cols = append(cols, container.NewVBox(col...))
+	}
+
+	scroll := container.NewHScroll(container.NewHBox(cols...))
+	scroll.SetMinSize(fyne.NewSize(0, gridCellH*float32(len(conditionRows)+1)+12))
+	return scroll
+}
+
+// =======================
+//  Ultimo seeing previsto (per la tab Tools)
+// =======================
+
+var (
+	forecastSeeingMu    sync.Mutex
+	forecastSeeingValue = math.NaN()
+	forecastSeeingNight time.Time
+)
+
+// setForecastSeeing memorizza il seeing medio previsto per la prossima notte.
+func setForecastSeeing(nights []models.NightForecast) {
+	forecastSeeingMu.Lock()
+	defer forecastSeeingMu.Unlock()
+	forecastSeeingValue = math.NaN()
+	for _, n := range nights {
+		if !math.IsNaN(n.AvgSeeingArcsec) {
+			forecastSeeingValue, forecastSeeingNight = n.AvgSeeingArcsec, n.Date
+			return
+		}
+	}
+}
+
+// lastForecastSeeing restituisce il seeing memorizzato (ok = false se la
+// tab Weather non ha ancora scaricato una previsione).
+func lastForecastSeeing() (arcsec float64, night time.Time, ok bool) {
+	forecastSeeingMu.Lock()
+	defer forecastSeeingMu.Unlock()
+	return forecastSeeingValue, forecastSeeingNight, !math.IsNaN(forecastSeeingValue)
+}
+
+// formatSeeing riassume seeing e trasparenza di un'ora.
+func formatSeeing(h models.WeatherHour) string {
+	if math.IsNaN(h.SeeingArcsec) && math.IsNaN(h.TransparencyPct) {
+		return "🔭 Seeing/trasparenza: n/d"
+	}
+	return fmt.Sprintf("🔭 Seeing stimato %.1f″ (%s) • trasparenza %.0f%% (%s)",
+		h.SeeingArcsec, services.SeeingLabel(h.SeeingArcsec),
+		h.TransparencyPct, services.TransparencyLabel(h.TransparencyPct))
+}
@@ -0,0 +1,505 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// =============================================================
+//  SITI DI OSSERVAZIONE (Preferences)
+// =============================================================
+
+const (
+	prefSites       = "sites.list"    // JSON con l'elenco dei siti
+	prefCurrentSite = "sites.current" // nome del sito selezionato
+
+	// chiavi della vecchia posizione unica della tab Weather (migrate in un sito)
+	prefWeatherLat = "weather.lat"
+	prefWeatherLon = "weather.lon"
+)
+
+// defaultSite è il sito proposto se l'utente non ne ha ancora salvato uno
+var defaultSite = models.Site{
+	Name:     "Sito predefinito",
+	Lat:      37.65,
+	Lon:      15.17,
+	Timezone: "Europe/Rome",
+}
+
+// siteStore tiene in memoria i siti salvati; si usa solo dal goroutine UI.
+var siteStore struct {
+	loaded    bool
+	sites     []models.Site
+	current   string
+	listeners []func()
+}
+
+// loadSites legge i siti dalle Preferences (una volta sola). Se non ce ne
+// sono, converte la vecchia posizione weather.lat/lon in un sito.
+func loadSites() {
+	if siteStore.loaded {
+		return
+	}
+	siteStore.loaded = true
+
+	app := fyne.CurrentApp()
+	if app == nil {
+		siteStore.sites = []models.Site{defaultSite}
+		siteStore.current = defaultSite.Name
+		return
+	}
+	p := app.Preferences()
+
+	if raw := p.String(prefSites); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &siteStore.sites); err != nil {
+			log.Printf("[Sites] elenco siti non valido, riparto dal default: %v", err)
+			siteStore.sites = nil
+		}
+	}
+	if len(siteStore.sites) == 0 {
+		site := defaultSite
+		if lat, lon, ok := loadLegacyCoords(p); ok {
+			site = models.Site{Name: "Sito salvato", Lat: lat, Lon: lon}
+		}
+		siteStore.sites = []models.Site{site}
+		siteStore.current = site.Name
+		saveSites()
+		p.RemoveValue(prefWeatherLat)
+		p.RemoveValue(prefWeatherLon)
+		return
+	}
+
+	siteStore.current = p.String(prefCurrentSite)
+	if siteIndex(siteStore.current) < 0 {
+		siteStore.current = siteStore.sites[0].Name
+	}
+}
+
+// loadLegacyCoords legge la posizione salvata dalle versioni precedenti.
+func loadLegacyCoords(p fyne.Preferences) (float64, float64, bool) {
+	latStr := p.String(prefWeatherLat)
+	lonStr := p.String(prefWeatherLon)
+	if latStr == "" || lonStr == "" {
+		return 0, 0, false
+	}
+	lat, err1 := strconv.ParseFloat(latStr, 64)
+	lon, err2 := strconv.ParseFloat(lonStr, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+// saveSites scrive elenco e sito corrente nelle Preferences.
+func saveSites() {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return
+	}
+	data, err := json.Marshal(siteStore.sites)
+	if err != nil {
+		log.Printf("[Sites] impossibile salvare i siti: %v", err)
+		return
+	}
+	p := app.Preferences()
+	p.SetString(prefSites, string(data))
+	p.SetString(prefCurrentSite, siteStore.current)
+}
+
+func siteIndex(name string) int {
+	for i, s := range siteStore.sites {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// currentSite restituisce il sito selezionato nella barra in alto.
+func currentSite() models.Site {
+	loadSites()
+	if i := siteIndex(siteStore.current); i >= 0 {
+		return siteStore.sites[i]
+	}
+	return defaultSite
+}
+
+// siteNames restituisce i nomi dei siti nell'ordine di inserimento.
+func siteNames() []string {
+	loadSites()
+	names := make([]string, len(siteStore.sites))
+	for i, s := range siteStore.sites {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// observerLocation restituisce le coordinate del sito selezionato.
+func observerLocation() (lat, lon float64) {
+	s := currentSite()
+	return s.Lat, s.Lon
+}
+
+// siteLocation è il fuso orario del sito selezionato.
+func siteLocation() *time.Location {
+	return services.SiteLocation(currentSite())
+}
+
+// siteNow è l'ora attuale nel fuso del sito selezionato.
+func siteNow() time.Time {
+	return time.Now().In(siteLocation())
+}
+
+// onSiteChanged registra una funzione chiamata (nel goroutine UI) quando
+// cambia il sito selezionato o vengono modificati i suoi dati.
+func onSiteChanged(fn func()) {
+	siteStore.listeners = append(siteStore.listeners, fn)
+}
+
+func notifySiteChanged() {
+	for _, fn := range siteStore.listeners {
+		fn()
+	}
+}
+
+// selectSite rende corrente il sito indicato.
+func selectSite(name string) {
+	loadSites()
+	if name == siteStore.current || siteIndex(name) < 0 {
+		return
+	}
+	siteStore.current = name
+	saveSites()
+	notifySiteChanged()
+}
+
+// putSite aggiunge o sostituisce (per nome originale oldName) un sito.
+func putSite(oldName string, s models.Site) error {
+	loadSites()
+	if err := services.ValidateSite(s); err != nil {
+		return err
+	}
+	if i := siteIndex(s.Name); i >= 0 && s.Name != oldName {
+		return fmt.Errorf("esiste già un sito chiamato %q", s.Name)
+	}
+
+	if i := siteIndex(oldName); oldName != "" && i >= 0 {
+		siteStore.sites[i] = s
+	} else {
+		siteStore.sites = append(siteStore.sites, s)
+	}
+	wasCurrent := oldName != "" && oldName == siteStore.current
+	if wasCurrent {
+		siteStore.current = s.Name
+	}
+	saveSites()
+	if wasCurrent {
+		notifySiteChanged()
+	}
+	return nil
+}
+
+// deleteSite elimina un sito; l'ultimo sito rimasto non si può eliminare.
+func deleteSite(name string) error {
+	loadSites()
+	i := siteIndex(name)
+	if i < 0 {
+		return nil
+	}
+	if len(siteStore.sites) == 1 {
+		return fmt.Errorf("serve almeno un sito")
+	}
+	siteStore.sites = append(siteStore.sites[:i], siteStore.sites[i+1:]...)
+	if name == siteStore.current {
+		siteStore.current = siteStore.sites[0].Name
+		saveSites()
+		notifySiteChanged()
+		return nil
+	}
+	saveSites()
+	return nil
+}
+
+// formatSiteSummary descrive il sito in una riga.
+func formatSiteSummary(s models.Site) string {
+	parts := []string{fmt.Sprintf("%.4f, %.4f", s.Lat, s.Lon)}
+	if s.ElevationM != 0 {
+		parts = append(parts, fmt.Sprintf("%.0f m", s.ElevationM))
+	}
+	if s.Timezone != "" {
+		parts = append(parts, s.Timezone)
+	}
+	switch {
+	case s.Bortle > 0 && s.SQM > 0:
+		parts = append(parts, fmt.Sprintf("Bortle %d, SQM %.2f", s.Bortle, s.SQM))
+	case s.Bortle > 0:
+		parts = append(parts, fmt.Sprintf("Bortle %d", s.Bortle))
+	case s.SQM > 0:
+		parts = append(parts, fmt.Sprintf("SQM %.2f (≈ Bortle %d)", s.SQM, services.BortleFromSQM(s.SQM)))
+	}
+	if len(s.Horizon) > 0 {
+		parts = append(parts, fmt.Sprintf("orizzonte %d punti", len(s.Horizon)))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// =============================================================
+//  SELETTORE E GESTIONE SITI
+// =============================================================
+
+// BuildSiteSelector restituisce il selettore del sito per la barra in alto,
+// con il pulsante che apre la gestione dei siti.
+func BuildSiteSelector(win fyne.Window) fyne.CanvasObject {
+	sel := widget.NewSelect(siteNames(), nil)
+	sel.Selected = currentSite().Name
+	sel.OnChanged = selectSite
+
+	// l'elenco può cambiare dalla gestione siti
+	syncOptions := func() {
+		sel.SetOptions(siteNames())
+		sel.Selected = currentSite().Name
+		sel.Refresh()
+	}
+	onSiteChanged(syncOptions)
+
+	manageBtn := widget.NewButtonWithIcon("", theme.ListIcon(), func() {
+		showSitesDialog(win, syncOptions)
+	})
+	return container.NewBorder(nil, nil, nil, manageBtn, sel)
+}
+
+// showSitesDialog elenca i siti salvati con aggiunta, modifica ed eliminazione.
+func showSitesDialog(win fyne.Window, changed func()) {
+	loadSites()
+
+	var selected = -1
+	details := widget.NewLabel("Seleziona un sito.")
+	details.Wrapping = fyne.TextWrapWord
+
+	list := widget.NewList(
+		func() int { return len(siteStore.sites) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			s := siteStore.sites[id]
+			text := s.Name
+			if s.Name == siteStore.current {
+				text += "  ✓"
+			}
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		details.SetText(formatSiteSummary(siteStore.sites[id]))
+	}
+
+	reload := func() {
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+		details.SetText("Seleziona un sito.")
+		changed()
+	}
+
+	addBtn := widget.NewButtonWithIcon("Nuovo", theme.ContentAddIcon(), func() {
+		showSiteForm(win, "", models.Site{}, reload)
+	})
+	editBtn := widget.NewButtonWithIcon("Modifica", theme.DocumentCreateIcon(), func() {
+		if selected < 0 {
+			return
+		}
+		s := siteStore.sites[selected]
+		showSiteForm(win, s.Name, s, reload)
+	})
+	useBtn := widget.NewButtonWithIcon("Usa", theme.ConfirmIcon(), func() {
+		if selected < 0 {
+			return
+		}
+		selectSite(siteStore.sites[selected].Name)
+		reload()
+	})
+	delBtn := widget.NewButtonWithIcon("Elimina", theme.DeleteIcon(), func() {
+		if selected < 0 {
+			return
+		}
+		name := siteStore.sites[selected].Name
+		dialog.ShowConfirm("Elimina sito", fmt.Sprintf("Eliminare il sito %q?", name), func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := deleteSite(name); err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			reload()
+		}, win)
+	})
+
+	listScroll := container.NewVScroll(list)
+	listScroll.SetMinSize(fyne.NewSize(320, 200))
+
+	content := container.NewBorder(
+		nil,
+		container.NewVBox(details, container.NewHBox(addBtn, editBtn, useBtn, delBtn)),
+		nil, nil,
+		listScroll,
+	)
+	dialog.NewCustom("Siti di osservazione", "Chiudi", content, win).Show()
+}
+
+// showSiteForm modifica un sito (oldName "" = nuovo sito).
+func showSiteForm(win fyne.Window, oldName string, s models.Site, done func()) {
+	num := func(v float64, format string) string {
+		if v == 0 {
+			return ""
+		}
+		return fmt.Sprintf(format, v)
+	}
+
+	name := widget.NewEntry()
+	name.SetText(s.Name)
+	lat := widget.NewEntry()
+	lat.SetText(fmt.Sprintf("%.4f", s.Lat))
+	lon := widget.NewEntry()
+	lon.SetText(fmt.Sprintf("%.4f", s.Lon))
+	elev := widget.NewEntry()
+	elev.SetText(num(s.ElevationM, "%.0f"))
+	tz := widget.NewEntry()
+	tz.SetText(s.Timezone)
+	tz.SetPlaceHolder("es. Europe/Rome (vuoto = fuso del dispositivo)")
+	bortle := widget.NewEntry()
+	if s.Bortle > 0 {
+		bortle.SetText(strconv.Itoa(s.Bortle))
+	}
+	sqm := widget.NewEntry()
+	sqm.SetText(num(s.SQM, "%.2f"))
+	horizon := widget.NewMultiLineEntry()
+	horizon.SetText(services.FormatHorizon(s.Horizon))
+	horizon.SetPlaceHolder("az:alt, es. 0:10, 90:25, 180:5, 270:15")
+	horizon.SetMinRowsVisible(2)
+
+	status := widget.NewLabel("")
+	locateBtn := widget.NewButton("Usa posizione dispositivo", func() {
+		status.SetText("Rilevo la posizione…")
+		go func() {
+			loc, err := locateDevice()
+			fyne.Do(func() {
+				if err != nil {
+					status.SetText("Impossibile rilevare la posizione: " + err.Error())
+					return
+				}
+				lat.SetText(fmt.Sprintf("%.4f", loc.Lat))
+				lon.SetText(fmt.Sprintf("%.4f", loc.Lon))
+				if !math.IsNaN(loc.ElevationM) {
+					elev.SetText(fmt.Sprintf("%.0f", loc.ElevationM))
+				}
+				status.SetText(formatDeviceLocation(loc))
+			})
+		}()
+	})
+
+	// la località scelta compila coordinate, quota e fuso (e il nome se vuoto)
+	search := newPlaceSearch(func(p models.Place) {
+		if strings.TrimSpace(name.Text) == "" {
+			name.SetText(p.Name)
+		}
+		lat.SetText(fmt.Sprintf("%.4f", p.Lat))
+		lon.SetText(fmt.Sprintf("%.4f", p.Lon))
+		elev.SetText(num(p.ElevationM, "%.0f"))
+		tz.SetText(p.Timezone)
+	})
+
+	form := container.NewVBox(
+		search,
+		widget.NewForm(
+			widget.NewFormItem("Nome", name),
+			widget.NewFormItem("Latitudine", lat),
+			widget.NewFormItem("Longitudine", lon),
+			widget.NewFormItem("Quota (m)", elev),
+			widget.NewFormItem("Fuso orario", tz),
+			widget.NewFormItem("Bortle (1-9)", bortle),
+			widget.NewFormItem("SQM (mag/″²)", sqm),
+			widget.NewFormItem("Orizzonte", horizon),
+		),
+		locateBtn,
+		status,
+	)
+
+	title := "Nuovo sito"
+	if oldName != "" {
+		title = "Modifica sito"
+	}
+	d := dialog.NewCustomConfirm(title, "Salva", "Annulla", form, func(ok bool) {
+		if !ok {
+			return
+		}
+		site, err := readSiteForm(name.Text, lat.Text, lon.Text, elev.Text, tz.Text, bortle.Text, sqm.Text, horizon.Text)
+		if err == nil {
+			err = putSite(oldName, site)
+		}
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		done()
+	}, win)
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
+}
+
+// readSiteForm converte i campi del modulo in un sito: latitudine e
+// longitudine sono obbligatorie, i campi facoltativi vuoti restano a zero.
+func readSiteForm(name, lat, lon, elev, tz, bortle, sqm, horizon string) (models.Site, error) {
+	parse := func(label, text string) (float64, error) {
+		text = strings.ReplaceAll(strings.TrimSpace(text), ",", ".")
+		if text == "" {
+			return 0, nil
+		}
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s non valida: %q", label, text)
+		}
+		return v, nil
+	}
+
+	s := models.Site{Name: strings.TrimSpace(name), Timezone: strings.TrimSpace(tz)}
+	if strings.TrimSpace(lat) == "" || strings.TrimSpace(lon) == "" {
+		return s, fmt.Errorf("latitudine e longitudine sono obbligatorie")
+	}
+	var err error
+	if s.Lat, err = parse("latitudine", lat); err != nil {
+		return s, err
+	}
+	if s.Lon, err = parse("longitudine", lon); err != nil {
+		return s, err
+	}
+	if s.ElevationM, err = parse("quota", elev); err != nil {
+		return s, err
+	}
+	if s.SQM, err = parse("lettura SQM", sqm); err != nil {
+		return s, err
+	}
+	if b := strings.TrimSpace(bortle); b != "" {
+		if s.Bortle, err = strconv.Atoi(b); err != nil {
+			return s, fmt.Errorf("classe Bortle non valida: %q", b)
+		}
+	}
+	if s.Horizon, err = services.ParseHorizon(horizon); err != nil {
+		return s, err
+	}
+	return s, nil
+}
@@ -37,11 +37,40 @@ const (
 // - single resource: if GIF/AVI, play animation; else show static image.
 // - multiple resources: cycle through frames as animation.
 func showResourcesDialog(parent fyne.CanvasObject, resources []fyne.Resource, title string) {
-	if len(resources) == 0 {
+	showFramesDialog(func() []fyne.Resource { return resources }, title, nil)
+}
+
+// frameViewer mostra un'animazione in un dialog. Se arrivano frame aggiornati
+// mentre il dialog è ancora aperto (LoadRemoteAnimation richiama la callback
+// dopo aver rinnovato una copia scaduta) li sostituisce invece di aprirne un
+// altro. Va usato solo dal goroutine UI.
+type frameViewer struct {
+	title  string
+	frames []fyne.Resource
+	open   bool
+}
+
+func (v *frameViewer) show(frames []fyne.Resource) {
+	if v.open && len(v.frames) > 1 && len(frames) > 1 {
+		v.frames = frames
 		return
 	}
+	v.frames = frames
+	v.open = true
+	showFramesDialog(func() []fyne.Resource { return v.frames }, v.title, func() { v.open = false })
+}
 
+// showFramesDialog è il visualizzatore comune: frames viene letta sul
+// goroutine UI a ogni fotogramma, così l'animazione segue i frame aggiornati.
+func showFramesDialog(frames func() []fyne.Resource, title string, onClosed func()) {
 	fyne.Do(func() {
+		resources := frames()
+		if len(resources) == 0 {
+			if onClosed != nil {
+				onClosed()
+			}
+			return
+		}
 		app := fyne.CurrentApp()
 		if app == nil {
 			return
@@ -52,6 +81,15 @@ func showResourcesDialog(parent fyne.CanvasObject, resources []fyne.Resource, ti
 		}
 		win := wins[0]
 
+		var popup *dialog.CustomDialog
+		stop := make(chan struct{})
+		closed := func() {
+			close(stop)
+			if onClosed != nil {
+				onClosed()
+			}
+		}
+
 		// Single resource
 		if len(resources) == 1 {
 			res := resources[0]
@@ -63,17 +101,17 @@ func showResourcesDialog(parent fyne.CanvasObject, resources []fyne.Resource, ti
 					img := canvas.NewImageFromResource(res)
 					img.FillMode = canvas.ImageFillContain
 					img.SetMinSize(fyne.NewSize(400, 400))
-					content := container.NewVBox(img)
-					popup := dialog.NewCustom(title, "Chiudi", content, win)
+					popup = dialog.NewCustom(title, "Chiudi", container.NewVBox(img), win)
+					popup.SetOnClosed(closed)
 					popup.Show()
 					return
 				}
 				gifWidget.Start()
 
-				content := container.NewVBox(gifWidget)
-				popup := dialog.NewCustom(title, "Chiudi", content, win)
+				popup = dialog.NewCustom(title, "Chiudi", container.NewVBox(gifWidget), win)
 				popup.SetOnClosed(func() {
 					gifWidget.Stop()
+					closed()
 				})
 				popup.Show()
 				return
@@ -84,8 +122,8 @@ func showResourcesDialog(parent fyne.CanvasObject, resources []fyne.Resource, ti
 			img.FillMode = canvas.ImageFillContain
 			img.SetMinSize(fyne.NewSize(400, 400))
 
-			content := container.NewVBox(img)
-			popup := dialog.NewCustom(title, "Chiudi", content, win)
+			popup = dialog.NewCustom(title, "Chiudi", container.NewVBox(img), win)
+			popup.SetOnClosed(closed)
 			popup.Show()
 			return
 		}
@@ -95,29 +133,29 @@ func showResourcesDialog(parent fyne.CanvasObject, resources []fyne.Resource, ti
 		animImg.FillMode = canvas.ImageFillContain
 		animImg.SetMinSize(fyne.NewSize(400, 400))
 
-		content := container.NewVBox(animImg)
-		popup := dialog.NewCustom(title, "Chiudi", content, win)
-
-		running := true
-		popup.SetOnClosed(func() {
-			running = false
-		})
-
+		popup = dialog.NewCustom(title, "Chiudi", container.NewVBox(animImg), win)
+		popup.SetOnClosed(closed)
 		popup.Show()
 
 		go func() {
-			for running {
-				for _, res := range resources {
-					if !running {
-						break
-					}
-					r := res
-					fyne.Do(func() {
-						animImg.Resource = r
-						animImg.Refresh()
-					})
-					time.Sleep(100 * time.Millisecond)
+			ticker := time.NewTicker(100 * time.Millisecond)
+			defer ticker.Stop()
+			i := 0
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
 				}
+				fyne.Do(func() {
+					current := frames()
+					if len(current) == 0 {
+						return
+					}
+					animImg.Resource = current[i%len(current)]
+					animImg.Refresh()
+					i++
+				})
 			}
 		}()
 	})
@@ -133,6 +171,14 @@ func BuildSohoView() fyne.CanvasObject {
 func buildSohoView() fyne.CanvasObject {
 	status := widget.NewLabel("")
 
+	// Statistiche delle cache in memoria, aggiornate al termine di ogni caricamento
+	cacheLabel := widget.NewLabel("")
+	cacheLabel.TextStyle = fyne.TextStyle{Italic: true}
+	updateCacheLabel := func() {
+		imgStats, animStats := services.MemoryCacheStats()
+		cacheLabel.SetText("Cache immagini: " + imgStats.String() + "\nCache animazioni: " + animStats.String())
+	}
+
 	// Helper riutilizzabile per colonne immagine + animazione
 	buildRemoteImageColumn := func(title, imageURL, animSource, imageName, animName string) (fyne.CanvasObject, *canvas.Image) {
 		img := canvas.NewImageFromResource(nil)
@@ -149,6 +195,7 @@ func buildSohoView() fyne.CanvasObject {
 
 		var animBtn fyne.CanvasObject
 		if animSource != "" {
+			viewer := &frameViewer{title: animName}
 			animBtnWidget := widget.NewButton("GIF/Anim", func() {
 				services.LoadRemoteAnimation(animSource, animName, status, func(frames []fyne.Resource) {
 					if len(frames) == 0 {
@@ -157,7 +204,8 @@ func buildSohoView() fyne.CanvasObject {
 					}
 
 					// Show frames (single GIF or multiple frames)
-					showResourcesDialog(img, frames, animName)
+					viewer.show(frames)
+					updateCacheLabel()
 				})
 			})
 			animBtn = animBtnWidget
@@ -166,7 +214,7 @@ func buildSohoView() fyne.CanvasObject {
 		}
 
 		// Carica immagine iniziale
-		services.LoadRemoteImage(imageURL, imageName, img, status)
+		services.LoadRemoteImage(imageURL, imageName, img, status, updateCacheLabel)
 
 		buttons := container.NewHBox(jpgBtn, animBtn)
 
@@ -191,8 +239,8 @@ func buildSohoView() fyne.CanvasObject {
 
 	// Pulsanti globali
 	refreshBtn := widget.NewButton("🔄 Aggiorna immagini LASCO", func() {
-		services.LoadRemoteImage(sohoC2JPEG, "LASCO C2 (JPG)", c2Img, status)
-		services.LoadRemoteImage(sohoC3JPEG, "LASCO C3 (JPG)", c3Img, status)
+		services.LoadRemoteImage(sohoC2JPEG, "LASCO C2 (JPG)", c2Img, status, updateCacheLabel)
+		services.LoadRemoteImage(sohoC3JPEG, "LASCO C3 (JPG)", c3Img, status, updateCacheLabel)
 	})
 	downloadBtn := widget.NewButton("📥 Scarica immagini", func() {
 		services.DownloadSOHOImages(status)
@@ -207,7 +255,12 @@ func buildSohoView() fyne.CanvasObject {
 		imageRow,
 		widget.NewSeparator(),
 		suviColumn,
+		widget.NewSeparator(),
+		buildXRayCard(),
+		widget.NewSeparator(),
+		cacheLabel,
 	)
+	updateCacheLabel()
 
 	scroll := container.NewVScroll(page)
 	scroll.SetMinSize(fyne.NewSize(0, 0))
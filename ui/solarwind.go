@@ -0,0 +1,397 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// =======================
+//  Vento solare: Bt/Bz, velocità, densità
+// =======================
+
+// Dimensioni del grafico (pixel): tre pannelli sovrapposti con l'asse dei tempi in comune
+const (
+	swChartW    = 720
+	swPanelH    = 100
+	swTitleH    = 18 // spazio sopra ogni pannello per il titolo
+	swAxisH     = 16
+	swL1Km      = 1.5e6 // distanza di L1 dalla Terra
+	swMaxPeriod = 5     // intervalli di Bz sud elencati al massimo
+)
+
+var (
+	swPanelBg    = color.NRGBA{R: 22, G: 26, B: 34, A: 255}
+	swGridColor  = color.NRGBA{R: 70, G: 75, B: 85, A: 255}
+	swSouthShade = color.NRGBA{R: 200, G: 40, B: 40, A: 255}
+	swBtColor    = color.NRGBA{R: 200, G: 200, B: 200, A: 255}
+	swBzNorth    = color.NRGBA{R: 80, G: 150, B: 240, A: 255}
+	swBzSouth    = color.NRGBA{R: 240, G: 60, B: 50, A: 255}
+	swSpeedColor = color.NRGBA{R: 240, G: 200, B: 60, A: 255}
+	swDensColor  = color.NRGBA{R: 90, G: 200, B: 120, A: 255}
+)
+
+// solarWindRanges sono le finestre selezionabili (etichetta → ore)
+var solarWindRanges = []struct {
+	label string
+	hours int
+}{
+	{"2 ore", 2},
+	{"24 ore", 24},
+	{"7 giorni", 168},
+}
+
+// buildSolarWindCard restituisce il riquadro col vento solare misurato a L1:
+// valori attuali, grafici di Bt/Bz, velocità e densità e intervalli con Bz
+// stabilmente verso sud.
+func buildSolarWindCard() fyne.CanvasObject {
+	nowLabel := widget.NewLabel("Scarico i dati del vento solare…")
+	nowLabel.Wrapping = fyne.TextWrapWord
+	periodsLabel := widget.NewLabel("")
+	periodsLabel.Wrapping = fyne.TextWrapWord
+	chart := container.NewStack()
+	loading := widget.NewProgressBarInfinite()
+
+	legend := widget.NewLabel(fmt.Sprintf("Bz blu verso nord, rosso verso sud; Bt in grigio. Fasce rosse: Bz sotto %.0f nT per almeno %d minuti. Orari del sito.",
+		services.BzSouthThreshold, services.BzSouthMinutes))
+	legend.Wrapping = fyne.TextWrapWord
+
+	hours := solarWindRanges[0].hours
+	var samples []models.SolarWindSample
+
+	show := func() {
+		if samples == nil {
+			return
+		}
+		loc := siteLocation()
+		periods := services.SouthwardBz(samples)
+		nowLabel.SetText(formatSolarWindNow(samples, periods, loc))
+		periodsLabel.SetText(formatBzPeriods(periods, loc))
+		chart.Objects = []fyne.CanvasObject{container.NewHScroll(buildSolarWindChart(samples, periods, loc))}
+		chart.Refresh()
+	}
+
+	// cambiando intervallo mentre un download è in corso vale solo l'ultima richiesta
+	fetchGen := 0
+	refresh := func() {
+		fetchGen++
+		gen, h := fetchGen, hours
+		loading.Show()
+		go func() {
+			s, err := services.FetchSolarWind(h)
+			fyne.Do(func() {
+				if gen != fetchGen {
+					return
+				}
+				loading.Hide()
+				if err != nil {
+					nowLabel.SetText("Vento solare non disponibile: " + err.Error())
+					return
+				}
+				samples = s
+				show()
+			})
+		}()
+	}
+
+	labels := make([]string, len(solarWindRanges))
+	for i, r := range solarWindRanges {
+		labels[i] = r.label
+	}
+	rangeSelect := widget.NewSelect(labels, func(s string) {
+		for _, r := range solarWindRanges {
+			if r.label == s {
+				hours = r.hours
+			}
+		}
+		refresh()
+	})
+
+	onSiteChanged(show)
+	rangeSelect.SetSelected(solarWindRanges[0].label) // avvia il primo download
+
+	return widget.NewCard("🌬️ Vento solare in tempo reale (L1)", "", container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(rangeSelect, widget.NewButton("Aggiorna", refresh)), nowLabel),
+		loading,
+		chart,
+		legend,
+		widget.NewSeparator(),
+		periodsLabel,
+	))
+}
+
+// formatSolarWindNow riassume gli ultimi valori validi e da quanto il Bz è
+// verso sud; stima anche quando il vento misurato a L1 arriverà alla Terra.
+func formatSolarWindNow(samples []models.SolarWindSample, periods []models.BzSouthPeriod, loc *time.Location) string {
+	last, ok := services.LatestSolarWind(samples)
+	if !ok {
+		return "Nessuna misura recente del vento solare."
+	}
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "Ultimo dato delle %s:", last.Time.In(loc).Format("15:04"))
+	if !math.IsNaN(last.Bz) {
+		dir := "verso nord"
+		if last.Bz < 0 {
+			dir = "verso sud"
+		}
+		// intervallo sostenuto ancora in corso?
+		if n := len(periods); n > 0 && last.Time.Sub(periods[n-1].End) <= 5*time.Minute {
+			dir = fmt.Sprintf("verso sud da %s", formatMinutes(last.Time.Sub(periods[n-1].Start)))
+		}
+		fmt.Fprintf(sb, " Bz %+.1f nT (%s)", last.Bz, dir)
+	}
+	if !math.IsNaN(last.Bt) {
+		fmt.Fprintf(sb, " • Bt %.1f nT", last.Bt)
+	}
+	if !math.IsNaN(last.Speed) {
+		fmt.Fprintf(sb, " • velocità %.0f km/s", last.Speed)
+	}
+	if !math.IsNaN(last.Density) {
+		fmt.Fprintf(sb, " • densità %.1f p/cm³", last.Density)
+	}
+	if !math.IsNaN(last.Speed) && last.Speed > 0 {
+		delay := time.Duration(swL1Km / last.Speed * float64(time.Second))
+		fmt.Fprintf(sb, "\nArrivo alla Terra fra circa %s (verso le %s).", formatMinutes(delay), last.Time.Add(delay).In(loc).Format("15:04"))
+	}
+	return sb.String()
+}
+
+// formatBzPeriods elenca gli intervalli di Bz sud sostenuto, dal più recente.
+func formatBzPeriods(periods []models.BzSouthPeriod, loc *time.Location) string {
+	if len(periods) == 0 {
+		return fmt.Sprintf("Nessun intervallo con Bz sotto %.0f nT per almeno %d minuti nel periodo mostrato.",
+			services.BzSouthThreshold, services.BzSouthMinutes)
+	}
+	sb := &strings.Builder{}
+	sb.WriteString("Bz sud sostenuto:")
+	for i := len(periods) - 1; i >= 0 && i >= len(periods)-swMaxPeriod; i-- {
+		p := periods[i]
+		fmt.Fprintf(sb, "\n• %s – %s (%s), minimo %.1f nT",
+			p.Start.In(loc).Format("02/01 15:04"), p.End.In(loc).Format("15:04"), formatMinutes(p.End.Sub(p.Start)), p.MinBz)
+	}
+	if n := len(periods) - swMaxPeriod; n > 0 {
+		fmt.Fprintf(sb, "\n… e altri %d", n)
+	}
+	return sb.String()
+}
+
+// formatMinutes scrive una durata come "45 min" o "2 h 10 min".
+func formatMinutes(d time.Duration) string {
+	m := int(d.Round(time.Minute).Minutes())
+	if m < 60 {
+		return fmt.Sprintf("%d min", m)
+	}
+	if m%60 == 0 {
+		return fmt.Sprintf("%d h", m/60)
+	}
+	return fmt.Sprintf("%d h %d min", m/60, m%60)
+}
+
+// swPanel è uno dei tre pannelli del grafico (scala lineare lo..hi)
+type swPanel struct {
+	title  string
+	top    int
+	lo, hi float64
+	ticks  []float64
+	value  func(models.SolarWindSample) float64
+}
+
+func (p swPanel) y(v float64) float64 {
+	v = math.Max(p.lo, math.Min(p.hi, v))
+	return float64(p.top) + float64(swPanelH-1)*(p.hi-v)/(p.hi-p.lo)
+}
+
+// buildSolarWindChart disegna Bt/Bz, velocità e densità con l'asse dei tempi
+// in comune; gli intervalli di Bz sud sostenuto sono ombreggiati in rosso.
+func buildSolarWindChart(samples []models.SolarWindSample, periods []models.BzSouthPeriod, loc *time.Location) fyne.CanvasObject {
+	height := 3*(swTitleH+swPanelH) + swAxisH
+	img := image.NewNRGBA(image.Rect(0, 0, swChartW, height))
+	overlay := []fyne.CanvasObject{}
+
+	// scale: simmetrica per il campo, con un minimo per i periodi quieti
+	maxB, maxV, maxN := 0.0, 0.0, 0.0
+	for _, s := range samples {
+		for _, b := range []float64{s.Bt, s.Bz} {
+			if !math.IsNaN(b) {
+				maxB = math.Max(maxB, math.Abs(b))
+			}
+		}
+		if !math.IsNaN(s.Speed) {
+			maxV = math.Max(maxV, s.Speed)
+		}
+		if !math.IsNaN(s.Density) {
+			maxN = math.Max(maxN, s.Density)
+		}
+	}
+	bScale := math.Max(10, 5*math.Ceil(maxB/5))
+	vMax := math.Max(700, 100*math.Ceil(maxV/100))
+	nMax := math.Max(20, 10*math.Ceil(maxN/10))
+
+	panels := []swPanel{
+		{title: "Bt / Bz (nT)", lo: -bScale, hi: bScale, ticks: []float64{-bScale, 0, bScale},
+			value: func(s models.SolarWindSample) float64 { return s.Bz }},
+		{title: "Velocità (km/s)", lo: 200, hi: vMax, ticks: []float64{200, 200 + (vMax-200)/2, vMax},
+			value: func(s models.SolarWindSample) float64 { return s.Speed }},
+		{title: "Densità (protoni/cm³)", lo: 0, hi: nMax, ticks: []float64{0, nMax / 2, nMax},
+			value: func(s models.SolarWindSample) float64 { return s.Density }},
+	}
+	for i := range panels {
+		panels[i].top = i*(swTitleH+swPanelH) + swTitleH
+	}
+
+	start, end := samples[0].Time, samples[len(samples)-1].Time
+	span := end.Sub(start)
+	if span <= 0 {
+		span = time.Minute
+	}
+	xOf := func(t time.Time) int {
+		return int(float64(swChartW-1) * float64(t.Sub(start)) / float64(span))
+	}
+
+	// sfondo dei pannelli con gli intervalli di Bz sud ombreggiati
+	south := make([]bool, swChartW)
+	for _, p := range periods {
+		for x := max(0, xOf(p.Start)); x <= min(swChartW-1, xOf(p.End)); x++ {
+			south[x] = true
+		}
+	}
+	for _, p := range panels {
+		for y := p.top; y < p.top+swPanelH; y++ {
+			for x := 0; x < swChartW; x++ {
+				c := swPanelBg
+				if south[x] {
+					c = blendNRGBA(swPanelBg, swSouthShade, 0.3)
+				}
+				img.SetNRGBA(x, y, c)
+			}
+		}
+		for _, v := range p.ticks {
+			y := int(p.y(v))
+			for x := 0; x < swChartW; x += 2 {
+				img.SetNRGBA(x, y, swGridColor)
+			}
+			lbl := canvas.NewText(fmt.Sprintf("%.0f", v), color.NRGBA{R: 160, G: 160, B: 160, A: 255})
+			lbl.TextSize = 10
+			lbl.Move(fyne.NewPos(3, float32(math.Min(float64(p.top+swPanelH-13), math.Max(float64(p.top), float64(y)-7)))))
+			overlay = append(overlay, lbl)
+		}
+		title := canvas.NewText(p.title, color.NRGBA{R: 220, G: 220, B: 220, A: 255})
+		title.TextSize = 11
+		title.TextStyle = fyne.TextStyle{Bold: true}
+		title.Move(fyne.NewPos(0, float32(p.top-swTitleH+2)))
+		overlay = append(overlay, title)
+	}
+
+	// griglia temporale nell'ora del sito
+	step, format := 15*time.Minute, "15:04"
+	switch {
+	case span > 36*time.Hour:
+		step, format = 24*time.Hour, "02/01"
+	case span > 3*time.Hour:
+		step = 3 * time.Hour
+	}
+	ls := start.In(loc)
+	for t := time.Date(ls.Year(), ls.Month(), ls.Day(), 0, 0, 0, 0, loc); t.Before(end); t = t.Add(step) {
+		if !t.After(start) {
+			continue
+		}
+		x := xOf(t)
+		for _, p := range panels {
+			for y := p.top; y < p.top+swPanelH; y += 2 {
+				img.SetNRGBA(x, y, swGridColor)
+			}
+		}
+		if x < 14 || x > swChartW-20 {
+			continue // l'etichetta uscirebbe dal grafico
+		}
+		lbl := canvas.NewText(t.In(loc).Format(format), color.NRGBA{R: 190, G: 190, B: 190, A: 255})
+		lbl.TextSize = 10
+		lbl.Move(fyne.NewPos(float32(x-14), float32(height-swAxisH+2)))
+		overlay = append(overlay, lbl)
+	}
+
+	// serie: Bt sotto Bz, colorato secondo il segno
+	zeroY := int(panels[0].y(0))
+	plotSolarWind(img, samples, xOf, panels[0], func(s models.SolarWindSample) float64 { return s.Bt },
+		func(int) color.NRGBA { return swBtColor })
+	plotSolarWind(img, samples, xOf, panels[0], panels[0].value, func(y int) color.NRGBA {
+		if y > zeroY {
+			return swBzSouth
+		}
+		return swBzNorth
+	})
+	plotSolarWind(img, samples, xOf, panels[1], panels[1].value, func(int) color.NRGBA { return swSpeedColor })
+	plotSolarWind(img, samples, xOf, panels[2], panels[2].value, func(int) color.NRGBA { return swDensColor })
+
+	chartImg := canvas.NewImageFromImage(img)
+	chartImg.FillMode = canvas.ImageFillOriginal
+	chartImg.ScaleMode = canvas.ImageScalePixels
+	chartImg.Resize(fyne.NewSize(swChartW, float32(height)))
+
+	bg := canvas.NewRectangle(color.Transparent)
+	bg.SetMinSize(fyne.NewSize(swChartW, float32(height)))
+	objs := append([]fyne.CanvasObject{bg, chartImg}, overlay...)
+	return container.NewWithoutLayout(objs...)
+}
+
+// plotSolarWind traccia una serie nel pannello: per ogni colonna di pixel la
+// escursione min..max dei campioni (con 7 giorni ogni colonna ne raccoglie
+// molti), unita alla colonna precedente se i dati non hanno buchi.
+func plotSolarWind(img *image.NRGBA, samples []models.SolarWindSample, xOf func(time.Time) int,
+	p swPanel, value func(models.SolarWindSample) float64, colorAt func(y int) color.NRGBA) {
+	type column struct {
+		lo, hi, first, last float64
+		tFirst, tLast       time.Time
+		ok                  bool
+	}
+	cols := make([]column, swChartW)
+	for _, s := range samples {
+		v := value(s)
+		x := xOf(s.Time)
+		if math.IsNaN(v) || x < 0 || x >= swChartW {
+			continue
+		}
+		c := &cols[x]
+		if !c.ok {
+			*c = column{lo: v, hi: v, first: v, tFirst: s.Time, ok: true}
+		}
+		c.lo, c.hi, c.last, c.tLast = math.Min(c.lo, v), math.Max(c.hi, v), v, s.Time
+	}
+
+	line := func(x int, y0, y1 float64) {
+		if y0 > y1 {
+			y0, y1 = y1, y0
+		}
+		for y := int(y0); y <= int(y1); y++ {
+			img.SetNRGBA(x, y, colorAt(y))
+		}
+	}
+	prev := -1
+	for x, c := range cols {
+		if !c.ok {
+			continue
+		}
+		line(x, p.y(c.lo), p.y(c.hi))
+		// raccordo lineare con la colonna precedente (salvo buchi > 10 minuti)
+		if prev >= 0 && c.tFirst.Sub(cols[prev].tLast) <= 10*time.Minute {
+			y0, y1 := p.y(cols[prev].last), p.y(c.first)
+			for xi := prev + 1; xi <= x; xi++ {
+				f := float64(xi-prev) / float64(x-prev)
+				fPrev := float64(xi-1-prev) / float64(x-prev)
+				line(xi, y0+(y1-y0)*fPrev, y0+(y1-y0)*f)
+			}
+		}
+		prev = x
+	}
+}
@@ -1,8 +1,6 @@
 package ui
 
 import (
-	"image/color"
-
 	"github.com/cr4sh87/astro-lair-go/services"
 
 	"fyne.io/fyne/v2"
@@ -33,12 +31,15 @@ func buildSpaceWeatherView() fyne.CanvasObject {
 	)
 
 	subtitle := widget.NewLabel(
-		"Immagini in tempo quasi-reale dall'NOAA Space Weather Prediction Center.\n" +
+		"Indice Kp, vento solare e immagini in tempo quasi-reale dall'NOAA Space Weather Prediction Center.\n" +
 			"Aurora boreale/australe e panoramica animata del meteo spaziale.",
 	)
 
 	statusLabel := widget.NewLabel("")
 
+	// probabilità OVATION al sito, mostrata anche sotto la mappa del suo emisfero
+	ovation := newOvationView()
+
 	// Aurora Nord / Sud
 	northImg := canvas.NewImageFromResource(nil)
 	northImg.FillMode = canvas.ImageFillContain
@@ -49,22 +50,24 @@ func buildSpaceWeatherView() fyne.CanvasObject {
 	southImg.SetMinSize(fyne.NewSize(260, 260))
 
 	btnLoadNorth := widget.NewButton("Aggiorna Aurora Nord", func() {
-		services.LoadRemoteImage(auroraNorthURL, "Aurora Nord", northImg, statusLabel)
+		services.LoadRemoteImage(auroraNorthURL, "Aurora Nord", northImg, statusLabel, nil)
 	})
 
 	btnLoadSouth := widget.NewButton("Aggiorna Aurora Sud", func() {
-		services.LoadRemoteImage(auroraSouthURL, "Aurora Sud", southImg, statusLabel)
+		services.LoadRemoteImage(auroraSouthURL, "Aurora Sud", southImg, statusLabel, nil)
 	})
 
 	auroraNorthBox := container.NewVBox(
 		widget.NewLabelWithStyle("Aurora – Emisfero Nord", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
 		northImg,
+		ovation.northLabel,
 		btnLoadNorth,
 	)
 
 	auroraSouthBox := container.NewVBox(
 		widget.NewLabelWithStyle("Aurora – Emisfero Sud", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
 		southImg,
+		ovation.southLabel,
 		btnLoadSouth,
 	)
 
@@ -82,8 +85,7 @@ func buildSpaceWeatherView() fyne.CanvasObject {
 
 	overviewStatus := widget.NewLabel("")
 
-	// Oggetto fittizio usato come parent per la popup
-	overviewPlaceholder := canvas.NewRectangle(color.Transparent)
+	overviewViewer := &frameViewer{title: "Space Weather Overview – NOAA SWPC"}
 
 	btnShowOverview := widget.NewButton("Mostra GIF NOAA", func() {
 		services.LoadRemoteAnimation(spaceWeatherOverviewGIF, "Space Weather Overview", overviewStatus, func(frames []fyne.Resource) {
@@ -92,7 +94,7 @@ func buildSpaceWeatherView() fyne.CanvasObject {
 				return
 			}
 			overviewStatus.SetText("GIF NOAA pronta.")
-			showResourcesDialog(overviewPlaceholder, frames, "Space Weather Overview – NOAA SWPC")
+			overviewViewer.show(frames)
 		})
 	})
 
@@ -108,7 +110,10 @@ func buildSpaceWeatherView() fyne.CanvasObject {
 		title,
 		subtitle,
 		widget.NewSeparator(),
+		buildKpCard(),
 		auroraRow,
+		ovation.card,
+		buildSolarWindCard(),
 		widget.NewSeparator(),
 		overviewBox,
 		widget.NewSeparator(),
@@ -117,8 +122,8 @@ func buildSpaceWeatherView() fyne.CanvasObject {
 	)
 
 	// Caricamento iniziale delle mappe aurorali
-	services.LoadRemoteImage(auroraNorthURL, "Aurora Nord", northImg, statusLabel)
-	services.LoadRemoteImage(auroraSouthURL, "Aurora Sud", southImg, statusLabel)
+	services.LoadRemoteImage(auroraNorthURL, "Aurora Nord", northImg, statusLabel, nil)
+	services.LoadRemoteImage(auroraSouthURL, "Aurora Sud", southImg, statusLabel, nil)
 
 	return container.NewVScroll(content)
 }
@@ -1,19 +1,18 @@
 package ui
 
 import (
-	"encoding/json"
 	"fmt"
-	"math"
-	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cr4sh87/astro-lair-go/models"
 	"github.com/cr4sh87/astro-lair-go/services"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -26,183 +25,119 @@ type DsoCatalog = models.DsoCatalog
 type DsoObject = models.DsoObject
 type TargetObject = models.TargetObject
 
-var targetsByCatalog = models.TargetsByaCatalog
-
 func floatPtr(f float64) *float64 { return models.FloatPtr(f) }
 
-// =======================
-//  Helpers RA/Dec
-// =======================
-
-func formatRAFromDeg(raDeg float64) string {
-	// RA in ore: gradi / 15
-	totalSeconds := raDeg / 15.0 * 3600.0
-	if totalSeconds < 0 {
-		totalSeconds = math.Mod(totalSeconds, 24*3600)
-		if totalSeconds < 0 {
-			totalSeconds += 24 * 3600
-		}
-	}
-	h := int(totalSeconds / 3600)
-	m := int(totalSeconds/60) % 60
-	s := totalSeconds - float64(h*3600+m*60)
-	return fmt.Sprintf("%02dh %02dm %04.1fs", h, m, s)
-}
-
-func formatDecFromDeg(decDeg float64) string {
-	sign := '+'
-	if decDeg < 0 {
-		sign = '-'
-	}
-	absDeg := math.Abs(decDeg)
-	totalArcsec := absDeg * 3600.0
-	d := int(totalArcsec / 3600)
-	m := int(totalArcsec/60) % 60
-	s := totalArcsec - float64(d*3600+m*60)
-	return fmt.Sprintf("%c%02d° %02d' %04.1f\"", sign, d, m, s)
-}
-
 // =======================
 //  Catalog loading
 // =======================
 
 func buildTargetsCatalog() map[string][]TargetObject {
-	data, err := os.ReadFile(services.DSOCatalogLocalPath)
-	if err == nil {
-		m, err := parseDsoTargetsJSON(data)
-		if err == nil && len(m) > 0 {
-			fmt.Printf("[Targets] Caricato catalogo dinamico (%d cataloghi)\n", len(m))
-			return m
-		}
-		fmt.Printf("[Targets] Catalogo JSON non valido o vuoto, uso fallback. err=%v len=%d\n", err, len(m))
-	} else {
-		fmt.Printf("[Targets] Nessun catalogo locale (%s): %v. Uso fallback.\n", services.DSOCatalogLocalPath, err)
-	}
-
-	// fallback: catalogo hardcoded
-	return targetsByCatalog
+	return services.LoadTargetsCatalog()
 }
 
-func parseDsoTargetsJSON(data []byte) (map[string][]TargetObject, error) {
-	var cat DsoCatalog
-	if err := json.Unmarshal(data, &cat); err != nil {
-		return nil, err
-	}
+type TargetsView struct {
+	allByCatalog    map[string][]TargetObject
+	catalogNames    []string
+	currentCatalog  string
+	allTargets      []TargetObject
+	filtered        []TargetObject
+	root            fyne.CanvasObject
+	list            *widget.List
+	searchEntry     *widget.Entry
+	detailLabel     *widget.Label
+	catalogSelector *widget.Select
 
-	out := make(map[string][]TargetObject)
+	// aggiornamento catalogo DSO
+	catalogStatus   *widget.Label
+	catalogProgress *widget.ProgressBar
+	updateBtn       *widget.Button
+	rollbackBtn     *widget.Button
+
+	// posizione dei target per il sito salvato e l'istante scelto
+	obsTime      time.Time
+	siteLat      float64
+	siteLon      float64
+	visibility   map[string]models.TargetVisibility
+	moon         models.MoonPosition
+	timeEntry    *widget.Entry
+	sortSelector *widget.Select
+	sortKey      string
+
+	// modalità "visibili stanotte"
+	tonightMode   bool
+	tonight       map[string]models.NightVisibility
+	tonightCheck  *widget.Check
+	minAltEntry   *widget.Entry
+	maxMagEntry   *widget.Entry
+	minSizeEntry  *widget.Entry
+	tonightStatus *widget.Label
+}
 
-	for _, o := range cat.Objects {
-		if strings.TrimSpace(o.Catalog) == "" {
-			continue
-		}
+// Chiavi di ordinamento della lista target
+const (
+	sortByCode      = "Codice"
+	sortByName      = "Nome"
+	sortByMagnitude = "Magnitudine"
+	sortByAltitude  = "Altezza"
+	sortByAzimuth   = "Azimut"
+	sortByHourAngle = "Angolo orario"
+	sortByAirmass   = "Airmass"
+	sortByTransit   = "Transito"
+	sortByTonight   = "Ore stanotte"
+	sortByMoon      = "Disturbo Luna"
+)
 
-		code := o.Code
-		if code == "" && o.Number != nil {
-			code = fmt.Sprintf("%d", *o.Number)
-		}
-		if code == "" {
-			code = o.ID
-		}
+var targetSortKeys = []string{
+	sortByCode, sortByName, sortByMagnitude, sortByAltitude,
+	sortByAzimuth, sortByHourAngle, sortByAirmass, sortByTransit,
+	sortByTonight, sortByMoon,
+}
 
-		var mag float64
-		if o.Mag != nil {
-			mag = *o.Mag
-		} else {
-			mag = 0
-		}
+// defaultTonightMinAlt è l'altezza minima proposta per la modalità "stanotte"
+const defaultTonightMinAlt = 30.0
 
-		raStr := ""
-		if o.RADeg != nil {
-			raStr = formatRAFromDeg(*o.RADeg)
-		}
-		decStr := ""
-		if o.DecDeg != nil {
-			decStr = formatDecFromDeg(*o.DecDeg)
-		}
+// targetKey identifica un target all'interno dei cataloghi
+func targetKey(t TargetObject) string {
+	return t.Catalog + "|" + t.Code
+}
 
-		t := TargetObject{
-			Catalog:       o.Catalog,
-			Code:          code,
-			Name:          o.Name,
-			Type:          o.Type,
-			Magnitude:     mag,
-			SurfaceBright: o.SurfaceBrightness,
-			RA:            raStr,
-			Dec:           decStr,
-			Constellation: o.Constellation,
-		}
+// =======================
+//  Sorgente catalogo (Preferences)
+// =======================
 
-		out[o.Catalog] = append(out[o.Catalog], t)
-	}
+const prefCatalogURL = "dso.catalog_url"
 
-	for k, list := range out {
-		sortTargetsByNumericCode(list)
-		out[k] = list
+// loadCatalogURL restituisce l'URL configurato per il catalogo ("" = default)
+func loadCatalogURL() string {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return ""
 	}
-
-	return out, nil
+	return app.Preferences().String(prefCatalogURL)
 }
 
-// helper per ordinare i target in base alla parte numerica del codice
-func sortTargetsByNumericCode(list []TargetObject) {
-	sort.Slice(list, func(i, j int) bool {
-		return extractNumericCode(list[i].Code) < extractNumericCode(list[j].Code)
-	})
-}
-
-func extractNumericCode(code string) int {
-	var digits []rune
-	for _, ch := range code {
-		if ch >= '0' && ch <= '9' {
-			digits = append(digits, ch)
-		}
-	}
-	if len(digits) == 0 {
-		return math.MaxInt32
-	}
-	v, err := strconv.Atoi(string(digits))
-	if err != nil {
-		return math.MaxInt32
+// saveCatalogURL salva l'URL del mirror del catalogo ("" ripristina il default)
+func saveCatalogURL(url string) {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return
 	}
-	return v
-}
-
-type TargetsView struct {
-	allByCatalog    map[string][]TargetObject
-	catalogNames    []string
-	currentCatalog  string
-	allTargets      []TargetObject
-	filtered        []TargetObject
-	root            fyne.CanvasObject
-	list            *widget.List
-	searchEntry     *widget.Entry
-	detailLabel     *widget.Label
-	catalogSelector *widget.Select
+	app.Preferences().SetString(prefCatalogURL, strings.TrimSpace(url))
 }
 
 // 👇 nome standardizzato
 func BuildTargetsView(byCatalog map[string][]TargetObject) *TargetsView {
 	tv := &TargetsView{
 		allByCatalog: byCatalog,
+		obsTime:      siteNow(),
+		sortKey:      sortByCode,
 	}
 
-	for name := range byCatalog {
-		tv.catalogNames = append(tv.catalogNames, name)
-	}
-	sort.Strings(tv.catalogNames)
-
-	if len(tv.catalogNames) > 0 {
-		tv.currentCatalog = tv.catalogNames[0]
-	}
-
-	for k, v := range tv.allByCatalog {
-		copyList := append([]TargetObject(nil), v...)
-		sortTargetsByNumericCode(copyList)
-		tv.allByCatalog[k] = copyList
-	}
+	tv.setCatalogData(byCatalog)
 
 	tv.allTargets = append(tv.allTargets, tv.allByCatalog[tv.currentCatalog]...)
 	tv.filtered = append(tv.filtered, tv.allTargets...)
+	tv.updateVisibility()
 
 	tv.searchEntry = widget.NewEntry()
 	tv.searchEntry.SetPlaceHolder("Cerca per nome, codice o costellazione…")
@@ -244,11 +179,22 @@ func BuildTargetsView(byCatalog map[string][]TargetObject) *TargetsView {
 			sub := box.Objects[1].(*widget.Label)
 
 			title.SetText(t.Name)
-			sub.SetText(fmt.Sprintf("%s • %s • mag %.1f",
+			text := fmt.Sprintf("%s • %s • mag %s",
 				fmt.Sprintf("%s %s", t.Catalog, t.Code),
 				t.Constellation,
-				t.Magnitude,
-			))
+				formatMagnitude(t.Magnitude),
+			)
+			if v, ok := tv.visibility[targetKey(t)]; ok {
+				text += fmt.Sprintf(" • alt %.0f° az %.0f°", v.AltDeg, v.AzDeg)
+				text += fmt.Sprintf(" • Luna %.0f°", v.Moon.SeparationDeg)
+				if v.Moon.SkyBrighteningMag > 0 {
+					text += fmt.Sprintf(" (+%.1f mag)", v.Moon.SkyBrighteningMag)
+				}
+			}
+			if n, ok := tv.tonight[targetKey(t)]; ok {
+				text += fmt.Sprintf(" • %.1f h stanotte", n.HoursAbove)
+			}
+			sub.SetText(text)
 		},
 	)
 
@@ -260,14 +206,101 @@ func BuildTargetsView(byCatalog map[string][]TargetObject) *TargetsView {
 		tv.detailLabel.SetText(tv.formatDetails(t))
 	}
 
+	tv.timeEntry = widget.NewEntry()
+	tv.timeEntry.SetPlaceHolder("YYYY-MM-DD HH:MM")
+	tv.timeEntry.SetText(tv.obsTime.Format("2006-01-02 15:04"))
+	tv.timeEntry.OnSubmitted = func(string) {
+		tv.setObsTimeFromEntry()
+	}
+
+	nowBtn := widget.NewButton("Adesso", func() {
+		tv.obsTime = siteNow()
+		tv.timeEntry.SetText(tv.obsTime.Format("2006-01-02 15:04"))
+		tv.refreshPositions()
+	})
+
+	// cambio di sito: stesso istante, espresso nel fuso del nuovo sito
+	onSiteChanged(func() {
+		tv.obsTime = tv.obsTime.In(siteLocation())
+		tv.timeEntry.SetText(tv.obsTime.Format("2006-01-02 15:04"))
+		tv.refreshPositions()
+	})
+	timeBtn := widget.NewButton("Calcola", func() {
+		tv.setObsTimeFromEntry()
+	})
+
+	tv.sortSelector = widget.NewSelect(targetSortKeys, func(selected string) {
+		tv.sortKey = selected
+		tv.sortFiltered()
+		tv.list.Refresh()
+	})
+	tv.sortSelector.Selected = tv.sortKey
+
+	tv.minAltEntry = widget.NewEntry()
+	tv.minAltEntry.SetText(fmt.Sprintf("%.0f", defaultTonightMinAlt))
+	tv.maxMagEntry = widget.NewEntry()
+	tv.maxMagEntry.SetPlaceHolder("es. 10")
+	tv.minSizeEntry = widget.NewEntry()
+	tv.minSizeEntry.SetPlaceHolder("es. 5")
+	tv.tonightStatus = widget.NewLabel("")
+	tv.tonightStatus.Wrapping = fyne.TextWrapWord
+	tv.tonightStatus.Hide()
+
+	tv.tonightCheck = widget.NewCheck("Visibili stanotte", func(on bool) {
+		tv.setTonightMode(on)
+	})
+	applyTonightBtn := widget.NewButton("Applica", func() {
+		if tv.tonightMode {
+			tv.refreshPositions()
+		} else {
+			tv.tonightCheck.SetChecked(true)
+		}
+	})
+
+	tonightForm := container.NewGridWithColumns(3,
+		widget.NewLabel("Alt. min (°)"),
+		widget.NewLabel("Mag. limite"),
+		widget.NewLabel("Dim. min (′)"),
+		tv.minAltEntry,
+		tv.maxMagEntry,
+		tv.minSizeEntry,
+	)
+
 	topControls := container.NewVBox(
 		tv.catalogSelector,
 		tv.searchEntry,
+		container.NewBorder(nil, nil, nil, container.NewHBox(timeBtn, nowBtn), tv.timeEntry),
+		container.NewBorder(nil, nil, widget.NewLabel("Ordina per"), nil, tv.sortSelector),
+		container.NewBorder(nil, nil, nil, applyTonightBtn, tv.tonightCheck),
+		tonightForm,
+		tv.tonightStatus,
+	)
+
+	tv.catalogStatus = widget.NewLabel("")
+	tv.catalogStatus.Wrapping = fyne.TextWrapWord
+	tv.catalogProgress = widget.NewProgressBar()
+	tv.catalogProgress.Hide()
+
+	tv.updateBtn = widget.NewButton("Aggiorna catalogo", func() {
+		tv.StartCatalogUpdate()
+	})
+	tv.rollbackBtn = widget.NewButton("Precedente", func() {
+		tv.rollbackCatalog()
+	})
+	sourceBtn := widget.NewButton("Sorgente…", func() {
+		tv.showCatalogSourceDialog()
+	})
+
+	catalogControls := container.NewVBox(
+		widget.NewSeparator(),
+		container.NewHBox(tv.updateBtn, tv.rollbackBtn, sourceBtn),
+		tv.catalogProgress,
+		tv.catalogStatus,
 	)
 
 	listCard := container.NewBorder(
 		topControls,
-		nil,
+		catalogControls,
 		nil,
 		nil,
 		tv.list,
@@ -289,48 +322,443 @@ func BuildTargetsView(byCatalog map[string][]TargetObject) *TargetsView {
 	return tv
 }
 
+// setCatalogData imposta i dati dei cataloghi (copie ordinate) e la lista dei nomi.
+// Mantiene il catalogo corrente se esiste ancora.
+func (tv *TargetsView) setCatalogData(byCatalog map[string][]TargetObject) {
+	tv.allByCatalog = make(map[string][]TargetObject, len(byCatalog))
+	tv.catalogNames = tv.catalogNames[:0]
+
+	for k, v := range byCatalog {
+		copyList := append([]TargetObject(nil), v...)
+		services.SortTargetsByNumericCode(copyList)
+		tv.allByCatalog[k] = copyList
+		tv.catalogNames = append(tv.catalogNames, k)
+	}
+	sort.Strings(tv.catalogNames)
+
+	if _, ok := tv.allByCatalog[tv.currentCatalog]; !ok {
+		tv.currentCatalog = ""
+		if len(tv.catalogNames) > 0 {
+			tv.currentCatalog = tv.catalogNames[0]
+		}
+	}
+}
+
+// AllTargets restituisce i target di tutti i cataloghi caricati.
+func (tv *TargetsView) AllTargets() []TargetObject {
+	var out []TargetObject
+	for _, name := range tv.catalogNames {
+		out = append(out, tv.allByCatalog[name]...)
+	}
+	return out
+}
+
+// Reload sostituisce i cataloghi mostrati (es. dopo un aggiornamento del file JSON).
+func (tv *TargetsView) Reload(byCatalog map[string][]TargetObject) {
+	tv.setCatalogData(byCatalog)
+	tv.catalogSelector.SetOptions(tv.catalogNames)
+	tv.catalogSelector.Selected = tv.currentCatalog
+	tv.catalogSelector.Refresh()
+	tv.switchCatalog(tv.currentCatalog)
+}
+
+// StartCatalogUpdate scarica in background il catalogo DSO, mostrando il progresso,
+// e ricarica la vista se è arrivata una versione più recente.
+func (tv *TargetsView) StartCatalogUpdate() {
+	url := services.ResolveDSOCatalogURL(loadCatalogURL())
+
+	tv.updateBtn.Disable()
+	tv.rollbackBtn.Disable()
+	tv.catalogProgress.SetValue(0)
+	tv.catalogProgress.Show()
+	tv.catalogStatus.SetText("Controllo aggiornamenti catalogo…")
+
+	go func() {
+		var last time.Time
+		progress := func(done, total int64) {
+			if time.Since(last) < 100*time.Millisecond {
+				return
+			}
+			last = time.Now()
+			fyne.Do(func() {
+				if total > 0 {
+					tv.catalogProgress.SetValue(float64(done) / float64(total))
+				}
+				tv.catalogStatus.SetText(fmt.Sprintf("Scarico catalogo… %.1f MB", float64(done)/(1<<20)))
+			})
+		}
+
+		res, err := services.UpdateDSOCatalog(url, progress)
+
+		fyne.Do(func() {
+			tv.catalogProgress.Hide()
+			tv.updateBtn.Enable()
+			tv.rollbackBtn.Enable()
+			if err != nil {
+				tv.catalogStatus.SetText("Aggiornamento catalogo fallito, uso il file locale:\n" + err.Error())
+				return
+			}
+			tv.catalogStatus.SetText(res.Message)
+			if res.Updated {
+				tv.Reload(services.LoadTargetsCatalog())
+			}
+		})
+	}()
+}
+
+func (tv *TargetsView) rollbackCatalog() {
+	if err := services.RollbackDSOCatalog(); err != nil {
+		tv.catalogStatus.SetText("Rollback impossibile: " + err.Error())
+		return
+	}
+	tv.catalogStatus.SetText("Ripristinato il catalogo precedente.")
+	tv.Reload(services.LoadTargetsCatalog())
+}
+
+// showCatalogSourceDialog permette di puntare il catalogo a un mirror.
+func (tv *TargetsView) showCatalogSourceDialog() {
+	win := fyne.CurrentApp().Driver().AllWindows()
+	if len(win) == 0 {
+		return
+	}
+
+	urlEntry := widget.NewEntry()
+	urlEntry.SetText(loadCatalogURL())
+	urlEntry.SetPlaceHolder(services.ResolveDSOCatalogURL(""))
+
+	dialog.NewForm(
+		"Sorgente catalogo DSO",
+		"Salva",
+		"Annulla",
+		[]*widget.FormItem{
+			widget.NewFormItem("URL", urlEntry),
+		},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			saveCatalogURL(urlEntry.Text)
+			tv.catalogStatus.SetText("Sorgente: " + services.ResolveDSOCatalogURL(urlEntry.Text))
+		},
+		win[0],
+	).Show()
+}
+
 func (tv *TargetsView) switchCatalog(catalog string) {
 	tv.currentCatalog = catalog
 	tv.allTargets = tv.allTargets[:0]
 	tv.allTargets = append(tv.allTargets, tv.allByCatalog[catalog]...)
+	tv.updateVisibility()
 	tv.applyFilter(tv.searchEntry.Text)
 }
 
-func (tv *TargetsView) applyFilter(q string) {
-	q = strings.TrimSpace(strings.ToLower(q))
-	tv.filtered = tv.filtered[:0]
+// setObsTimeFromEntry legge l'istante dal campo data/ora (ora del sito)
+func (tv *TargetsView) setObsTimeFromEntry() {
+	t, err := time.ParseInLocation("2006-01-02 15:04", strings.TrimSpace(tv.timeEntry.Text), siteLocation())
+	if err != nil {
+		tv.detailLabel.SetText("Data/ora non valida: usa il formato YYYY-MM-DD HH:MM.")
+		return
+	}
+	tv.obsTime = t
+	tv.refreshPositions()
+}
 
-	if q == "" {
-		tv.filtered = append(tv.filtered, tv.allTargets...)
-		tv.list.Refresh()
+// refreshPositions ricalcola le posizioni e aggiorna lista e ordinamento
+func (tv *TargetsView) refreshPositions() {
+	tv.updateVisibility()
+	tv.applyFilter(tv.searchEntry.Text)
+	tv.list.UnselectAll()
+	tv.list.Refresh()
+	tv.detailLabel.SetText("Seleziona un target per vedere i dettagli.")
+}
+
+// updateVisibility calcola alt/az e sorgere/transito/tramonto dei target del
+// catalogo corrente per il sito selezionato.
+func (tv *TargetsView) updateVisibility() {
+	tv.siteLat, tv.siteLon = observerLocation()
+	tv.moon = services.ComputeMoonPosition(tv.obsTime, tv.siteLat, tv.siteLon)
+	tv.visibility = make(map[string]models.TargetVisibility, len(tv.allTargets))
+	for _, t := range tv.allTargets {
+		if v, ok := services.ComputeTargetVisibility(t, tv.siteLat, tv.siteLon, tv.obsTime); ok {
+			v.Moon = services.ComputeMoonInterference(tv.moon, *t.RADeg, *t.DecDeg, v.AltDeg)
+			tv.visibility[targetKey(t)] = v
+		}
+	}
+	tv.updateTonight()
+}
+
+// setTonightMode attiva/disattiva la modalità "visibili stanotte"
+func (tv *TargetsView) setTonightMode(on bool) {
+	tv.tonightMode = on
+	if on {
+		tv.sortKey = sortByTonight
+		tv.tonightStatus.Show()
+	} else {
+		if tv.sortKey == sortByTonight {
+			tv.sortKey = sortByCode
+		}
+		tv.tonightStatus.Hide()
+	}
+	tv.sortSelector.Selected = tv.sortKey
+	tv.sortSelector.Refresh()
+	tv.refreshPositions()
+}
+
+// tonightFilter legge i criteri dai campi; i campi vuoti disattivano il filtro.
+func (tv *TargetsView) tonightFilter() (models.TonightFilter, error) {
+	f := models.TonightFilter{MinAltDeg: defaultTonightMinAlt}
+	parse := func(e *widget.Entry, dst *float64, name string) error {
+		txt := strings.TrimSpace(strings.ReplaceAll(e.Text, ",", "."))
+		if txt == "" {
+			return nil
+		}
+		v, err := strconv.ParseFloat(txt, 64)
+		if err != nil {
+			return fmt.Errorf("%s non valida: %q", name, e.Text)
+		}
+		*dst = v
+		return nil
+	}
+	if err := parse(tv.minAltEntry, &f.MinAltDeg, "altezza minima"); err != nil {
+		return f, err
+	}
+	if err := parse(tv.maxMagEntry, &f.MaxMagnitude, "magnitudine limite"); err != nil {
+		return f, err
+	}
+	if err := parse(tv.minSizeEntry, &f.MinSizeArcmin, "dimensione minima"); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// updateTonight ricalcola le ore sopra l'altezza minima durante il buio
+// astronomico della notte che contiene tv.obsTime.
+func (tv *TargetsView) updateTonight() {
+	tv.tonight = nil
+	if !tv.tonightMode {
 		return
 	}
+	tv.tonight = map[string]models.NightVisibility{}
+
+	f, err := tv.tonightFilter()
+	if err != nil {
+		tv.tonightStatus.SetText(err.Error())
+		return
+	}
+
+	day := services.NightDay(tv.obsTime)
+	np, ok := services.NewNightPlanner(day, tv.siteLat, tv.siteLon)
+	if !ok {
+		tv.tonightStatus.SetText(fmt.Sprintf("Nessun buio astronomico la notte del %s a questo sito.", day.Format("02/01")))
+		return
+	}
+	np.SetHorizon(currentSite().Horizon)
+
+	res := np.FilterTonight(tv.allTargets, f)
+	for _, r := range res {
+		tv.tonight[targetKey(r.Target)] = r.NightVisibility
+	}
+	tv.tonightStatus.SetText(fmt.Sprintf("Buio astronomico %s → %s: %d target sopra %.0f°.",
+		np.Window.Start.In(tv.obsTime.Location()).Format("02/01 15:04"),
+		np.Window.End.In(tv.obsTime.Location()).Format("02/01 15:04"),
+		len(res), f.MinAltDeg))
+}
+
+// sortFiltered ordina la lista filtrata secondo tv.sortKey; i target senza
+// coordinate (o sotto l'orizzonte, per airmass e disturbo lunare) finiscono in fondo.
+func (tv *TargetsView) sortFiltered() {
+	if tv.sortKey == sortByCode {
+		services.SortTargetsByNumericCode(tv.filtered)
+		return
+	}
+
+	less := func(a, b TargetObject) bool {
+		switch tv.sortKey {
+		case sortByTonight:
+			na, nb := tv.tonight[targetKey(a)], tv.tonight[targetKey(b)]
+			if na.HoursAbove != nb.HoursAbove {
+				return na.HoursAbove > nb.HoursAbove
+			}
+			return na.MaxAltDeg > nb.MaxAltDeg
+		case sortByName:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		case sortByMagnitude:
+			// senza magnitudine in fondo
+			if (a.Magnitude == nil) != (b.Magnitude == nil) {
+				return b.Magnitude == nil
+			}
+			return a.Magnitude != nil && *a.Magnitude < *b.Magnitude
+		}
+
+		va, okA := tv.visibility[targetKey(a)]
+		vb, okB := tv.visibility[targetKey(b)]
+		if tv.sortKey == sortByAirmass || tv.sortKey == sortByMoon {
+			okA = okA && va.Airmass > 0
+			okB = okB && vb.Airmass > 0
+		}
+		if okA != okB {
+			return okA
+		}
+		if !okA {
+			return false
+		}
+
+		switch tv.sortKey {
+		case sortByAltitude:
+			return va.AltDeg > vb.AltDeg
+		case sortByAzimuth:
+			return va.AzDeg < vb.AzDeg
+		case sortByHourAngle:
+			return va.HourAngleDeg < vb.HourAngleDeg
+		case sortByAirmass:
+			return va.Airmass < vb.Airmass
+		case sortByMoon:
+			if va.Moon.SkyBrighteningMag != vb.Moon.SkyBrighteningMag {
+				return va.Moon.SkyBrighteningMag < vb.Moon.SkyBrighteningMag
+			}
+			return va.Moon.SeparationDeg > vb.Moon.SeparationDeg
+		case sortByTransit:
+			return va.Transit.Before(*vb.Transit)
+		}
+		return false
+	}
+
+	sort.SliceStable(tv.filtered, func(i, j int) bool {
+		return less(tv.filtered[i], tv.filtered[j])
+	})
+}
+
+func (tv *TargetsView) applyFilter(q string) {
+	q = strings.TrimSpace(strings.ToLower(q))
+	tv.filtered = tv.filtered[:0]
 
 	for _, t := range tv.allTargets {
-		if strings.Contains(strings.ToLower(t.Name), q) ||
+		if tv.tonightMode {
+			if _, ok := tv.tonight[targetKey(t)]; !ok {
+				continue
+			}
+		}
+		if q == "" ||
+			strings.Contains(strings.ToLower(t.Name), q) ||
 			strings.Contains(strings.ToLower(t.Constellation), q) ||
 			strings.Contains(strings.ToLower(t.Code), q) ||
 			strings.Contains(strings.ToLower(t.Catalog), q) {
 			tv.filtered = append(tv.filtered, t)
 		}
 	}
+	tv.sortFiltered()
 	tv.list.Refresh()
 }
 
+// formatMagnitude scrive la magnitudine o "n/d" se il catalogo non la riporta.
+func formatMagnitude(mag *float64) string {
+	if mag == nil {
+		return "n/d"
+	}
+	return fmt.Sprintf("%.1f", *mag)
+}
+
 func (tv *TargetsView) formatDetails(t TargetObject) string {
 	sb := &strings.Builder{}
 	fmt.Fprintf(sb, "Nome: %s\n", t.Name)
 	fmt.Fprintf(sb, "Catalogo: %s %s\n", t.Catalog, t.Code)
 	fmt.Fprintf(sb, "Tipo: %s\n", t.Type)
 	fmt.Fprintf(sb, "Costellazione: %s\n", t.Constellation)
-	fmt.Fprintf(sb, "Magnitudine: %.1f\n", t.Magnitude)
+	fmt.Fprintf(sb, "Magnitudine: %s\n", formatMagnitude(t.Magnitude))
 	if t.SurfaceBright != nil {
 		fmt.Fprintf(sb, "Luminosità superficiale: %.2f mag/arcsec²\n", *t.SurfaceBright)
 	}
+	if t.SizeMajor != nil {
+		if t.SizeMinor != nil {
+			fmt.Fprintf(sb, "Dimensioni: %.1f′ × %.1f′\n", *t.SizeMajor, *t.SizeMinor)
+		} else {
+			fmt.Fprintf(sb, "Dimensioni: %.1f′\n", *t.SizeMajor)
+		}
+	}
 	fmt.Fprintf(sb, "\nCoordinate:\n  RA:  %s\n  Dec: %s\n", t.RA, t.Dec)
+
+	if n, ok := tv.tonight[targetKey(t)]; ok {
+		fmt.Fprintf(sb, "\nStanotte (buio astronomico):\n")
+		fmt.Fprintf(sb, "  Ore sopra l'altezza minima: %.1f h\n", n.HoursAbove)
+		fmt.Fprintf(sb, "  Altezza massima: %.1f° alle %s\n", n.MaxAltDeg, n.MaxAltTime.In(tv.obsTime.Location()).Format("15:04"))
+	}
+
+	v, ok := tv.visibility[targetKey(t)]
+	if !ok {
+		return sb.String()
+	}
+
+	fmt.Fprintf(sb, "\nPosizione (%.4f, %.4f — %s):\n", tv.siteLat, tv.siteLon, tv.obsTime.Format("2006-01-02 15:04"))
+	fmt.Fprintf(sb, "  Altezza: %.1f°\n", v.AltDeg)
+	fmt.Fprintf(sb, "  Azimut: %.1f° (%s)\n", v.AzDeg, compassPoint(v.AzDeg))
+	if h := services.HorizonAltitude(currentSite().Horizon, v.AzDeg); h > 0 {
+		if v.AltDeg < h && v.AltDeg > 0 {
+			fmt.Fprintf(sb, "  Nascosto dall'orizzonte locale (%.0f° a questo azimut)\n", h)
+		} else {
+			fmt.Fprintf(sb, "  Orizzonte locale a questo azimut: %.0f°\n", h)
+		}
+	}
+	fmt.Fprintf(sb, "  Angolo orario: %s\n", formatHourAngle(v.HourAngleDeg))
+	if v.Airmass > 0 {
+		fmt.Fprintf(sb, "  Airmass: %.2f\n", v.Airmass)
+	} else {
+		fmt.Fprintf(sb, "  Airmass: — (sotto l'orizzonte)\n")
+	}
+
+	switch {
+	case v.Circumpolar:
+		fmt.Fprintf(sb, "  Circumpolare: sempre sopra l'orizzonte\n")
+	case v.NeverRises:
+		fmt.Fprintf(sb, "  Non sorge mai da questo sito\n")
+	default:
+		fmt.Fprintf(sb, "  Sorge: %s\n", formatEventTime(*v.Rise, tv.obsTime))
+	}
+	fmt.Fprintf(sb, "  Transito: %s\n", formatEventTime(*v.Transit, tv.obsTime))
+	if v.Set != nil {
+		fmt.Fprintf(sb, "  Tramonta: %s\n", formatEventTime(*v.Set, tv.obsTime))
+	}
+
+	fmt.Fprintf(sb, "\nLuna (%s, %.0f%%):\n", tv.moon.PhaseName, tv.moon.Illumination*100)
+	fmt.Fprintf(sb, "  Separazione: %.1f°\n", v.Moon.SeparationDeg)
+	switch {
+	case !v.Moon.MoonUp:
+		fmt.Fprintf(sb, "  Luna sotto l'orizzonte: nessun disturbo\n")
+	case v.Airmass == 0:
+		fmt.Fprintf(sb, "  Luna alta %.0f° (target sotto l'orizzonte)\n", tv.moon.AltDeg)
+	default:
+		fmt.Fprintf(sb, "  Schiarimento cielo: +%.2f mag/arcsec² (%s)\n",
+			v.Moon.SkyBrighteningMag, services.MoonInterferenceLabel(v.Moon.SkyBrighteningMag))
+	}
 	return sb.String()
 }
 
+// formatEventTime mostra l'ora locale, con la data se diversa dal giorno di riferimento
+func formatEventTime(t, ref time.Time) string {
+	t = t.In(ref.Location())
+	if t.YearDay() == ref.YearDay() && t.Year() == ref.Year() {
+		return t.Format("15:04")
+	}
+	return t.Format("15:04 (02/01)")
+}
+
+// formatHourAngle formatta l'angolo orario in ore (negativo = a est, prima del transito)
+func formatHourAngle(haDeg float64) string {
+	sign := "+"
+	if haDeg < 0 {
+		sign = "-"
+		haDeg = -haDeg
+	}
+	totalMin := int(haDeg/15.0*60.0 + 0.5)
+	return fmt.Sprintf("%s%dh %02dm", sign, totalMin/60, totalMin%60)
+}
+
+// compassPoint converte un azimut nel punto cardinale più vicino
+func compassPoint(azDeg float64) string {
+	points := []string{"N", "NE", "E", "SE", "S", "SO", "O", "NO"}
+	idx := int((azDeg+22.5)/45.0) % 8
+	return points[idx]
+}
+
 func (tv *TargetsView) Widget() fyne.CanvasObject {
 	return tv.root
 }
@@ -2,9 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/cr4sh87/astro-lair-go/models"
 	"github.com/cr4sh87/astro-lair-go/services"
 
 	"fyne.io/fyne/v2"
@@ -12,63 +15,13 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-// =============================================================
-//  STORAGE POSIZIONE METEO (Preferences)
-// =============================================================
-
-const (
-	prefWeatherLat = "weather.lat"
-	prefWeatherLon = "weather.lon"
-)
-
-// loadStoredCoords carica lat/lon se presenti nelle Preferences
-func loadStoredCoords() (float64, float64, bool) {
-	app := fyne.CurrentApp()
-	if app == nil {
-		return 0, 0, false
-	}
-	p := app.Preferences()
-
-	latStr := p.String(prefWeatherLat)
-	lonStr := p.String(prefWeatherLon)
-	if latStr == "" || lonStr == "" {
-		return 0, 0, false
-	}
-
-	lat, err1 := strconv.ParseFloat(latStr, 64)
-	lon, err2 := strconv.ParseFloat(lonStr, 64)
-	if err1 != nil || err2 != nil {
-		return 0, 0, false
-	}
-
-	return lat, lon, true
-}
-
-// saveStoredCoords salva la posizione meteo scelta dall'utente
-func saveStoredCoords(lat, lon float64) {
-	app := fyne.CurrentApp()
-	if app == nil {
-		return
-	}
-	p := app.Preferences()
-	p.SetString(prefWeatherLat, fmt.Sprintf("%f", lat))
-	p.SetString(prefWeatherLon, fmt.Sprintf("%f", lon))
-}
-
 // =============================================================
 //  WEATHER VIEW
 // =============================================================
 
 func buildWeatherView() fyne.CanvasObject {
-	// valori di default / fallback
-	latDefault := 37.65
-	lonDefault := 15.17
-
-	// se esiste una posizione salvata → usala
-	if lat, lon, ok := loadStoredCoords(); ok {
-		latDefault = lat
-		lonDefault = lon
-	}
+	// coordinate del sito selezionato; si possono cambiare per una previsione al volo
+	latDefault, lonDefault := observerLocation()
 
 	latEntry := widget.NewEntry()
 	latEntry.SetText(fmt.Sprintf("%.4f", latDefault))
@@ -76,22 +29,25 @@ func buildWeatherView() fyne.CanvasObject {
 	lonEntry := widget.NewEntry()
 	lonEntry.SetText(fmt.Sprintf("%.4f", lonDefault))
 
+	// fonte e precisione dell'ultima posizione rilevata
+	locationLabel := widget.NewLabel("")
+	locationLabel.Wrapping = fyne.TextWrapWord
+
 	result := widget.NewLabel("Inserisci coordinate o usa la posizione del dispositivo, poi premi \"Aggiorna Meteo\".")
 	result.Wrapping = fyne.TextWrapWord
 
 	loading := widget.NewProgressBarInfinite()
 	loading.Hide()
 
+	forecast := newForecastTables()
+
 	// funzione condivisa che scarica e aggiorna il meteo
 	updateWeather := func(lat, lon float64) {
-		// salva per uso futuro
-		saveStoredCoords(lat, lon)
-
 		loading.Show()
 		result.SetText("Scarico previsioni…")
 
 		go func() {
-			meteo, err := services.FetchWeather(lat, lon)
+			fc, err := services.FetchForecast(lat, lon, weatherForecastDays)
 			if err != nil {
 				fyne.Do(func() {
 					loading.Hide()
@@ -100,39 +56,20 @@ func buildWeatherView() fyne.CanvasObject {
 				return
 			}
 
+			now := time.Now()
+			nights := services.NightForecasts(*fc, lat, lon, now)
+
 			fyne.Do(func() {
 				loading.Hide()
 
-				if len(meteo.Hourly.Time) == 0 ||
-					len(meteo.Hourly.CloudCover) == 0 ||
-					len(meteo.Hourly.Humidity) == 0 ||
-					len(meteo.Hourly.WindSpeed) == 0 {
-					result.SetText("Dati meteo non disponibili.")
-					return
-				}
-
-				cc := meteo.Hourly.CloudCover[0]
-				hum := meteo.Hourly.Humidity[0]
-				wnd := meteo.Hourly.WindSpeed[0]
-
-				text := fmt.Sprintf(
-					"🌤️ Copertura nuvolosa: %.0f%%\n💧 Umidità: %.0f%%\n🌬️ Vento: %.1f m/s",
-					cc, hum, wnd,
-				)
-
-				var quality string
-				switch {
-				case cc < 20 && wnd < 4:
-					quality = "🔵 Cielo ottimo per foto"
-				case cc < 40 && wnd < 6:
-					quality = "🟢 Cielo buono"
-				case cc < 70:
-					quality = "🟡 Cielo mediocre"
-				default:
-					quality = "🔴 Cielo poco adatto"
+				idx := services.CurrentHourIndex(*fc, now)
+				if idx < 0 {
+					result.SetText("La previsione non copre l'ora attuale.")
+				} else {
+					result.SetText(formatWeatherHour(fc.Hours[idx], fc.Timezone))
 				}
-
-				result.SetText(text + "\n\n" + quality)
+				forecast.set(*fc, nights, idx)
+				setForecastSeeing(nights)
 			})
 		}()
 	}
@@ -147,13 +84,21 @@ func buildWeatherView() fyne.CanvasObject {
 		updateWeather(lat, lon)
 	})
 
-	// 🔘 Pulsante che forza la richiesta di posizione (via IP)
+	// al cambio di sito la previsione segue il sito selezionato
+	onSiteChanged(func() {
+		lat, lon := observerLocation()
+		latEntry.SetText(fmt.Sprintf("%.4f", lat))
+		lonEntry.SetText(fmt.Sprintf("%.4f", lon))
+		updateWeather(lat, lon)
+	})
+
+	// 🔘 Pulsante che forza la richiesta di posizione (fonti in ordine configurabile)
 	useDeviceLocationBtn := widget.NewButton("Usa posizione dispositivo", func() {
 		loading.Show()
 		result.SetText("Rilevo la posizione del dispositivo…")
 
 		go func() {
-			loc, err := services.AutoLocateDevice()
+			loc, err := locateDevice()
 			if err != nil {
 				fyne.Do(func() {
 					loading.Hide()
@@ -169,21 +114,32 @@ func buildWeatherView() fyne.CanvasObject {
 
 				// e aggiorna direttamente il meteo
 				updateWeather(loc.Lat, loc.Lon)
+				locationLabel.SetText(formatDeviceLocation(loc))
 			})
 		}()
 	})
+	locationSettingsBtn := widget.NewButton("Fonti…", showLocationSettingsDialog)
+
+	// ricerca offline di una località: compila le coordinate e aggiorna
+	search := newPlaceSearch(func(p models.Place) {
+		latEntry.SetText(fmt.Sprintf("%.4f", p.Lat))
+		lonEntry.SetText(fmt.Sprintf("%.4f", p.Lon))
+		updateWeather(p.Lat, p.Lon)
+	})
 
 	form := widget.NewForm(
+		widget.NewFormItem("Località", search),
 		widget.NewFormItem("Latitudine", latEntry),
 		widget.NewFormItem("Longitudine", lonEntry),
 	)
 
 	header := container.NewVBox(
 		widget.NewLabelWithStyle("Meteo Astronomico", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
-		useDeviceLocationBtn,
+		container.NewBorder(nil, nil, nil, locationSettingsBtn, useDeviceLocationBtn),
+		locationLabel,
 	)
 
-	return container.NewVBox(
+	return container.NewVScroll(container.NewVBox(
 		header,
 		widget.NewSeparator(),
 		form,
@@ -191,7 +147,286 @@ func buildWeatherView() fyne.CanvasObject {
 		loading,
 		widget.NewSeparator(),
 		result,
+		widget.NewSeparator(),
+		forecast.root,
+	))
+}
+
+// weatherForecastDays sono i giorni di previsione scaricati
+const weatherForecastDays = 7
+
+// formatWeatherHour descrive l'ora di previsione corrente.
+func formatWeatherHour(h models.WeatherHour, timezone string) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "Ora attuale: %s (fuso del sito %s)\n", h.Time.Format("Mon 02/01 15:04"), timezone)
+	fmt.Fprintf(sb, "🌤️ Nuvole: %s (basse %s, medie %s, alte %s)\n",
+		fmtPct(h.CloudCoverPct), fmtPct(h.CloudLowPct), fmtPct(h.CloudMidPct), fmtPct(h.CloudHighPct))
+	fmt.Fprintf(sb, "🌡️ Temperatura: %s • punto di rugiada %s • umidità %s\n",
+		fmtTemp(h.TemperatureC), fmtTemp(h.DewPointC), fmtPct(h.HumidityPct))
+	fmt.Fprintf(sb, "🌬️ Vento: %s • raffiche %s\n", fmtWind(h.WindSpeedMS), fmtWind(h.WindGustsMS))
+	fmt.Fprintf(sb, "👁️ Visibilità: %s • 🌧️ probabilità di pioggia %s\n", fmtVisibility(h.VisibilityM), fmtPct(h.PrecipProbPct))
+	fmt.Fprintf(sb, "%s\n\n", formatSeeing(h))
+	sb.WriteString(services.SkyQuality(h.CloudCoverPct, h.WindSpeedMS))
+	return sb.String()
+}
+
+// =============================================================
+//  TABELLE PREVISIONI (notti + ore)
+// =============================================================
+
+// forecastTables mostra il riassunto per notte e le previsioni orarie;
+// selezionando una notte la tabella oraria si limita a quella notte.
+type forecastTables struct {
+	root fyne.CanvasObject
+
+	nightsTable *widget.Table
+	hoursTable  *widget.Table
+	hoursTitle  *widget.Label
+	gridHolder  *fyne.Container
+	dewLabel    *widget.Label
+
+	fc     models.WeatherForecast
+	nights []models.NightForecast
+	hours  []models.WeatherHour // ore mostrate
+	from   int                  // prima ora da mostrare senza selezione (l'ora attuale)
+
+	dewNight *models.NightForecast // notte degli avvisi rugiada (nil se nessuna)
+}
+
+var (
+	nightHeaders = []string{"Notte", "Buio", "Nuvole", "Ore serene", "Seeing", "Traspar.", "T min", "T−rugiada", "Rugiada", "Raffiche", "Pioggia", "Cielo"}
+	hourHeaders  = []string{"Ora", "Nuvole", "Basse", "Medie", "Alte", "T", "Rugiada", "Visib.", "Vento", "Raffiche", "Pioggia"}
+)
+
+func newForecastTables() *forecastTables {
+	ft := &forecastTables{}
+
+	ft.nightsTable = widget.NewTable(
+		func() (int, int) { return len(ft.nights) + 1, len(nightHeaders) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			lbl := obj.(*widget.Label)
+			if id.Row == 0 {
+				lbl.TextStyle = fyne.TextStyle{Bold: true}
+				lbl.SetText(nightHeaders[id.Col])
+				return
+			}
+			lbl.TextStyle = fyne.TextStyle{}
+			if id.Row-1 >= len(ft.nights) {
+				lbl.SetText("")
+				return
+			}
+			lbl.SetText(nightCell(ft.nights[id.Row-1], id.Col))
+		},
+	)
+	for col, w := range []float32{90, 110, 70, 90, 70, 75, 65, 90, 90, 80, 70, 190} {
+		ft.nightsTable.SetColumnWidth(col, w)
+	}
+
+	ft.hoursTable = widget.NewTable(
+		func() (int, int) { return len(ft.hours) + 1, len(hourHeaders) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			lbl := obj.(*widget.Label)
+			if id.Row == 0 {
+				lbl.TextStyle = fyne.TextStyle{Bold: true}
+				lbl.SetText(hourHeaders[id.Col])
+				return
+			}
+			lbl.TextStyle = fyne.TextStyle{}
+			if id.Row-1 >= len(ft.hours) {
+				lbl.SetText("")
+				return
+			}
+			lbl.SetText(hourCell(ft.hours[id.Row-1], id.Col))
+		},
+	)
+	for col, w := range []float32{110, 70, 60, 60, 60, 70, 75, 70, 70, 80, 70} {
+		ft.hoursTable.SetColumnWidth(col, w)
+	}
+
+	ft.hoursTitle = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	ft.gridHolder = container.NewStack()
+	ft.dewLabel = widget.NewLabel("")
+	ft.dewLabel.Wrapping = fyne.TextWrapWord
+	// le fasce anticondensa cambiano dal dialogo strumentazione
+	onEquipmentChanged(ft.refreshDew)
+
+	ft.nightsTable.OnSelected = func(id widget.TableCellID) {
+		if id.Row == 0 || id.Row-1 >= len(ft.nights) {
+			ft.nightsTable.UnselectAll()
+			return
+		}
+		ft.showNight(ft.nights[id.Row-1])
+	}
+
+	allBtn := widget.NewButton("Tutte le ore", func() {
+		ft.nightsTable.UnselectAll()
+		ft.showAll()
+	})
+
+	nightsScroll := container.NewScroll(ft.nightsTable)
+	nightsScroll.SetMinSize(fyne.NewSize(0, 230))
+	hoursScroll := container.NewScroll(ft.hoursTable)
+	hoursScroll.SetMinSize(fyne.NewSize(0, 320))
+
+	ft.root = container.NewVBox(
+		widget.NewLabelWithStyle("Notti (buio astronomico; * = tramonto-alba dove non fa buio)", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		nightsScroll,
+		container.NewBorder(nil, nil, nil, allBtn, ft.hoursTitle),
+		ft.gridHolder,
+		ft.dewLabel,
+		hoursScroll,
+		widget.NewLabel("Seeing e trasparenza sono stime empiriche da vento in quota (200/250 hPa),\ngradiente termico 850-500 hPa e umidità in quota: utili per confrontare ore e notti."),
 	)
+	return ft
+}
+
+// set carica una nuova previsione; current è l'indice dell'ora attuale (-1 se assente).
+func (ft *forecastTables) set(fc models.WeatherForecast, nights []models.NightForecast, current int) {
+	ft.fc, ft.nights = fc, nights
+	ft.from = max(current, 0)
+	ft.nightsTable.UnselectAll()
+	ft.nightsTable.Refresh()
+	ft.showAll()
+}
+
+func (ft *forecastTables) showAll() {
+	ft.hours = ft.fc.Hours[ft.from:]
+	ft.hoursTitle.SetText(fmt.Sprintf("Previsione oraria (%d ore)", len(ft.hours)))
+	// senza selezione gli avvisi rugiada riguardano la prima notte in elenco
+	ft.dewNight = nil
+	if len(ft.nights) > 0 {
+		ft.dewNight = &ft.nights[0]
+	}
+	ft.refreshDew()
+	ft.refreshHours()
+}
+
+func (ft *forecastTables) showNight(n models.NightForecast) {
+	ft.hours = ft.hours[:0:0]
+	for _, h := range ft.fc.Hours {
+		if h.Time.Add(time.Hour).After(n.Start) && h.Time.Before(n.End) {
+			ft.hours = append(ft.hours, h)
+		}
+	}
+	ft.hoursTitle.SetText("Previsione oraria — notte del " + n.Date.Format("Mon 02/01"))
+	ft.dewNight = &n
+	ft.refreshDew()
+	ft.refreshHours()
+}
+
+// refreshDew riscrive gli avvisi rugiada con le fasce configurate.
+func (ft *forecastTables) refreshDew() {
+	if ft.dewNight == nil {
+		ft.dewLabel.SetText("")
+		return
+	}
+	ft.dewLabel.SetText(formatDewWarnings(*ft.dewNight, getEquipmentConfig().PrimaryHeaters))
+}
+
+// refreshHours ridisegna tabella oraria e griglia per le ore selezionate.
+func (ft *forecastTables) refreshHours() {
+	ft.gridHolder.Objects = []fyne.CanvasObject{buildConditionsGrid(ft.hours)}
+	ft.gridHolder.Refresh()
+	ft.hoursTable.Refresh()
+	ft.hoursTable.ScrollToTop()
+}
+
+func nightCell(n models.NightForecast, col int) string {
+	loc := n.Date.Location()
+	switch col {
+	case 0:
+		return n.Date.Format("Mon 02/01")
+	case 1:
+		span := n.Start.In(loc).Format("15:04") + "–" + n.End.In(loc).Format("15:04")
+		if !n.Astronomical {
+			span += "*"
+		}
+		return span
+	case 2:
+		return fmtPct(n.AvgCloudPct)
+	case 3:
+		return fmt.Sprintf("%d/%d", n.ClearHours, n.Hours)
+	case 4:
+		if math.IsNaN(n.AvgSeeingArcsec) {
+			return "—"
+		}
+		return fmt.Sprintf("%.1f″", n.AvgSeeingArcsec)
+	case 5:
+		return fmtPct(n.AvgTransparencyPct)
+	case 6:
+		return fmtTemp(n.MinTemperatureC)
+	case 7:
+		return fmtTemp(n.MinDewSpreadC)
+	case 8:
+		return services.DewRiskLabel(n.MaxDewLevel, n.Frost)
+	case 9:
+		return fmtWind(n.MaxGustMS)
+	case 10:
+		return fmtPct(n.MaxPrecipProbPct)
+	case 11:
+		return n.Quality
+	}
+	return ""
+}
+
+func hourCell(h models.WeatherHour, col int) string {
+	switch col {
+	case 0:
+		return h.Time.Format("Mon 02/01 15h")
+	case 1:
+		return fmtPct(h.CloudCoverPct)
+	case 2:
+		return fmtPct(h.CloudLowPct)
+	case 3:
+		return fmtPct(h.CloudMidPct)
+	case 4:
+		return fmtPct(h.CloudHighPct)
+	case 5:
+		return fmtTemp(h.TemperatureC)
+	case 6:
+		return fmtTemp(h.DewPointC)
+	case 7:
+		return fmtVisibility(h.VisibilityM)
+	case 8:
+		return fmtWind(h.WindSpeedMS)
+	case 9:
+		return fmtWind(h.WindGustsMS)
+	case 10:
+		return fmtPct(h.PrecipProbPct)
+	}
+	return ""
+}
+
+// Formattazione dei valori meteo ("—" se mancanti)
+
+func fmtPct(v float64) string {
+	if math.IsNaN(v) {
+		return "—"
+	}
+	return fmt.Sprintf("%.0f%%", v)
+}
+
+func fmtTemp(v float64) string {
+	if math.IsNaN(v) {
+		return "—"
+	}
+	return fmt.Sprintf("%.1f°C", v)
+}
+
+func fmtWind(v float64) string {
+	if math.IsNaN(v) {
+		return "—"
+	}
+	return fmt.Sprintf("%.1f m/s", v)
+}
+
+func fmtVisibility(m float64) string {
+	if math.IsNaN(m) {
+		return "—"
+	}
+	return fmt.Sprintf("%.0f km", m/1000)
 }
 
 // BuildWeatherView ritorna la vista meteo
@@ -0,0 +1,284 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cr4sh87/astro-lair-go/models"
+	"github.com/cr4sh87/astro-lair-go/services"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// =======================
+//  GOES: flusso X e brillamenti
+// =======================
+
+// Dimensioni del grafico del flusso X (pixel) e scala verticale (W/m²)
+const (
+	xrayChartW   = 720
+	xrayChartH   = 240
+	xrayAxisH    = 16
+	xrayFluxMin  = 1e-9
+	xrayFluxMax  = 1e-3
+	xrayMaxListN = 30 // brillamenti elencati al massimo
+)
+
+// Colori delle due bande (come nei grafici SWPC: rosso la lunga, blu la corta)
+var (
+	xrayLongColor  = color.NRGBA{R: 235, G: 60, B: 50, A: 255}
+	xrayShortColor = color.NRGBA{R: 80, G: 130, B: 240, A: 255}
+)
+
+// buildXRayCard restituisce il riquadro col flusso X GOES (1 o 7 giorni) e
+// l'elenco dei brillamenti della settimana.
+func buildXRayCard() fyne.CanvasObject {
+	nowLabel := widget.NewLabel("Scarico il flusso X GOES…")
+	nowLabel.Wrapping = fyne.TextWrapWord
+	chart := container.NewStack()
+	loading := widget.NewProgressBarInfinite()
+	flaresBox := container.NewVBox()
+	suviStatus := widget.NewLabel("")
+
+	days := 1
+	rangeSelect := widget.NewSelect([]string{"24 ore", "7 giorni"}, nil)
+
+	// cambiando intervallo mentre un download è in corso vale solo l'ultima richiesta
+	fetchGen := 0
+	refresh := func() {
+		fetchGen++
+		gen, d := fetchGen, days
+		loading.Show()
+		go func() {
+			flux, fluxErr := services.FetchXRayFlux(d)
+			flares, flaresErr := services.FetchSolarFlares()
+			fyne.Do(func() {
+				if gen != fetchGen {
+					return
+				}
+				loading.Hide()
+				loc := siteLocation()
+				if fluxErr != nil {
+					nowLabel.SetText("Flusso X non disponibile: " + fluxErr.Error())
+					chart.Objects = nil
+				} else {
+					nowLabel.SetText(formatXRayNow(flux, loc))
+					chart.Objects = []fyne.CanvasObject{container.NewHScroll(buildXRayChart(flux, flares))}
+				}
+				chart.Refresh()
+
+				flaresBox.Objects = nil
+				if flaresErr != nil {
+					flaresBox.Add(widget.NewLabel("Elenco brillamenti non disponibile: " + flaresErr.Error()))
+				} else {
+					for _, l := range formatFlares(flares, loc) {
+						flaresBox.Add(l)
+					}
+				}
+				flaresBox.Refresh()
+			})
+		}()
+	}
+
+	rangeSelect.OnChanged = func(s string) {
+		days = 1
+		if s == "7 giorni" {
+			days = 7
+		}
+		refresh()
+	}
+
+	// i brillamenti si seguono meglio nell'animazione SUVI 304 Å
+	suviViewer := &frameViewer{title: "SUVI 304 Å (frames)"}
+	suviBtn := widget.NewButton("▶ Animazione SUVI 304 Å", func() {
+		services.LoadRemoteAnimation(suvi304DirURL, "SUVI 304 Å (frames)", suviStatus, suviViewer.show)
+	})
+
+	legend := widget.NewLabel("Rosso: 0.1–0.8 nm (definisce la classe) • blu: 0.05–0.4 nm • fasce A/B/C/M/X • triangoli: picchi dei brillamenti. Orari UTC.")
+	legend.Wrapping = fyne.TextWrapWord
+
+	rangeSelect.SetSelected("24 ore") // avvia il primo download
+
+	return widget.NewCard("☀️ Flusso X GOES e brillamenti", "", container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(rangeSelect, widget.NewButton("Aggiorna", refresh)), nowLabel),
+		loading,
+		chart,
+		legend,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Brillamenti (ultimi 7 giorni)", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		flaresBox,
+		container.NewHBox(suviBtn, suviStatus),
+	))
+}
+
+// formatXRayNow descrive l'ultimo valore del flusso e la sua classe.
+func formatXRayNow(flux []models.XRayFlux, loc *time.Location) string {
+	for i := len(flux) - 1; i >= 0; i-- {
+		p := flux[i]
+		if math.IsNaN(p.Long) {
+			continue
+		}
+		return fmt.Sprintf("Flusso attuale (0.1–0.8 nm): %.1e W/m² — classe %s alle %s (%s UTC)",
+			p.Long, services.XRayClass(p.Long), p.Time.In(loc).Format("15:04"), p.Time.UTC().Format("15:04"))
+	}
+	return "Nessuna misura recente del flusso X."
+}
+
+// formatFlares elenca i brillamenti dal più recente; M e X in evidenza.
+func formatFlares(flares []models.SolarFlare, loc *time.Location) []fyne.CanvasObject {
+	if len(flares) == 0 {
+		return []fyne.CanvasObject{widget.NewLabel("Nessun brillamento negli ultimi 7 giorni.")}
+	}
+	var out []fyne.CanvasObject
+	for i, f := range flares {
+		if i == xrayMaxListN {
+			out = append(out, widget.NewLabel(fmt.Sprintf("… e altri %d", len(flares)-i)))
+			break
+		}
+		marker := "⚪"
+		switch {
+		case strings.HasPrefix(f.Class, "X"):
+			marker = "🔴"
+		case strings.HasPrefix(f.Class, "M"):
+			marker = "🟠"
+		case strings.HasPrefix(f.Class, "C"):
+			marker = "🟡"
+		}
+		end := "in corso"
+		if f.End != nil {
+			end = "fine " + f.End.In(loc).Format("15:04")
+		}
+		text := fmt.Sprintf("%s %s — picco %s (%s UTC) • inizio %s • %s",
+			marker, f.Class, f.Peak.In(loc).Format("Mon 02/01 15:04"), f.Peak.UTC().Format("15:04"),
+			f.Begin.In(loc).Format("15:04"), end)
+		l := widget.NewLabel(text)
+		if strings.HasPrefix(f.Class, "M") || strings.HasPrefix(f.Class, "X") {
+			l.TextStyle = fyne.TextStyle{Bold: true}
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+// buildXRayChart disegna le due bande in scala logaritmica, con le fasce
+// delle classi e i picchi dei brillamenti nel periodo mostrato.
+func buildXRayChart(flux []models.XRayFlux, flares []models.SolarFlare) fyne.CanvasObject {
+	img := image.NewNRGBA(image.Rect(0, 0, xrayChartW, xrayChartH))
+	overlay := []fyne.CanvasObject{}
+
+	logMin, logMax := math.Log10(xrayFluxMin), math.Log10(xrayFluxMax)
+	yOf := func(f float64) float64 {
+		return float64(xrayChartH-1) * (1 - (math.Log10(f)-logMin)/(logMax-logMin))
+	}
+
+	// fasce delle classi, alternate, con la lettera a destra
+	bandColors := []color.NRGBA{{R: 22, G: 26, B: 34, A: 255}, {R: 32, G: 38, B: 48, A: 255}}
+	for y := 0; y < xrayChartH; y++ {
+		f := math.Pow(10, logMax-(logMax-logMin)*float64(y)/float64(xrayChartH-1))
+		band := int(math.Floor(math.Log10(f)))
+		c := bandColors[(band%2+2)%2]
+		for x := 0; x < xrayChartW; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	for _, letter := range []string{"A", "B", "C", "M", "X"} {
+		lo := services.ParseXRayClass(letter)
+		t := canvas.NewText(letter, color.NRGBA{R: 170, G: 170, B: 170, A: 255})
+		t.TextSize = 11
+		t.TextStyle = fyne.TextStyle{Bold: true}
+		t.Move(fyne.NewPos(xrayChartW-14, float32(yOf(lo*10)+(yOf(lo)-yOf(lo*10))/2-8)))
+		overlay = append(overlay, t)
+	}
+
+	if len(flux) > 0 {
+		start, end := flux[0].Time, flux[len(flux)-1].Time
+		span := end.Sub(start)
+		if span <= 0 {
+			span = time.Minute
+		}
+		xOf := func(t time.Time) int {
+			return int(float64(xrayChartW-20) * float64(t.Sub(start)) / float64(span))
+		}
+
+		// griglia temporale: ogni 3 ore (24 h) o ogni giorno (7 giorni), in UTC
+		step := 3 * time.Hour
+		format := "15:04"
+		if span > 36*time.Hour {
+			step = 24 * time.Hour
+			format = "02/01"
+		}
+		for t := start.Truncate(step).Add(step); t.Before(end); t = t.Add(step) {
+			x := xOf(t)
+			for y := 0; y < xrayChartH; y += 2 {
+				img.SetNRGBA(x, y, color.NRGBA{R: 70, G: 75, B: 85, A: 255})
+			}
+			lbl := canvas.NewText(t.Format(format), color.NRGBA{R: 190, G: 190, B: 190, A: 255})
+			lbl.TextSize = 10
+			lbl.Move(fyne.NewPos(float32(x-14), xrayChartH+2))
+			overlay = append(overlay, lbl)
+		}
+
+		// una colonna per pixel col massimo del periodo (i picchi restano visibili)
+		plot := func(value func(models.XRayFlux) float64, c color.NRGBA) {
+			cols := make([]float64, xrayChartW)
+			for i := range cols {
+				cols[i] = math.NaN()
+			}
+			for _, p := range flux {
+				v := value(p)
+				if math.IsNaN(v) {
+					continue
+				}
+				x := xOf(p.Time)
+				if x >= 0 && x < xrayChartW && (math.IsNaN(cols[x]) || v > cols[x]) {
+					cols[x] = v
+				}
+			}
+			prev := math.NaN()
+			for x, v := range cols {
+				if math.IsNaN(v) {
+					continue
+				}
+				y := yOf(math.Max(xrayFluxMin, math.Min(xrayFluxMax, v)))
+				y0, y1 := y, y
+				if !math.IsNaN(prev) {
+					y0, y1 = math.Min(prev, y), math.Max(prev, y)
+				}
+				for yy := int(y0); yy <= int(y1)+1 && yy < xrayChartH; yy++ {
+					img.SetNRGBA(x, yy, c)
+				}
+				prev = y
+			}
+		}
+		plot(func(p models.XRayFlux) float64 { return p.Short }, xrayShortColor)
+		plot(func(p models.XRayFlux) float64 { return p.Long }, xrayLongColor)
+
+		// picchi dei brillamenti C e superiori nel periodo
+		for _, f := range flares {
+			if f.Peak.Before(start) || f.Peak.After(end) || math.IsNaN(f.PeakFlux) || f.PeakFlux < 1e-6 {
+				continue
+			}
+			t := canvas.NewText("▼"+f.Class, color.White)
+			t.TextSize = 10
+			t.Move(fyne.NewPos(float32(xOf(f.Peak)-5), float32(yOf(f.PeakFlux)-16)))
+			overlay = append(overlay, t)
+		}
+	}
+
+	chartImg := canvas.NewImageFromImage(img)
+	chartImg.FillMode = canvas.ImageFillOriginal
+	chartImg.ScaleMode = canvas.ImageScalePixels
+	chartImg.Resize(fyne.NewSize(xrayChartW, xrayChartH))
+
+	bg := canvas.NewRectangle(color.Transparent)
+	bg.SetMinSize(fyne.NewSize(xrayChartW, xrayChartH+xrayAxisH))
+	objs := append([]fyne.CanvasObject{bg, chartImg}, overlay...)
+	return container.NewWithoutLayout(objs...)
+}